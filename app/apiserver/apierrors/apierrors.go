@@ -5,7 +5,27 @@ import "errors"
 var (
 	ErrEmptyParam               = errors.New("Invalid param")
 	ErrNotValidBody             = errors.New("Invalid json")
+	ErrValidation               = errors.New("Validation failed")
 	ErrIncorrectEmailOrPassword = errors.New("Incorrect email or password")
+	ErrIncorrectPassword        = errors.New("Incorrect password")
+	ErrSamePassword             = errors.New("New password is the same as current")
 	ErrNotAuthenticated         = errors.New("Not authenticated")
+	ErrInvalidAuthHeader        = errors.New("Invalid authorization header")
+	ErrInvalidToken             = errors.New("Invalid token")
+	ErrTokenExpired             = errors.New("Token expired")
+	ErrSessionRevoked           = errors.New("Session revoked")
+	ErrAccountDisabled          = errors.New("Account disabled")
 	ErrPermissionDenied         = errors.New("Permission denied")
+	ErrNotAllowedImpersonating  = errors.New("Not allowed while impersonating")
+	ErrInvalidCode              = errors.New("Invalid or expired code")
+	ErrContactVerified          = errors.New("Contact already verified")
+	ErrContactNotVerified       = errors.New("Contact not verified")
+	ErrFileTooLarge             = errors.New("File too large")
+	ErrUnsupportedFileType      = errors.New("Unsupported file type")
+	ErrTicketAssigned           = errors.New("Ticket already assigned")
+	ErrTicketClosed             = errors.New("Ticket closed")
+	ErrNotTicketHelper          = errors.New("Ticket is not assigned to you")
+	ErrTransitionNotAllowed     = errors.New("Status transition not allowed")
+	ErrTooManyAttachments       = errors.New("Too many attachments")
+	ErrAttachmentNotFound       = errors.New("Attachment not found or already attached")
 )
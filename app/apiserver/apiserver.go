@@ -8,10 +8,18 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/jobs"
+	"github.com/inhumanLightBackend/app/realtime"
 	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
 	"github.com/inhumanLightBackend/app/utils/notifications/telegram"
+	"github.com/sirupsen/logrus"
 )
 
+// Tickets created earlier are not checked for SLA breaches
+const slaLookback = 30 * 24 * time.Hour
+
 // Start and configure server
 func Start(config *Config) error {
 	db, err := newDb(config.DatabaseURL)
@@ -19,9 +27,43 @@ func Start(config *Config) error {
 		return err
 	}
 
+	if err := jwtHelper.Setup(&jwtHelper.Config{
+		Algorithm:      config.JwtAlgorithm,
+		PrivateKeyPath: config.JwtPrivateKeyPath,
+		KeyId:          config.JwtKeyId,
+		Issuer:         config.JwtIssuer,
+		Audience:       config.JwtAudience,
+	}); err != nil {
+		return err
+	}
+	if err := middleware.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return err
+	}
+
 	store := sqlstore.New(db)
+	bus, err := newBus(db, config)
+	if err != nil {
+		return err
+	}
+
+	logger := logrus.New()
+	scheduler := jobs.NewScheduler(logger, store)
+	scheduler.Every("purge_accounts", time.Hour, jobs.PurgeAccounts(store))
+	scheduler.Every("check_sla", 5*time.Minute, jobs.CheckSla(store,
+		telegram.New(config.TelegramUserId, config.TelegramToken), slaLookback))
+	if config.StaleReminderHours > 0 {
+		scheduler.Every("close_stale_tickets", 15*time.Minute, jobs.CloseStaleTickets(store,
+			newDispatcher(store, config, logger), bus,
+			time.Duration(config.StaleReminderHours)*time.Hour,
+			time.Duration(config.StaleCloseHours)*time.Hour))
+	}
+	scheduler.Start(context.Background())
+
+	s, err := NewServer(store, config, bus)
+	if err != nil {
+		return err
+	}
 	notifs := telegram.New(config.TelegramUserId, config.TelegramToken).Notify()
-	s := NewServer(store, config)
 	notifs <- "Server started"
 
 	exit := make(chan os.Signal, 1)
@@ -67,4 +109,23 @@ func newDb(databaseURL string) (*sql.DB, error) {
 	}
 
 	return db, nil
-}
\ No newline at end of file
+}
+
+// Init bus of ticket events. Postgres bus listens in background
+func newBus(db *sql.DB, config *Config) (realtime.Bus, error) {
+	switch config.RealtimeMode {
+	case "", "local":
+		return realtime.NewHub(), nil
+	case "postgres":
+		bus := realtime.NewPgBus(db)
+		logger := logrus.New()
+		go func() {
+			if err := bus.Listen(context.Background(), config.DatabaseURL, logger); err != nil {
+				logger.WithError(err).Error("Ticket events listener stopped")
+			}
+		}()
+		return bus, nil
+	}
+
+	return nil, fmt.Errorf("Unknown realtime mode %q", config.RealtimeMode)
+}
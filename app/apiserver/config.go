@@ -6,11 +6,42 @@ type Config struct {
 	DatabaseURL    string `toml:"database_url"`
 	TelegramToken  string `toml:"telegram_token"`
 	TelegramUserId int    `toml:"telegram_user_id"`
+	// Token signing: HS512 (default), RS256 or EdDSA
+	JwtAlgorithm      string `toml:"jwt_algorithm"`
+	JwtPrivateKeyPath string `toml:"jwt_private_key_path"`
+	JwtKeyId          string `toml:"jwt_key_id"`
+	JwtIssuer         string `toml:"jwt_issuer"`
+	JwtAudience       string `toml:"jwt_audience"`
+	// Smtp server for email notifications. Email channel is off if host is empty
+	SmtpHost     string `toml:"smtp_host"`
+	SmtpPort     int    `toml:"smtp_port"`
+	SmtpUsername string `toml:"smtp_username"`
+	SmtpPassword string `toml:"smtp_password"`
+	SmtpFrom     string `toml:"smtp_from"`
+	// Directory of uploaded files and upload size limit in bytes
+	ResourcesDir  string `toml:"resources_dir"`
+	MaxUploadSize int64  `toml:"max_upload_size"`
+	// Delivery of ticket events: "local" for single instance or "postgres"
+	// to sync several instances with LISTEN/NOTIFY
+	RealtimeMode string `toml:"realtime_mode"`
+	// Internal services allowed to use token introspection. Client id to secret
+	IntrospectionClients map[string]string `toml:"introspection_clients"`
+	// Proxies allowed to pass client address in X-Forwarded-For and
+	// X-Real-IP, addresses or CIDR networks. Headers are ignored if empty
+	TrustedProxies []string `toml:"trusted_proxies"`
+	// Hours without customer reply before reminder and hours after reminder
+	// before ticket is closed. Stale tickets are not closed if reminder is 0
+	StaleReminderHours int `toml:"stale_reminder_hours"`
+	StaleCloseHours    int `toml:"stale_close_hours"`
 }
 
 // Init new config
 func NewConfig() *Config {
 	return &Config{
-		Port: ":8080",
+		Port:               ":8080",
+		ResourcesDir:       "resources",
+		RealtimeMode:       "local",
+		StaleReminderHours: 72,
+		StaleCloseHours:    48,
 	}
 }
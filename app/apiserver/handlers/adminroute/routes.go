@@ -0,0 +1,391 @@
+package adminroute
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/handlers/userroute"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	defaultImpersonationTTL = 15
+	maxImpersonationTTL     = 60
+)
+
+type AdminRoutes struct {
+	store store.Store
+}
+
+func New(store store.Store) *AdminRoutes {
+	return &AdminRoutes{
+		store: store,
+	}
+}
+
+func (ar *AdminRoutes) SetUpRoutes(r *mux.Router) {
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AdminOnly)
+
+	admin.HandleFunc("/users", ar.users()).Methods("GET")
+	admin.HandleFunc("/users/{id:[0-9]+}", ar.patchUser()).Methods("PATCH")
+	admin.HandleFunc("/audit", ar.audit()).Methods("GET")
+	admin.HandleFunc("/audit/export", ar.auditExport()).Methods("GET")
+	admin.Handle("/balance/adjust", middleware.NotImpersonated(ar.adjustBalance())).Methods("POST")
+	admin.Handle("/impersonate", middleware.NotImpersonated(ar.impersonate())).Methods("POST")
+	admin.HandleFunc("/sections", ar.sections()).Methods("GET")
+	admin.HandleFunc("/sections", ar.createSection()).Methods("POST")
+	admin.HandleFunc("/sections/{id:[0-9]+}", ar.updateSection()).Methods("PUT")
+	admin.HandleFunc("/sections/{id:[0-9]+}", ar.deleteSection()).Methods("DELETE")
+	admin.HandleFunc("/sla/policies", ar.slaPolicies()).Methods("GET")
+	admin.HandleFunc("/sla/policies", ar.createSlaPolicy()).Methods("POST")
+	admin.HandleFunc("/sla/policies/{id:[0-9]+}", ar.updateSlaPolicy()).Methods("PUT")
+	admin.HandleFunc("/sla/policies/{id:[0-9]+}", ar.deleteSlaPolicy()).Methods("DELETE")
+	admin.HandleFunc("/sla/breaches", ar.slaBreaches()).Methods("GET")
+	admin.HandleFunc("/sla/report", ar.slaReport()).Methods("GET")
+}
+
+// User directory with filters and cursor pagination
+func (ar *AdminRoutes) users() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := userFilter(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		users, err := ar.store.User(r.Context()).List(filter)
+		if err != nil {
+			if err == store.ErrInvalidCursor {
+				responses.SendError(w, r, http.StatusBadRequest, err)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		total, err := ar.store.User(r.Context()).Count(filter)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		entries := make([]*userroute.UserView, len(users))
+		for i, u := range users {
+			entries[i] = userroute.NewUserView(u)
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"users":       entries,
+			"total":       total,
+			"next_cursor": filter.NextCursor(users),
+		})
+	}
+}
+
+// Patch other user. Admin may change role and account status
+func (ar *AdminRoutes) patchUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		user, err := ar.store.User(r.Context()).FindById(id)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		userroute.PatchUser(ar.store, w, r, user)
+	}
+}
+
+func (ar *AdminRoutes) audit() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := auditFilter(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		entries, err := ar.store.Audit(r.Context()).Find(filter)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		total, err := ar.store.Audit(r.Context()).Count(filter)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"entries": entries,
+			"total":   total,
+			"limit":   filter.Limit,
+			"offset":  filter.Offset,
+		})
+	}
+}
+
+// Export audit log in JSON Lines format. Filters are the same as in audit
+// but pagination is ignored, all matched entries are streamed. Pages are
+// taken by last exported id, so entries added during export do not shift them
+func (ar *AdminRoutes) auditExport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := auditFilter(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+		filter.Limit = maxPageSize
+		filter.Offset = 0
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", "attachment; filename=audit.jsonl")
+		w.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(w)
+		for {
+			entries, err := ar.store.Audit(r.Context()).Find(filter)
+			if err != nil {
+				return
+			}
+
+			for _, entry := range entries {
+				if err := encoder.Encode(entry); err != nil {
+					return
+				}
+			}
+
+			if len(entries) < filter.Limit {
+				return
+			}
+			filter.BeforeId = entries[len(entries)-1].ID
+		}
+	}
+}
+
+func (ar *AdminRoutes) adjustBalance() http.HandlerFunc {
+	type request struct {
+		UserId  uint    `json:"user_id"`
+		Value   float32 `json:"value"`
+		Comment string  `json:"comment"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		if req.UserId == 0 || req.Value == 0 {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		// Adjustment is not saved without audit entry
+		var balance *models.Balance
+		if err := ar.store.WithTx(r.Context(), func(tx store.Store) error {
+			var err error
+			if req.Value > 0 {
+				balance, err = tx.Balance(r.Context()).Add(req.UserId, req.Value)
+			} else {
+				balance, err = tx.Balance(r.Context()).Remove(req.UserId, -req.Value)
+			}
+			if err != nil {
+				return err
+			}
+
+			return middleware.Audit(tx, r, &models.AuditLog{
+				Action: auditAction.BalanceAdjust,
+				Target: models.AuditTarget("user", req.UserId),
+				Details: map[string]interface{}{
+					"value":       req.Value,
+					"balance_now": balance.BalanceNow,
+					"comment":     req.Comment,
+				},
+			})
+		}); err != nil {
+			if err == store.ErrInsufficientFunds {
+				responses.SendError(w, r, http.StatusBadRequest, err)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, balance)
+	}
+}
+
+// Mint short-lived access token which acts as the target user
+func (ar *AdminRoutes) impersonate() http.HandlerFunc {
+	type request struct {
+		UserId  int `json:"user_id"`
+		Minutes int `json:"minutes"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		if req.UserId == 0 || req.Minutes < 0 || req.Minutes > maxImpersonationTTL {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+		if req.Minutes == 0 {
+			req.Minutes = defaultImpersonationTTL
+		}
+
+		user, err := ar.store.User(r.Context()).FindById(req.UserId)
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if user.Role == roles.ADMIN || !user.IsActive {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrPermissionDenied)
+			return
+		}
+
+		// Token is not issued without audit entry
+		if err := middleware.Audit(ar.store, r, &models.AuditLog{
+			Action: auditAction.Impersonate,
+			Target: models.AuditTarget("user", user.ID),
+			Details: map[string]interface{}{
+				"minutes": req.Minutes,
+			},
+		}); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		ttl := time.Duration(req.Minutes) * time.Minute
+		token, err := jwtHelper.CreateImpersonated(user, middleware.UserId(r), ttl)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"access_token": token,
+			"expires_in":   int(ttl.Seconds()),
+		})
+	}
+}
+
+// Parse user filter from query params
+func userFilter(r *http.Request) (*store.UserFilter, error) {
+	query := r.URL.Query()
+	filter := &store.UserFilter{
+		Role:   query.Get("role"),
+		Search: query.Get("q"),
+		Cursor: query.Get("cursor"),
+		Limit:  defaultPageSize,
+	}
+
+	var err error
+	switch sortBy := query.Get("sort"); sortBy {
+	case "", store.UserSortCreatedAt, store.UserSortEmail, store.UserSortLogin, store.UserSortId:
+		filter.SortBy = sortBy
+	default:
+		return nil, apierrors.ErrEmptyParam
+	}
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return nil, apierrors.ErrEmptyParam
+	}
+	if active := query.Get("active"); active != "" {
+		value, err := strconv.ParseBool(active)
+		if err != nil {
+			return nil, err
+		}
+		filter.Active = &value
+	}
+	if from := query.Get("created_from"); from != "" {
+		if filter.CreatedFrom, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, err
+		}
+	}
+	if to := query.Get("created_to"); to != "" {
+		if filter.CreatedTo, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, err
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			return nil, apierrors.ErrEmptyParam
+		}
+		if filter.Limit > maxPageSize {
+			filter.Limit = maxPageSize
+		}
+	}
+
+	return filter, nil
+}
+
+// Parse audit filter from query params
+func auditFilter(r *http.Request) (*store.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := &store.AuditFilter{
+		Action: query.Get("action"),
+		Target: query.Get("target"),
+		Limit:  defaultPageSize,
+	}
+
+	var err error
+	if actor := query.Get("actor"); actor != "" {
+		if filter.Actor, err = strconv.Atoi(actor); err != nil {
+			return nil, err
+		}
+	}
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, err
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, err
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			return nil, apierrors.ErrEmptyParam
+		}
+		if filter.Limit > maxPageSize {
+			filter.Limit = maxPageSize
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil || filter.Offset < 0 {
+			return nil, apierrors.ErrEmptyParam
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,142 @@
+package adminroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// All ticket sections with helper pools
+func (ar *AdminRoutes) sections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sections, err := ar.store.Sections(r.Context()).List()
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, sections)
+	}
+}
+
+// Create section. Priority is normal and routing is round robin by default
+func (ar *AdminRoutes) createSection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		section := &models.Section{
+			Priority: ticketPriority.Normal,
+			Routing:  sectionRouting.RoundRobin,
+		}
+		if err := json.NewDecoder(r.Body).Decode(section); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+		section.ID = 0
+		section.LastHelper = 0
+
+		if !ar.validPool(w, r, section) {
+			return
+		}
+		if err := ar.store.Sections(r.Context()).Create(section); err != nil {
+			sendChangeError(w, r, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusCreated, section)
+	}
+}
+
+// Change description, priority, routing or helper pool of section.
+// Fields missing in body are not changed. Name can not be changed,
+// tickets refer to section by it
+func (ar *AdminRoutes) updateSection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		current, err := ar.store.Sections(r.Context()).Find(id)
+		if err != nil {
+			sendChangeError(w, r, err)
+			return
+		}
+
+		section := *current
+		section.Helpers = append([]int{}, current.Helpers...)
+		if err := json.NewDecoder(r.Body).Decode(&section); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+		section.ID = current.ID
+		section.Name = current.Name
+
+		if !ar.validPool(w, r, &section) {
+			return
+		}
+		if err := ar.store.Sections(r.Context()).Update(&section); err != nil {
+			sendChangeError(w, r, err)
+			return
+		}
+
+		updated, err := ar.store.Sections(r.Context()).Find(id)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, updated)
+	}
+}
+
+// Delete section. New tickets can not be created in it, existing ones keep it
+func (ar *AdminRoutes) deleteSection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		if _, err := ar.store.Sections(r.Context()).Find(id); err != nil {
+			sendChangeError(w, r, err)
+			return
+		}
+
+		if err := ar.store.Sections(r.Context()).Delete(id); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]string{"response": fmt.Sprintf("section %d deleted", id)})
+	}
+}
+
+// Check that every helper of pool is active support user. Duplicates are removed.
+// Sends error and returns false if pool is not valid
+func (ar *AdminRoutes) validPool(w http.ResponseWriter, r *http.Request, section *models.Section) bool {
+	pool := make([]int, 0, len(section.Helpers))
+	seen := make(map[int]bool, len(section.Helpers))
+	for _, id := range section.Helpers {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		helper, err := ar.store.User(r.Context()).FindById(id)
+		if err != nil && err != store.ErrRecordNotFound {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return false
+		}
+		if err != nil || !helper.IsActive || (helper.Role != roles.SUPPORT && helper.Role != roles.ADMIN) {
+			responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+				"helpers": fmt.Sprintf("user %d must be active support user", id),
+			})
+			return false
+		}
+		pool = append(pool, id)
+	}
+	section.Helpers = pool
+
+	return true
+}
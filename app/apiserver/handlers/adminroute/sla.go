@@ -0,0 +1,224 @@
+package adminroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/sla"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Default period of SLA report
+const defaultReportPeriod = 30 * 24 * time.Hour
+
+// All SLA policies
+func (ar *AdminRoutes) slaPolicies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		policies, err := ar.store.Sla(r.Context()).Policies()
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, policies)
+	}
+}
+
+// Create SLA policy. Empty section creates default policy
+func (ar *AdminRoutes) createSlaPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		policy := &models.SlaPolicy{}
+		if err := json.NewDecoder(r.Body).Decode(policy); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+		policy.ID = 0
+
+		if !ar.knownSection(w, r, policy.Section) {
+			return
+		}
+		if err := ar.store.Sla(r.Context()).CreatePolicy(policy); err != nil {
+			sendChangeError(w, r, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusCreated, policy)
+	}
+}
+
+// Change section or limits of SLA policy
+func (ar *AdminRoutes) updateSlaPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		policy := &models.SlaPolicy{}
+		if err := json.NewDecoder(r.Body).Decode(policy); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+		policy.ID, _ = strconv.Atoi(mux.Vars(r)["id"])
+
+		if !ar.knownSection(w, r, policy.Section) {
+			return
+		}
+		if err := ar.store.Sla(r.Context()).UpdatePolicy(policy); err != nil {
+			sendChangeError(w, r, err)
+			return
+		}
+
+		policy, err := ar.store.Sla(r.Context()).FindPolicy(policy.ID)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, policy)
+	}
+}
+
+// Delete SLA policy. Registered breaches are kept
+func (ar *AdminRoutes) deleteSlaPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		if _, err := ar.store.Sla(r.Context()).FindPolicy(id); err != nil {
+			sendChangeError(w, r, err)
+			return
+		}
+
+		if err := ar.store.Sla(r.Context()).DeletePolicy(id); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]string{"response": fmt.Sprintf("policy %d deleted", id)})
+	}
+}
+
+// Breaches registered in period
+func (ar *AdminRoutes) slaBreaches() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := reportPeriod(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		breaches, err := ar.store.Sla(r.Context()).Breaches(from, to)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, breaches)
+	}
+}
+
+// SLA compliance of tickets created in period
+func (ar *AdminRoutes) slaReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := reportPeriod(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		policies, err := ar.store.Sla(r.Context()).Policies()
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		timelines, err := ar.store.Sla(r.Context()).Timelines(from, to)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		report := sla.BuildReport(policies, timelines, time.Now().UTC())
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"from":     from,
+			"to":       to,
+			"sections": report.Sections,
+			"total":    report.Total,
+		})
+	}
+}
+
+// Check that policy section exists. Empty section of default policy is
+// always known. Sends error and returns false if section is unknown
+func (ar *AdminRoutes) knownSection(w http.ResponseWriter, r *http.Request, name string) bool {
+	if name == "" {
+		return true
+	}
+
+	_, err := ar.store.Sections(r.Context()).FindByName(name)
+	if err == store.ErrRecordNotFound {
+		responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+			"section": "unknown section",
+		})
+		return false
+	}
+	if err != nil {
+		responses.SendError(w, r, http.StatusInternalServerError, err)
+		return false
+	}
+
+	return true
+}
+
+// Period from 'from' and 'to' query params in RFC3339.
+// Last 30 days by default
+func reportPeriod(r *http.Request) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+	to := time.Now().UTC()
+	if value := query.Get("to"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t.UTC()
+	}
+
+	from := to.Add(-defaultReportPeriod)
+	if value := query.Get("from"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t.UTC()
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, apierrors.ErrEmptyParam
+	}
+
+	return from, to, nil
+}
+
+// Send error of SLA policy or section change
+func sendChangeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case store.ErrRecordNotFound:
+		responses.SendError(w, r, http.StatusNotFound, err)
+		return
+	case store.ErrSlaPolicyExists, store.ErrSectionExists:
+		responses.SendError(w, r, http.StatusConflict, err)
+		return
+	}
+
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		responses.SendError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	fields := make(map[string]string, len(errs))
+	for field, fieldErr := range errs {
+		fields[field] = fieldErr.Error()
+	}
+
+	responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, fields)
+}
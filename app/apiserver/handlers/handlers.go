@@ -7,30 +7,67 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/handlers/adminroute"
+	"github.com/inhumanLightBackend/app/apiserver/handlers/oauthroute"
+	"github.com/inhumanLightBackend/app/apiserver/handlers/resourceroute"
 	supportroutes "github.com/inhumanLightBackend/app/apiserver/handlers/supportroute"
 	"github.com/inhumanLightBackend/app/apiserver/handlers/userroute"
+	"github.com/inhumanLightBackend/app/apiserver/handlers/wsroute"
 	"github.com/inhumanLightBackend/app/apiserver/middleware"
 	"github.com/inhumanLightBackend/app/apiserver/responses"
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/storage"
 	"github.com/inhumanLightBackend/app/store"
 	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+	"github.com/inhumanLightBackend/app/utils/notifications"
 	"github.com/sirupsen/logrus"
 )
 
 type Handlers struct {
-	store  store.Store
-	logger *logrus.Logger
-	router *mux.Router
+	store          store.Store
+	logger         *logrus.Logger
+	router         *mux.Router
+	serviceClients map[string]string
+	dispatcher     *notifications.Dispatcher
+	storage        storage.Storage
+	maxUploadSize  int64
+	bus            realtime.Bus
 }
 
 func New(store store.Store, logger *logrus.Logger) *Handlers {
 	return &Handlers{
-		store:  store,
-		logger: logger,
-		router: mux.NewRouter(),
+		store:      store,
+		logger:     logger,
+		router:     mux.NewRouter(),
+		dispatcher: notifications.NewDispatcher(store, logger),
+		storage:    storage.NewMemory(),
+		bus:        realtime.NewHub(),
 	}
 }
 
+// Set storage of uploaded files and upload size limit. Zero limit keeps default
+func (h *Handlers) SetStorage(storage storage.Storage, maxUploadSize int64) {
+	h.storage = storage
+	h.maxUploadSize = maxUploadSize
+}
+
+// Set bus of ticket events pushed over WebSocket
+func (h *Handlers) SetBus(bus realtime.Bus) {
+	h.bus = bus
+}
+
+// Set dispatcher of external notifications
+func (h *Handlers) SetDispatcher(dispatcher *notifications.Dispatcher) {
+	h.dispatcher = dispatcher
+}
+
+// Set credentials of internal services allowed to introspect tokens
+func (h *Handlers) SetServiceClients(clients map[string]string) {
+	h.serviceClients = clients
+}
+
 func (h *Handlers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.router.ServeHTTP(w, r)
 }
@@ -38,18 +75,24 @@ func (h *Handlers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) SetupRoutes() {
 	// Возможно сделать структуру такую же как и у БД.
 	// То есть раскидать все хендлеры по интерфейсам. А в этом методе вызывать их роуты
-	middleware := middleware.New(h.logger)
+	middleware := middleware.New(h.store, h.logger)
 	h.router.Use(middleware.Logging)
 	h.router.Use(handlers.CORS(handlers.AllowedOrigins([]string{"*"})))
 
 	h.router.HandleFunc("/signup", h.SignUp()).Methods("POST")
+	h.router.HandleFunc("/signup/check", h.CheckUsername()).Methods("GET")
 	h.router.HandleFunc("/signin", h.SignIn()).Methods("POST")
 	h.router.HandleFunc("/checkAccess", h.CheckAccessToken()).Methods("GET")
+	h.router.HandleFunc("/.well-known/jwks.json", h.JWKS()).Methods("GET")
+	oauthroute.New(h.store, h.serviceClients).SetUpRoutes(h.router)
 
 	main := h.router.PathPrefix("/api/v1").Subrouter()
 	main.Use(middleware.Authenticate)
-	userroute.New(h.store).SetUpRoutes(main)
-	supportroutes.New(h.store).SetUpRoutes(main)
+	userroute.New(h.store, h.dispatcher).SetUpRoutes(main)
+	supportroutes.New(h.store, h.dispatcher, h.bus).SetUpRoutes(main)
+	adminroute.New(h.store).SetUpRoutes(main)
+	resourceroute.New(h.store, h.storage, h.maxUploadSize).SetUpRoutes(main)
+	wsroute.New(h.store, h.bus).SetUpRoutes(main)
 }
 
 func (h *Handlers) SignUp() http.HandlerFunc {
@@ -72,7 +115,17 @@ func (h *Handlers) SignUp() http.HandlerFunc {
 			Password: req.Password,
 		}
 
-		if err := h.store.User(r.Context()).Create(user); err != nil {
+		if err := h.store.WithTx(r.Context(), func(tx store.Store) error {
+			if err := tx.User(r.Context()).Create(user); err != nil {
+				return err
+			}
+
+			return tx.Balance(r.Context()).CreateBalance(uint(user.ID))
+		}); err != nil {
+			if err == store.ErrUsernameTaken || err == store.ErrEmailTaken {
+				responses.SendError(w, r, http.StatusConflict, err)
+				return
+			}
 			responses.SendError(w, r, http.StatusBadRequest, err)
 			return
 		}
@@ -81,9 +134,35 @@ func (h *Handlers) SignUp() http.HandlerFunc {
 	}
 }
 
+// Check if username has valid format and not taken
+func (h *Handlers) CheckUsername() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := r.URL.Query().Get("username")
+		if err := models.ValidateLogin(username); err != nil {
+			responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+				"username": err.Error(),
+			})
+			return
+		}
+
+		_, err := h.store.User(r.Context()).FindByLogin(username)
+		if err != nil && err != store.ErrRecordNotFound {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"username":  username,
+			"available": err == store.ErrRecordNotFound,
+		})
+	}
+}
+
 func (h *Handlers) SignIn() http.HandlerFunc {
+	// Login is username or email. Email field is kept for old clients
 	type request struct {
-		Login    string `json:"email"`
+		Login    string `json:"login"`
+		Email    string `json:"email"`
 		Password string `json:"password"`
 	}
 
@@ -93,25 +172,67 @@ func (h *Handlers) SignIn() http.HandlerFunc {
 			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
 			return
 		}
+		if req.Login == "" {
+			req.Login = req.Email
+		}
+		if req.Login == "" {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
 
-		user, err := h.store.User(r.Context()).FindByEmail(req.Login)
+		user, err := h.store.User(r.Context()).FindByLogin(req.Login)
 		if err != nil || !user.ComparePassword(req.Password) {
+			entry := &models.AuditLog{
+				Action:  auditAction.SignInFailed,
+				Details: map[string]interface{}{"login": req.Login},
+			}
+			if user != nil {
+				entry.Target = models.AuditTarget("user", user.ID)
+			}
+			middleware.Audit(h.store, r, entry)
+
 			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrIncorrectEmailOrPassword)
 			return
 		}
+		if !user.IsActive {
+			middleware.Audit(h.store, r, &models.AuditLog{
+				Action:  auditAction.SignInFailed,
+				Target:  models.AuditTarget("user", user.ID),
+				Details: map[string]interface{}{"login": req.Login},
+			})
+
+			responses.SendError(w, r, http.StatusForbidden, apierrors.ErrAccountDisabled)
+			return
+		}
 
-		accToken, err := jwtHelper.Create(user, 1, "access")
+		session := &models.Session{
+			UserId:    user.ID,
+			IP:        middleware.RemoteAddr(r),
+			UserAgent: r.UserAgent(),
+		}
+		if err := h.store.Sessions(r.Context()).Create(session); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		accToken, err := jwtHelper.CreateForSession(user, session.ID, 1, "access")
 		if err != nil {
 			responses.SendError(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		refrToken, err := jwtHelper.Create(user, 30, "refresh")
+		refrToken, err := jwtHelper.CreateForSession(user, session.ID, 30, "refresh")
 		if err != nil {
 			responses.SendError(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
+		middleware.Audit(h.store, r, &models.AuditLog{
+			Action: auditAction.SignIn,
+			Actor:  user.ID,
+			Target: models.AuditTarget("user", user.ID),
+		})
+
 		responses.Respond(w, r, http.StatusOK, map[string]string{
 			"access_token":  accToken,
 			"refresh_token": refrToken,
@@ -123,26 +244,47 @@ func (h *Handlers) CheckAccessToken() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token, err := middleware.GetToken(r)
 		if err != nil {
-			responses.SendError(w, r, http.StatusUnauthorized, err)
+			middleware.SendAuthError(w, r, err)
 			return
 		}
 
 		claims, err := jwtHelper.Validate(token)
-		if err != nil || claims.Type == "access" {
-			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrNotAuthenticated)
+		if err != nil {
+			middleware.SendAuthError(w, r, err)
+			return
+		}
+		if claims.Type != "refresh" {
+			middleware.SendAuthError(w, r, apierrors.ErrInvalidToken)
+			return
+		}
+
+		if claims.SessionId != "" {
+			session, err := h.store.Sessions(r.Context()).Find(claims.SessionId)
+			if err != nil || !session.IsActive() {
+				middleware.SendAuthError(w, r, apierrors.ErrSessionRevoked)
+				return
+			}
+		}
+
+		user, err := h.store.User(r.Context()).FindById(claims.UserId)
+		if err != nil || !user.IsActive {
+			middleware.SendAuthError(w, r, apierrors.ErrAccountDisabled)
 			return
 		}
 
-		accessToken, err := jwtHelper.Create(&models.User{
-			ID:   claims.UserId,
-			Role: claims.Access,
-		}, 1, "access")
+		accessToken, err := jwtHelper.CreateForSession(user, claims.SessionId, 1, "access")
 
 		if err != nil {
 			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrNotAuthenticated)
 			return
 		}
 
+		middleware.Audit(h.store, r, &models.AuditLog{
+			Action: auditAction.TokenRefresh,
+			Actor:  claims.UserId,
+			Target: models.AuditTarget("user", claims.UserId),
+		})
+
 		responses.Respond(w, r, http.StatusOK, map[string]string{
 			"access_token":  accessToken,
 			"refresh_token": token,
@@ -150,3 +292,11 @@ func (h *Handlers) CheckAccessToken() http.HandlerFunc {
 	}
 }
 
+// Publish public keys so other services can verify tokens offline
+func (h *Handlers) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		responses.Respond(w, r, http.StatusOK, jwtHelper.PublicKeys())
+	}
+}
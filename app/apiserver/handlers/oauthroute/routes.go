@@ -0,0 +1,166 @@
+package oauthroute
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+)
+
+type OauthRoutes struct {
+	store   store.Store
+	clients map[string]string
+}
+
+// Introspection response (RFC 7662)
+type introspection struct {
+	Active    bool              `json:"active"`
+	Subject   string            `json:"sub,omitempty"`
+	Role      string            `json:"role,omitempty"`
+	TokenType string            `json:"token_type,omitempty"`
+	ExpiresAt int64             `json:"exp,omitempty"`
+	IssuedAt  int64             `json:"iat,omitempty"`
+	Issuer    string            `json:"iss,omitempty"`
+	Audience  string            `json:"aud,omitempty"`
+	TokenId   string            `json:"jti,omitempty"`
+	SessionId string            `json:"sid,omitempty"`
+	Actor     map[string]string `json:"act,omitempty"`
+}
+
+// Inactive tokens tell nothing else about themselves
+var inactive = &introspection{Active: false}
+
+// New oauth routes. Clients is map of service client id to its secret
+func New(store store.Store, clients map[string]string) *OauthRoutes {
+	return &OauthRoutes{
+		store:   store,
+		clients: clients,
+	}
+}
+
+func (or *OauthRoutes) SetUpRoutes(r *mux.Router) {
+	oauth := r.PathPrefix("/oauth").Subrouter()
+	oauth.HandleFunc("/introspect", or.introspect()).Methods("POST")
+}
+
+// Tell internal service if token or api key is active and who is it
+func (or *OauthRoutes) introspect() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !or.authenticateClient(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspection"`)
+			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrNotAuthenticated)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		token := r.PostForm.Get("token")
+		if token == "" {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		// Hint only tells which lookup goes first, the other one is fallback
+		lookups := []func(*http.Request, string) *introspection{or.introspectJwt, or.introspectApiToken}
+		if r.PostForm.Get("token_type_hint") == "api_token" {
+			lookups[0], lookups[1] = lookups[1], lookups[0]
+		}
+		result := inactive
+		for _, lookup := range lookups {
+			if found := lookup(r, token); found != nil {
+				result = found
+				break
+			}
+		}
+
+		responses.Respond(w, r, http.StatusOK, result)
+	}
+}
+
+// Check service credentials passed with basic auth
+func (or *OauthRoutes) authenticateClient(r *http.Request) bool {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	expected, ok := or.clients[id]
+	if !ok || expected == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(expected)) == 1
+}
+
+// Returns nil if token is not a jwt issued by this service. Refresh
+// tokens are only exchanged for access tokens, so they are inactive
+// for services
+func (or *OauthRoutes) introspectJwt(r *http.Request, token string) *introspection {
+	claims, err := jwtHelper.Validate(token)
+	if err != nil {
+		return nil
+	}
+	if claims.Type == "refresh" {
+		return inactive
+	}
+
+	result := &introspection{
+		Subject:   strconv.Itoa(claims.UserId),
+		Role:      claims.Access,
+		TokenType: claims.Type,
+		ExpiresAt: claims.ExpiresAt,
+		IssuedAt:  claims.IssuedAt,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		TokenId:   claims.Id,
+		SessionId: claims.SessionId,
+	}
+	if claims.ImpersonatorId != 0 {
+		result.Actor = map[string]string{"sub": strconv.Itoa(claims.ImpersonatorId)}
+	}
+
+	if claims.SessionId != "" {
+		session, err := or.store.Sessions(r.Context()).Find(claims.SessionId)
+		if err != nil || !session.IsActive() {
+			return inactive
+		}
+	}
+
+	user, err := or.store.User(r.Context()).FindById(claims.UserId)
+	if err != nil || !user.IsActive {
+		return inactive
+	}
+	result.Role = user.Role
+	result.Active = true
+
+	return result
+}
+
+// Returns nil if there is no user with such api token
+func (or *OauthRoutes) introspectApiToken(r *http.Request, token string) *introspection {
+	user, err := or.store.User(r.Context()).FindByToken(token)
+	if err != nil {
+		return nil
+	}
+	if !user.IsActive {
+		return inactive
+	}
+
+	return &introspection{
+		Active:    true,
+		Subject:   strconv.Itoa(user.ID),
+		Role:      user.Role,
+		TokenType: "api_token",
+		IssuedAt:  user.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,259 @@
+package resourceroute
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/storage"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Default limit of uploaded file size
+const DefaultMaxUploadSize = 10 << 20
+
+// Content types allowed to upload. Type is detected by file content,
+// client provided Content-Type is ignored
+var AllowedTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"application/zip": true,
+	"text/plain":      true,
+}
+
+type ResourceRoutes struct {
+	store         store.Store
+	storage       storage.Storage
+	maxUploadSize int64
+}
+
+func New(store store.Store, storage storage.Storage, maxUploadSize int64) *ResourceRoutes {
+	if maxUploadSize <= 0 {
+		maxUploadSize = DefaultMaxUploadSize
+	}
+
+	return &ResourceRoutes{
+		store:         store,
+		storage:       storage,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func (rr *ResourceRoutes) SetUpRoutes(r *mux.Router) {
+	r.HandleFunc("/resources", rr.upload()).Methods("POST")
+	r.HandleFunc("/resources", rr.list()).Methods("GET")
+	r.HandleFunc("/resources/{id:[0-9]+}", rr.info()).Methods("GET")
+	r.HandleFunc("/resources/{id:[0-9]+}/download", rr.download()).Methods("GET")
+	r.HandleFunc("/resources/{id:[0-9]+}", rr.delete()).Methods("DELETE")
+}
+
+// Upload file from multipart form field 'file'. Optional 'ticket_id'
+// field attaches file to ticket of the user
+func (rr *ResourceRoutes) upload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Space for other form fields and multipart boundaries
+		r.Body = http.MaxBytesReader(w, r.Body, rr.maxUploadSize+1<<20)
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			responses.SendError(w, r, http.StatusRequestEntityTooLarge, apierrors.ErrFileTooLarge)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+		defer file.Close()
+		if header.Size > rr.maxUploadSize {
+			responses.SendError(w, r, http.StatusRequestEntityTooLarge, apierrors.ErrFileTooLarge)
+			return
+		}
+
+		res := &models.Resource{
+			Owner:    middleware.UserId(r),
+			Filename: header.Filename,
+		}
+		if value := r.FormValue("ticket_id"); value != "" {
+			ticketId, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+				return
+			}
+			ticket, err := rr.store.Tickets(r.Context()).Find(uint(ticketId))
+			if err != nil || !policy.CanAccessTicket(policy.FromRequest(r), ticket) {
+				responses.SendError(w, r, http.StatusNotFound, store.ErrRecordNotFound)
+				return
+			}
+			res.TicketId = ticket.ID
+		}
+
+		head := make([]byte, 512)
+		n, err := io.ReadFull(file, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			responses.SendError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		res.MimeType = detectType(head[:n])
+		if !AllowedTypes[res.MimeType] {
+			responses.SendError(w, r, http.StatusUnsupportedMediaType, apierrors.ErrUnsupportedFileType)
+			return
+		}
+
+		if res.Path, err = storage.NewKey(); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if res.Size, err = rr.storage.Save(r.Context(), res.Path, io.MultiReader(bytes.NewReader(head[:n]), file)); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := rr.store.Resources(r.Context()).Create(res); err != nil {
+			rr.storage.Delete(r.Context(), res.Path)
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusCreated, res)
+	}
+}
+
+// List files uploaded by user
+func (rr *ResourceRoutes) list() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resources, err := rr.store.Resources(r.Context()).FindByOwner(middleware.UserId(r))
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, resources)
+	}
+}
+
+// File metadata
+func (rr *ResourceRoutes) info() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := rr.readable(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, res)
+	}
+}
+
+// Stream file content
+func (rr *ResourceRoutes) download() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := rr.readable(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		content, err := rr.storage.Open(r.Context(), res.Path)
+		if err != nil {
+			if err == storage.ErrNotFound {
+				responses.SendError(w, r, http.StatusNotFound, store.ErrRecordNotFound)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", res.MimeType)
+		w.Header().Set("Content-Length", strconv.FormatInt(res.Size, 10))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": res.Filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, content)
+	}
+}
+
+// Delete file. Allowed to uploader and admin. Files linked to a ticket
+// are part of the conversation and can not be deleted
+func (rr *ResourceRoutes) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := rr.find(r)
+		if err != nil || (res.Owner != middleware.UserId(r) && !middleware.IsAdmin(r)) {
+			responses.SendError(w, r, http.StatusNotFound, store.ErrRecordNotFound)
+			return
+		}
+
+		if err := rr.store.Resources(r.Context()).Delete(res.ID); err != nil {
+			switch err {
+			case store.ErrResourceAttached:
+				responses.SendError(w, r, http.StatusConflict, err)
+			case store.ErrRecordNotFound:
+				responses.SendError(w, r, http.StatusNotFound, err)
+			default:
+				responses.SendError(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		if err := rr.storage.Delete(r.Context(), res.Path); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]string{"response": fmt.Sprintf("resource %d deleted", res.ID)})
+	}
+}
+
+// Find resource by path id
+func (rr *ResourceRoutes) find(r *http.Request) (*models.Resource, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return rr.store.Resources(r.Context()).Find(id)
+}
+
+// Find resource user is allowed to read: own upload or file of ticket
+// user can access, same as messages of the ticket. Not allowed resource
+// looks like not existing one
+func (rr *ResourceRoutes) readable(r *http.Request) (*models.Resource, error) {
+	res, err := rr.find(r)
+	if err != nil {
+		return nil, store.ErrRecordNotFound
+	}
+	if res.Owner == middleware.UserId(r) {
+		return res, nil
+	}
+
+	if res.TicketId != 0 {
+		ticket, err := rr.store.Tickets(r.Context()).Find(res.TicketId)
+		if err == nil && policy.CanAccessTicket(policy.FromRequest(r), ticket) {
+			return res, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
+// Detect content type without parameters
+func detectType(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+
+	return mediaType
+}
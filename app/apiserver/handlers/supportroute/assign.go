@@ -0,0 +1,261 @@
+package supportroutes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/notificationStatus"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketAction"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Take not assigned ticket by helper
+func (sr *SupportRoutes) accept() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticket, err := sr.ticketFromPath(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, store.ErrRecordNotFound)
+			return
+		}
+		if ticket.Helper != -1 {
+			responses.SendError(w, r, http.StatusConflict, apierrors.ErrTicketAssigned)
+			return
+		}
+
+		sr.changeHelper(w, r, ticket, ticketAction.Accept, middleware.UserId(r))
+	}
+}
+
+// Assign or reassign ticket to helper by admin
+func (sr *SupportRoutes) assign() http.HandlerFunc {
+	type request struct {
+		HelperId int `json:"helper_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		ticket, err := sr.ticketFromPath(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, store.ErrRecordNotFound)
+			return
+		}
+
+		helper, err := sr.store.User(r.Context()).FindById(req.HelperId)
+		if err != nil || !helper.IsActive || (helper.Role != roles.SUPPORT && helper.Role != roles.ADMIN) {
+			responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+				"helper_id": "must be active support user",
+			})
+			return
+		}
+		if ticket.Helper == helper.ID {
+			responses.SendError(w, r, http.StatusConflict, apierrors.ErrTicketAssigned)
+			return
+		}
+
+		sr.changeHelper(w, r, ticket, ticketAction.Assign, helper.ID)
+	}
+}
+
+// Return ticket to the queue by its helper
+func (sr *SupportRoutes) unassign() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticket, err := sr.ticketFromPath(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, store.ErrRecordNotFound)
+			return
+		}
+		if ticket.Helper != middleware.UserId(r) {
+			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrNotTicketHelper)
+			return
+		}
+
+		sr.changeHelper(w, r, ticket, ticketAction.Unassign, -1)
+	}
+}
+
+// Helper changes of ticket
+func (sr *SupportRoutes) history() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticket, err := sr.ticketFromPath(r)
+		if err == nil && !policy.CanAccessTicket(policy.FromRequest(r), ticket) {
+			err = store.ErrRecordNotFound
+		}
+		if err != nil {
+			sendTicketError(w, r, err)
+			return
+		}
+
+		entries, err := sr.store.TicketHistory(r.Context()).FindByTicket(ticket.ID)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, entries)
+	}
+}
+
+// Set new helper of ticket, write history and notify customer and new helper
+// unless they muted the ticket. Helper -1 returns ticket to the queue
+func (sr *SupportRoutes) changeHelper(w http.ResponseWriter, r *http.Request, ticket *models.Ticket, action string, helperId int) {
+	if ticket.Status == ticketStatus.Closed {
+		responses.SendError(w, r, http.StatusConflict, apierrors.ErrTicketClosed)
+		return
+	}
+
+	actor, from, fromStatus := middleware.UserId(r), ticket.Helper, ticket.Status
+	notes := ticketHelperNotes(ticket, action, actor, helperId)
+	sent := make([]*models.Notification, 0, len(notes))
+	if err := sr.store.WithTx(r.Context(), func(tx store.Store) error {
+		var err error
+		switch {
+		case helperId == -1:
+			err = tx.Tickets(r.Context()).Unassign(ticket.ID)
+		case action == ticketAction.Accept:
+			// Other helper may take ticket meanwhile
+			err = tx.Tickets(r.Context()).Accept(ticket.ID, &models.User{ID: helperId})
+		default:
+			err = tx.Tickets(r.Context()).Assign(ticket.ID, &models.User{ID: helperId})
+		}
+		if err != nil {
+			return err
+		}
+		if err := changeHelperStatus(r, tx, ticket, helperId, actor); err != nil {
+			return err
+		}
+
+		if err := tx.TicketHistory(r.Context()).Create(&models.TicketHistory{
+			TicketId:   ticket.ID,
+			Actor:      actor,
+			Action:     action,
+			FromHelper: from,
+			ToHelper:   helperId,
+		}); err != nil {
+			return err
+		}
+
+		for _, note := range notes {
+			muted, err := tx.TicketMutes(r.Context()).IsMuted(note.For, ticket.ID)
+			if err != nil {
+				return err
+			}
+			if muted {
+				continue
+			}
+			if err := tx.Notifications(r.Context()).Create(note); err != nil {
+				return err
+			}
+			sent = append(sent, note)
+		}
+
+		return nil
+	}); err != nil {
+		if err == store.ErrTicketAssigned {
+			responses.SendError(w, r, http.StatusConflict, apierrors.ErrTicketAssigned)
+			return
+		}
+		responses.SendError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, note := range sent {
+		sr.dispatcher.Notify(r.Context(), note.For, note.Message)
+	}
+
+	ticket, err := sr.store.Tickets(r.Context()).Find(ticket.ID)
+	if err != nil {
+		responses.SendError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	sr.publish(realtime.EventStatus, ticket.ID, actor, &models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     fromStatus,
+		To:       ticket.Status,
+		Actor:    actor,
+	})
+
+	responses.Respond(w, r, http.StatusOK, ticket)
+}
+
+// Move ticket taken by helper in process and ticket returned to the queue
+// back to opened. Other statuses do not depend on helper. Helper taking
+// ticket changes status by themselves, the rest is done by system
+func changeHelperStatus(r *http.Request, tx store.Store, ticket *models.Ticket, helperId int, actor int) error {
+	change := &models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     ticket.Status,
+	}
+	party := ticketStatus.Helper
+	switch {
+	case helperId != -1 && ticket.Status == ticketStatus.Opened:
+		change.To = ticketStatus.InProcess
+		if helperId != actor {
+			party = ticketStatus.System
+		}
+	case helperId == -1 && ticket.Status == ticketStatus.InProcess:
+		change.To = ticketStatus.Opened
+		party = ticketStatus.System
+	default:
+		return nil
+	}
+	if party == ticketStatus.Helper {
+		change.Actor = actor
+	}
+	if !ticketStatus.CanTransition(change.From, change.To, party) {
+		return nil
+	}
+
+	return tx.Tickets(r.Context()).ChangeStatus(change)
+}
+
+// Notifications for customer and new helper. Actor is not notified about own action
+func ticketHelperNotes(ticket *models.Ticket, action string, actor, helperId int) []*models.Notification {
+	message := fmt.Sprintf("Your ticket #%d \"%s\" was taken by a helper", ticket.ID, ticket.Title)
+	switch {
+	case action == ticketAction.Unassign:
+		message = fmt.Sprintf("Your ticket #%d \"%s\" is waiting for a new helper", ticket.ID, ticket.Title)
+	case ticket.Helper != -1:
+		message = fmt.Sprintf("Your ticket #%d \"%s\" was passed to another helper", ticket.ID, ticket.Title)
+	}
+
+	notes := []*models.Notification{{
+		Message: message,
+		Status:  notificationStatus.Info,
+		For:     int(ticket.From),
+	}}
+	if helperId != -1 && helperId != actor {
+		notes = append(notes, &models.Notification{
+			Message: fmt.Sprintf("Ticket #%d \"%s\" assigned to you", ticket.ID, ticket.Title),
+			Status:  notificationStatus.Info,
+			For:     helperId,
+		})
+	}
+
+	return notes
+}
+
+// Find ticket by id from path
+func (sr *SupportRoutes) ticketFromPath(r *http.Request) (*models.Ticket, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return sr.store.Tickets(r.Context()).Find(uint(id))
+}
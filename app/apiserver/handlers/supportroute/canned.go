@@ -0,0 +1,315 @@
+package supportroutes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Shared and personal canned responses of helper, most used first
+func (sr *SupportRoutes) cannedResponses() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := sr.store.CannedResponses(r.Context()).FindVisible(middleware.UserId(r))
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, list)
+	}
+}
+
+// Create personal canned response. Shared responses are created by admins
+func (sr *SupportRoutes) createCannedResponse() http.HandlerFunc {
+	type request struct {
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+		Status string `json:"status"`
+		Shared bool   `json:"shared"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+		if req.Shared && middleware.Role(r) != roles.ADMIN {
+			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrPermissionDenied)
+			return
+		}
+
+		response := &models.CannedResponse{
+			Owner:  middleware.UserId(r),
+			Title:  req.Title,
+			Body:   req.Body,
+			Status: req.Status,
+		}
+		if req.Shared {
+			response.Owner = 0
+		}
+		if err := sr.store.CannedResponses(r.Context()).Create(response); err != nil {
+			sendCannedError(w, r, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusCreated, response)
+	}
+}
+
+// Change title, body or status of canned response
+func (sr *SupportRoutes) updateCannedResponse() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		current, err := sr.editableCanned(r)
+		if err != nil {
+			sendCannedError(w, r, err)
+			return
+		}
+
+		response := *current
+		if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+		response.ID, response.Owner = current.ID, current.Owner
+
+		if err := sr.store.CannedResponses(r.Context()).Update(&response); err != nil {
+			sendCannedError(w, r, err)
+			return
+		}
+
+		updated, err := sr.store.CannedResponses(r.Context()).Find(response.ID)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, updated)
+	}
+}
+
+// Delete canned response
+func (sr *SupportRoutes) deleteCannedResponse() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := sr.editableCanned(r)
+		if err != nil {
+			sendCannedError(w, r, err)
+			return
+		}
+
+		if err := sr.store.CannedResponses(r.Context()).Delete(response.ID); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]string{"response": fmt.Sprintf("canned response %d deleted", response.ID)})
+	}
+}
+
+// Render canned response for ticket, so helper can edit it before sending
+func (sr *SupportRoutes) renderCannedResponse() http.HandlerFunc {
+	type request struct {
+		TicketId uint `json:"ticket_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		response, err := sr.visibleCanned(r)
+		if err != nil {
+			sendCannedError(w, r, err)
+			return
+		}
+		ticket, err := sr.visibleTicket(r, req.TicketId)
+		if err != nil {
+			sendTicketError(w, r, err)
+			return
+		}
+
+		text, err := sr.renderCanned(r, response, ticket)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"ticket_id": ticket.ID,
+			"message":   text,
+		})
+	}
+}
+
+// Post canned response to ticket and move ticket to status of response
+// in one call. Ticket already in that status only gets the message
+func (sr *SupportRoutes) applyMacro() http.HandlerFunc {
+	type request struct {
+		TicketId uint `json:"ticket_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		response, err := sr.visibleCanned(r)
+		if err != nil {
+			sendCannedError(w, r, err)
+			return
+		}
+		ticket, err := sr.visibleTicket(r, req.TicketId)
+		if err != nil {
+			sendTicketError(w, r, err)
+			return
+		}
+
+		actor := middleware.UserId(r)
+		party := policy.TicketParty(policy.FromRequest(r), ticket)
+		var change *models.TicketStatusChange
+		if response.Status != "" && response.Status != ticket.Status {
+			if !ticketStatus.CanTransition(ticket.Status, response.Status, party) {
+				responses.SendError(w, r, http.StatusConflict, apierrors.ErrTransitionNotAllowed)
+				return
+			}
+			change = &models.TicketStatusChange{
+				TicketId: ticket.ID,
+				From:     ticket.Status,
+				To:       response.Status,
+				Actor:    actor,
+				Comment:  response.Title,
+			}
+		}
+
+		text, err := sr.renderCanned(r, response, ticket)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		message := &models.TicketMessage{
+			TicketId: ticket.ID,
+			Message:  text,
+			Who:      uint(actor),
+		}
+		if err := sr.store.Tickets(r.Context()).Reply(message, change); err != nil {
+			if err == store.ErrStatusChanged {
+				responses.SendError(w, r, http.StatusConflict, err)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		// Message is already posted, so failed usage count does not fail request
+		sr.store.CannedResponses(r.Context()).Used(response.ID)
+
+		message.Attachments = make([]*models.Resource, 0)
+		sr.publish(realtime.EventMessage, ticket.ID, actor, message)
+		if change == nil {
+			sr.notifyMessage(r, ticket.ID, actor)
+		} else {
+			sr.publish(realtime.EventStatus, ticket.ID, actor, change)
+			sr.notifyStatus(r, ticket, change)
+			middleware.Audit(sr.store, r, &models.AuditLog{
+				Action: auditAction.TicketStatusChange,
+				Target: models.AuditTarget("ticket", int(ticket.ID)),
+				Details: map[string]interface{}{
+					"from":  change.From,
+					"to":    change.To,
+					"macro": response.ID,
+				},
+			})
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"message": message,
+			"change":  change,
+		})
+	}
+}
+
+// Render response with ticket values. Rendering has no side effects,
+// usage is counted only when response is posted
+func (sr *SupportRoutes) renderCanned(r *http.Request, response *models.CannedResponse, ticket *models.Ticket) (string, error) {
+	// Deleted users render with empty values
+	var err error
+	vars := &models.CannedVars{Ticket: ticket}
+	if vars.User, err = sr.store.User(r.Context()).FindById(int(ticket.From)); err != nil && err != store.ErrRecordNotFound {
+		return "", err
+	}
+	if vars.Helper, err = sr.store.User(r.Context()).FindById(middleware.UserId(r)); err != nil && err != store.ErrRecordNotFound {
+		return "", err
+	}
+
+	return response.Render(vars), nil
+}
+
+// Canned response from path visible to user. Personal responses
+// of other users look like not existing ones
+func (sr *SupportRoutes) visibleCanned(r *http.Request) (*models.CannedResponse, error) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	response, err := sr.store.CannedResponses(r.Context()).Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if !response.Shared() && response.Owner != middleware.UserId(r) {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return response, nil
+}
+
+// Canned response from path user can change. Shared responses
+// are changed by admins only
+func (sr *SupportRoutes) editableCanned(r *http.Request) (*models.CannedResponse, error) {
+	response, err := sr.visibleCanned(r)
+	if err != nil {
+		return nil, err
+	}
+	if response.Shared() && middleware.Role(r) != roles.ADMIN {
+		return nil, apierrors.ErrPermissionDenied
+	}
+
+	return response, nil
+}
+
+// Send error of canned response lookup or change
+func sendCannedError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case store.ErrRecordNotFound:
+		responses.SendError(w, r, http.StatusNotFound, err)
+		return
+	case apierrors.ErrPermissionDenied:
+		responses.SendError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		responses.SendError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	fields := make(map[string]string, len(errs))
+	for field, fieldErr := range errs {
+		fields[field] = fieldErr.Error()
+	}
+
+	responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, fields)
+}
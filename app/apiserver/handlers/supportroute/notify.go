@@ -0,0 +1,118 @@
+package supportroutes
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Mute notifications about ticket for user
+func (sr *SupportRoutes) mute() http.HandlerFunc {
+	return sr.muteHandler(func(r *http.Request, ticket *models.Ticket) error {
+		return sr.store.TicketMutes(r.Context()).Mute(middleware.UserId(r), ticket.ID)
+	})
+}
+
+// Unmute notifications about ticket for user
+func (sr *SupportRoutes) unmute() http.HandlerFunc {
+	return sr.muteHandler(func(r *http.Request, ticket *models.Ticket) error {
+		return sr.store.TicketMutes(r.Context()).Unmute(middleware.UserId(r), ticket.ID)
+	})
+}
+
+// Check if user muted ticket
+func (sr *SupportRoutes) muted() http.HandlerFunc {
+	return sr.muteHandler(nil)
+}
+
+// Apply change to mute setting of visible ticket and respond with current setting
+func (sr *SupportRoutes) muteHandler(change func(*http.Request, *models.Ticket) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticket, err := sr.ticketFromPath(r)
+		if err == nil && !policy.CanAccessTicket(policy.FromRequest(r), ticket) {
+			err = store.ErrRecordNotFound
+		}
+		if err != nil {
+			sendTicketError(w, r, err)
+			return
+		}
+
+		if change != nil {
+			if err := change(r, ticket); err != nil {
+				responses.SendError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		muted, err := sr.store.TicketMutes(r.Context()).IsMuted(middleware.UserId(r), ticket.ID)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"ticket_id": ticket.ID,
+			"muted":     muted,
+		})
+	}
+}
+
+// Notify helpers about new ticket in the queue
+func (sr *SupportRoutes) notifyCreated(r *http.Request, ticket *models.Ticket) {
+	active := true
+	helpers, err := sr.store.User(r.Context()).List(&store.UserFilter{
+		Role:   roles.SUPPORT,
+		Active: &active,
+	})
+	if err != nil {
+		return
+	}
+
+	recipients := make([]int, 0, len(helpers))
+	for _, helper := range helpers {
+		recipients = append(recipients, helper.ID)
+	}
+	sr.dispatcher.NotifyTicket(r.Context(), ticket.ID, recipients,
+		fmt.Sprintf("New ticket #%d \"%s\" in section \"%s\"", ticket.ID, ticket.Title, ticket.Section))
+}
+
+// Notify other party about new message
+func (sr *SupportRoutes) notifyMessage(r *http.Request, ticketId uint, actor int) {
+	ticket, err := sr.store.Tickets(r.Context()).Find(ticketId)
+	if err != nil {
+		return
+	}
+
+	sr.dispatcher.NotifyTicket(r.Context(), ticket.ID, ticketParties(ticket, actor),
+		fmt.Sprintf("New message in ticket #%d \"%s\"", ticket.ID, ticket.Title))
+}
+
+// Notify other party about status change
+func (sr *SupportRoutes) notifyStatus(r *http.Request, ticket *models.Ticket, change *models.TicketStatusChange) {
+	message := fmt.Sprintf("Ticket #%d \"%s\" is %s now", ticket.ID, ticket.Title, change.To)
+	if change.To == ticketStatus.Closed {
+		message = fmt.Sprintf("Ticket #%d \"%s\" was closed", ticket.ID, ticket.Title)
+	}
+
+	sr.dispatcher.NotifyTicket(r.Context(), ticket.ID, ticketParties(ticket, change.Actor), message)
+}
+
+// Customer and helper of ticket except actor
+func ticketParties(ticket *models.Ticket, actor int) []int {
+	parties := make([]int, 0, 2)
+	if int(ticket.From) != actor {
+		parties = append(parties, int(ticket.From))
+	}
+	if ticket.Helper != -1 && ticket.Helper != actor && ticket.Helper != int(ticket.From) {
+		parties = append(parties, ticket.Helper)
+	}
+
+	return parties
+}
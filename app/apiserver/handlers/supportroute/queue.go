@@ -0,0 +1,132 @@
+package supportroutes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// Tickets of all users for admins and tickets assigned to caller or not
+// assigned for helpers, with filters, cursor pagination and counts per status
+func (sr *SupportRoutes) queue() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := ticketFilter(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+		if !policy.QueueScope(policy.FromRequest(r), filter) {
+			responses.SendError(w, r, http.StatusForbidden, apierrors.ErrPermissionDenied)
+			return
+		}
+
+		tickets, err := sr.store.Tickets(r.Context()).List(filter)
+		if err != nil {
+			if err == store.ErrInvalidCursor {
+				responses.SendError(w, r, http.StatusBadRequest, err)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		counts, err := sr.store.Tickets(r.Context()).CountByStatus(filter)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		for _, status := range ticketStatus.All {
+			if _, ok := counts[status]; !ok {
+				counts[status] = 0
+			}
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"tickets":     tickets,
+			"counts":      counts,
+			"next_cursor": filter.NextCursor(tickets),
+		})
+	}
+}
+
+// Parse ticket queue filter from query params. Status is comma separated list,
+// helper is user id, 'me' or 'none' for not assigned tickets
+func ticketFilter(r *http.Request) (*store.TicketFilter, error) {
+	query := r.URL.Query()
+	filter := &store.TicketFilter{
+		Section: query.Get("section"),
+		Cursor:  query.Get("cursor"),
+		Limit:   defaultPageSize,
+	}
+
+	var err error
+	if status := query.Get("status"); status != "" {
+		for _, item := range strings.Split(status, ",") {
+			if !ticketStatus.IsValid(item) {
+				return nil, store.ErrProccessingStatusNotFound
+			}
+			filter.Status = append(filter.Status, item)
+		}
+	}
+	switch helper := query.Get("helper"); helper {
+	case "":
+	case "me":
+		id := middleware.UserId(r)
+		filter.Helper = &id
+	case "none":
+		id := -1
+		filter.Helper = &id
+	default:
+		id, err := strconv.Atoi(helper)
+		if err != nil {
+			return nil, err
+		}
+		filter.Helper = &id
+	}
+	switch sortBy := query.Get("sort"); sortBy {
+	case "", store.TicketSortCreatedAt, store.TicketSortLastActivity:
+		filter.SortBy = sortBy
+	default:
+		return nil, apierrors.ErrEmptyParam
+	}
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return nil, apierrors.ErrEmptyParam
+	}
+	if from := query.Get("created_from"); from != "" {
+		if filter.CreatedFrom, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, err
+		}
+	}
+	if to := query.Get("created_to"); to != "" {
+		if filter.CreatedTo, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, err
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			return nil, apierrors.ErrEmptyParam
+		}
+		if filter.Limit > maxPageSize {
+			filter.Limit = maxPageSize
+		}
+	}
+
+	return filter, nil
+}
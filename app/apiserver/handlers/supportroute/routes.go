@@ -8,30 +8,60 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/inhumanLightBackend/app/apiserver/apierrors"
 	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
 	"github.com/inhumanLightBackend/app/apiserver/responses"
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/realtime"
 	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/utils/notifications"
 )
 
+// Max count of files attached to ticket or message. Size and type of
+// each file are limited on upload
+const MaxAttachments = 5
+
 type SupportRoutes struct {
-	store store.Store
+	store      store.Store
+	dispatcher *notifications.Dispatcher
+	bus        realtime.Bus
 }
 
-func New(store store.Store) *SupportRoutes {
+func New(store store.Store, dispatcher *notifications.Dispatcher, bus realtime.Bus) *SupportRoutes {
 	return &SupportRoutes{
-		store: store,
+		store:      store,
+		dispatcher: dispatcher,
+		bus:        bus,
 	}
 }
 
 func (sr *SupportRoutes) SetUpRoutes(r *mux.Router) {
 	support := r.PathPrefix("/support").Subrouter()
 
+	support.HandleFunc("/sections", sr.sections()).Methods("GET")
 	support.HandleFunc("/ticket/create", sr.createTicket()).Methods("POST")
 	support.HandleFunc("/ticket", sr.ticket()).Methods("GET")
 	support.HandleFunc("/tickets", sr.tickets()).Methods("GET")
+	support.HandleFunc("/search", sr.search()).Methods("GET")
+	support.Handle("/queue", middleware.StaffOnly(sr.queue())).Methods("GET")
+	support.Handle("/tickets/{id:[0-9]+}/accept", middleware.StaffOnly(sr.accept())).Methods("POST")
+	support.Handle("/tickets/{id:[0-9]+}/assign", middleware.AdminOnly(sr.assign())).Methods("POST")
+	support.Handle("/tickets/{id:[0-9]+}/unassign", middleware.StaffOnly(sr.unassign())).Methods("POST")
+	support.Handle("/tickets/{id:[0-9]+}/history", middleware.StaffOnly(sr.history())).Methods("GET")
 	support.HandleFunc("/message/add", sr.addMessage()).Methods("POST")
 	support.HandleFunc("/messages", sr.messages()).Methods("GET")
-	support.HandleFunc("/ticket/status", sr.changeMessageStatus()).Methods("GET")
+	support.HandleFunc("/ticket/transition", sr.transition()).Methods("POST")
+	support.HandleFunc("/tickets/{id:[0-9]+}/statuses", sr.statusHistory()).Methods("GET")
+	support.HandleFunc("/tickets/{id:[0-9]+}/mute", sr.muted()).Methods("GET")
+	support.HandleFunc("/tickets/{id:[0-9]+}/mute", sr.mute()).Methods("POST")
+	support.HandleFunc("/tickets/{id:[0-9]+}/mute", sr.unmute()).Methods("DELETE")
+	support.Handle("/canned", middleware.StaffOnly(sr.cannedResponses())).Methods("GET")
+	support.Handle("/canned", middleware.StaffOnly(sr.createCannedResponse())).Methods("POST")
+	support.Handle("/canned/{id:[0-9]+}", middleware.StaffOnly(sr.updateCannedResponse())).Methods("PUT")
+	support.Handle("/canned/{id:[0-9]+}", middleware.StaffOnly(sr.deleteCannedResponse())).Methods("DELETE")
+	support.Handle("/canned/{id:[0-9]+}/render", middleware.StaffOnly(sr.renderCannedResponse())).Methods("POST")
+	support.Handle("/canned/{id:[0-9]+}/apply", middleware.StaffOnly(sr.applyMacro())).Methods("POST")
 }
 
 func (sr *SupportRoutes) createTicket() http.HandlerFunc {
@@ -39,6 +69,7 @@ func (sr *SupportRoutes) createTicket() http.HandlerFunc {
 		Title       string `json:"title"`
 		Description string `json:"description"`
 		Section     string `json:"section"`
+		Attachments []int  `json:"attachments"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -52,6 +83,22 @@ func (sr *SupportRoutes) createTicket() http.HandlerFunc {
 			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
 			return
 		}
+		if len(req.Attachments) > MaxAttachments {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrTooManyAttachments)
+			return
+		}
+
+		section, err := sr.store.Sections(r.Context()).FindByName(req.Section)
+		if err != nil {
+			if err == store.ErrRecordNotFound {
+				responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+					"section": "unknown section",
+				})
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
 		ctxUser := middleware.UserContextMap(r.Context().Value(middleware.CtxUserKey))
 		userId, _ := strconv.Atoi(ctxUser["id"])
@@ -59,14 +106,32 @@ func (sr *SupportRoutes) createTicket() http.HandlerFunc {
 		ticket := &models.Ticket{
 			Title:       req.Title,
 			Description: req.Description,
-			Section:     req.Section,
+			Section:     section.Name,
+			Priority:    section.Priority,
 			From:        uint(userId),
 		}
 
-		if err := sr.store.Tickets(r.Context()).Create(ticket); err != nil {
-			responses.SendError(w, r, http.StatusInternalServerError, err)
+		helperId := -1
+		if err := sr.store.WithTx(r.Context(), func(tx store.Store) error {
+			if err := tx.Tickets(r.Context()).Create(ticket); err != nil {
+				return err
+			}
+			if err := tx.Resources(r.Context()).Attach(userId, ticket.ID, 0, req.Attachments); err != nil {
+				return err
+			}
+
+			var err error
+			helperId, err = routeTicket(r, tx, section, ticket)
+			return err
+		}); err != nil {
+			sendAttachError(w, r, err)
 			return
 		}
+		if helperId == -1 {
+			sr.notifyCreated(r, ticket)
+		} else {
+			sr.notifyAssigned(r, ticket, helperId)
+		}
 
 		responses.Respond(w, r, http.StatusOK, map[string]string{
 			"message": "created",
@@ -82,17 +147,29 @@ func (sr *SupportRoutes) ticket() http.HandlerFunc {
 			return
 		}
 		ticketId, err := strconv.Atoi(id[0])
-		if err != nil {
+		if err != nil || ticketId <= 0 {
 			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
 			return
 		}
 
-		ticket, err := sr.store.Tickets(r.Context()).Find(uint(ticketId))
+		ticket, err := sr.visibleTicket(r, uint(ticketId))
 		if err != nil {
-			responses.SendError(w, r, http.StatusBadRequest, err)
+			sendTicketError(w, r, err)
 			return
 		}
 
+		attached, err := sr.store.Resources(r.Context()).FindByTicket(ticket.ID)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		ticket.Attachments = make([]*models.Resource, 0)
+		for _, res := range attached {
+			if res.MessageId == 0 {
+				ticket.Attachments = append(ticket.Attachments, res)
+			}
+		}
+
 		responses.Respond(w, r, http.StatusOK, ticket)
 	}
 }
@@ -118,8 +195,9 @@ func (sr *SupportRoutes) tickets() http.HandlerFunc {
 
 func (sr *SupportRoutes) addMessage() http.HandlerFunc {
 	type request struct {
-		Message  string `json:"message"`
-		TicketId uint	`json:"ticket_id"`
+		Message     string `json:"message"`
+		TicketId    uint   `json:"ticket_id"`
+		Attachments []int  `json:"attachments"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -129,21 +207,46 @@ func (sr *SupportRoutes) addMessage() http.HandlerFunc {
 			return
 		}
 
-		if req.Message == "" || req.TicketId == 0 {
+		// Message with attachments may have no text
+		if (req.Message == "" && len(req.Attachments) == 0) || req.TicketId == 0 {
 			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
 			return
 		}
+		if len(req.Attachments) > MaxAttachments {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrTooManyAttachments)
+			return
+		}
+
+		if _, err := sr.visibleTicket(r, req.TicketId); err != nil {
+			sendTicketError(w, r, err)
+			return
+		}
 
 		ctxUser := middleware.UserContextMap(r.Context().Value(middleware.CtxUserKey))
 		userId, _ := strconv.Atoi(ctxUser["id"])
-		if err := sr.store.Tickets(r.Context()).AddMessage(&models.TicketMessage{
+		message := &models.TicketMessage{
 			TicketId: req.TicketId,
-			Message: req.Message,
-			Who: uint(userId),
+			Message:  req.Message,
+			Who:      uint(userId),
+		}
+		if err := sr.store.WithTx(r.Context(), func(tx store.Store) error {
+			if err := tx.Tickets(r.Context()).AddMessage(message); err != nil {
+				return err
+			}
+
+			return tx.Resources(r.Context()).Attach(userId, message.TicketId, message.ID, req.Attachments)
 		}); err != nil {
-			responses.SendError(w, r, http.StatusInternalServerError, err)
+			sendAttachError(w, r, err)
 			return
 		}
+		if len(req.Attachments) > 0 {
+			if err := store.AttachToMessages(sr.store.Resources(r.Context()), message.TicketId, []*models.TicketMessage{message}); err != nil {
+				responses.SendError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		sr.publish(realtime.EventMessage, message.TicketId, userId, message)
+		sr.notifyMessage(r, message.TicketId, userId)
 
 		responses.Respond(w, r, http.StatusOK, map[string]string {
 			"message": "added",
@@ -160,11 +263,16 @@ func (sr *SupportRoutes) messages() http.HandlerFunc {
 		}
 
 		ticketId, err := strconv.Atoi(id[0])
-		if err != nil {
+		if err != nil || ticketId <= 0 {
 			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
 			return
 		}
 
+		if _, err := sr.visibleTicket(r, uint(ticketId)); err != nil {
+			sendTicketError(w, r, err)
+			return
+		}
+
 		messages, err := sr.store.Tickets(r.Context()).TakeMessages(uint(ticketId))
 		if err != nil {
 			responses.SendError(w, r, http.StatusBadRequest, err)
@@ -175,33 +283,139 @@ func (sr *SupportRoutes) messages() http.HandlerFunc {
 	}
 }
 
-func (sr *SupportRoutes) changeMessageStatus() http.HandlerFunc {
-	return func (w http.ResponseWriter, r *http.Request)  {
-		id, ok := r.URL.Query()["id"]
-		if !ok && len(id) == 0 {
-			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+// Move ticket to new status if party of user is allowed to
+func (sr *SupportRoutes) transition() http.HandlerFunc {
+	type request struct {
+		TicketId uint   `json:"ticket_id"`
+		Status   string `json:"status"`
+		Comment  string `json:"comment"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
 			return
 		}
-
-		status, ok := r.URL.Query()["st"]
-		if !ok && len(status) == 0 {
+		if req.TicketId == 0 || req.Status == "" {
 			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
 			return
 		}
+		if !ticketStatus.IsValid(req.Status) {
+			responses.SendError(w, r, http.StatusBadRequest, store.ErrProccessingStatusNotFound)
+			return
+		}
 
-		ticketId, err := strconv.Atoi(id[0])
+		ticket, err := sr.visibleTicket(r, req.TicketId)
 		if err != nil {
-			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			sendTicketError(w, r, err)
 			return
 		}
 
-		if err := sr.store.Tickets(r.Context()).ChangeStatus(uint(ticketId), status[0]); err != nil {
-			responses.SendError(w, r, http.StatusBadRequest, err)
+		party := policy.TicketParty(policy.FromRequest(r), ticket)
+		if !ticketStatus.CanTransition(ticket.Status, req.Status, party) {
+			responses.SendError(w, r, http.StatusConflict, apierrors.ErrTransitionNotAllowed)
 			return
 		}
 
-		responses.Respond(w, r, http.StatusOK, map[string]string {
-			"message": "status chnaged to " + status[0],
+		change := &models.TicketStatusChange{
+			TicketId: ticket.ID,
+			From:     ticket.Status,
+			To:       req.Status,
+			Actor:    middleware.UserId(r),
+			Comment:  req.Comment,
+		}
+		if err := sr.store.Tickets(r.Context()).ChangeStatus(change); err != nil {
+			if err == store.ErrStatusChanged {
+				responses.SendError(w, r, http.StatusConflict, err)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		sr.publish(realtime.EventStatus, ticket.ID, change.Actor, change)
+		sr.notifyStatus(r, ticket, change)
+		middleware.Audit(sr.store, r, &models.AuditLog{
+			Action: auditAction.TicketStatusChange,
+			Target: models.AuditTarget("ticket", int(ticket.ID)),
+			Details: map[string]interface{}{
+				"from": change.From,
+				"to":   change.To,
+			},
+		})
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"change": change,
+			"next":   ticketStatus.Next(change.To, party),
 		})
 	}
-}
\ No newline at end of file
+}
+
+// Status changes of ticket
+func (sr *SupportRoutes) statusHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticket, err := sr.ticketFromPath(r)
+		if err == nil && !policy.CanAccessTicket(policy.FromRequest(r), ticket) {
+			err = store.ErrRecordNotFound
+		}
+		if err != nil {
+			sendTicketError(w, r, err)
+			return
+		}
+
+		changes, err := sr.store.Tickets(r.Context()).StatusHistory(ticket.ID)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, changes)
+	}
+}
+
+// Push event to subscribers of ticket. Events are best effort,
+// change is already saved
+func (sr *SupportRoutes) publish(eventType string, ticketId uint, actor int, data interface{}) {
+	sr.bus.Publish(&realtime.Event{
+		Type:     eventType,
+		TicketId: ticketId,
+		Actor:    actor,
+		Data:     data,
+	})
+}
+
+// Find ticket visible to user. Not visible ticket looks like not existing one,
+// so ticket ids can not be enumerated
+func (sr *SupportRoutes) visibleTicket(r *http.Request, id uint) (*models.Ticket, error) {
+	ticket, err := sr.store.Tickets(r.Context()).Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.CanAccessTicket(policy.FromRequest(r), ticket) {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return ticket, nil
+}
+
+// Send error of ticket lookup
+func sendTicketError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == store.ErrRecordNotFound {
+		responses.SendError(w, r, http.StatusNotFound, err)
+		return
+	}
+
+	responses.SendError(w, r, http.StatusInternalServerError, err)
+}
+
+// Send error of attaching resources. Resource of other user, already
+// attached one or not existing one can not be attached
+func sendAttachError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == store.ErrRecordNotFound {
+		responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrAttachmentNotFound)
+		return
+	}
+
+	responses.SendError(w, r, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,53 @@
+package supportroutes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search tickets and messages visible to user by words of 'q' query param.
+// Best matches go first
+func (sr *SupportRoutes) search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+			return
+		}
+
+		filter := policy.SearchScope(policy.FromRequest(r), query)
+		filter.Limit = defaultSearchLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+				return
+			}
+			if limit > maxSearchLimit {
+				limit = maxSearchLimit
+			}
+			filter.Limit = limit
+		}
+
+		hits, err := sr.store.Tickets(r.Context()).Search(filter)
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"query":   query,
+			"results": hits,
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package supportroutes
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/models/ticketAction"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/routing"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Sections tickets can be created in. Helper pools are shown to staff only
+func (sr *SupportRoutes) sections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sections, err := sr.store.Sections(r.Context()).List()
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !middleware.IsStaff(r) {
+			for i, section := range sections {
+				public := *section
+				public.Helpers = make([]int, 0)
+				sections[i] = &public
+			}
+		}
+
+		responses.Respond(w, r, http.StatusOK, sections)
+	}
+}
+
+// Assign new ticket to helper from section pool. Ticket stays in the
+// queue if nobody from pool can take it. Returns id of helper or -1
+func routeTicket(r *http.Request, tx store.Store, section *models.Section, ticket *models.Ticket) (int, error) {
+	if section.Routing == sectionRouting.RoundRobin {
+		// Turn is read and moved under section lock, so concurrent
+		// tickets do not go to the same helper
+		locked, err := tx.Sections(r.Context()).FindForUpdate(section.ID)
+		if err != nil && err != store.ErrRecordNotFound {
+			return -1, err
+		}
+		if err == nil {
+			section = locked
+		}
+	}
+
+	available := make(map[int]int, len(section.Helpers))
+	for _, id := range section.Helpers {
+		helper, err := tx.User(r.Context()).FindById(id)
+		if err == store.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return -1, err
+		}
+		if !helper.IsActive || (helper.Role != roles.SUPPORT && helper.Role != roles.ADMIN) {
+			continue
+		}
+
+		available[id] = 0
+		if section.Routing != sectionRouting.LeastLoaded {
+			continue
+		}
+		helperId := id
+		counts, err := tx.Tickets(r.Context()).CountByStatus(&store.TicketFilter{
+			Helper: &helperId,
+			Status: []string{ticketStatus.Opened, ticketStatus.InProcess, ticketStatus.WaitingForCustomer},
+		})
+		if err != nil {
+			return -1, err
+		}
+		for _, count := range counts {
+			available[id] += count
+		}
+	}
+
+	helperId := routing.PickHelper(section, available)
+	if helperId == -1 {
+		return -1, nil
+	}
+
+	if err := tx.Tickets(r.Context()).Accept(ticket.ID, &models.User{ID: helperId}); err != nil {
+		return -1, err
+	}
+	if err := changeHelperStatus(r, tx, ticket, helperId, 0); err != nil {
+		return -1, err
+	}
+	if err := tx.TicketHistory(r.Context()).Create(&models.TicketHistory{
+		TicketId:   ticket.ID,
+		Actor:      0,
+		Action:     ticketAction.AutoAssign,
+		FromHelper: -1,
+		ToHelper:   helperId,
+	}); err != nil {
+		return -1, err
+	}
+	if section.Routing == sectionRouting.RoundRobin {
+		// Section removed meanwhile does not need its turn
+		err := tx.Sections(r.Context()).SetLastHelper(section.ID, helperId)
+		if err != nil && err != store.ErrRecordNotFound {
+			return -1, err
+		}
+	}
+
+	return helperId, nil
+}
+
+// Notify helper about ticket routed to them
+func (sr *SupportRoutes) notifyAssigned(r *http.Request, ticket *models.Ticket, helperId int) {
+	sr.dispatcher.NotifyTicket(r.Context(), ticket.ID, []int{helperId},
+		fmt.Sprintf("Ticket #%d \"%s\" assigned to you", ticket.ID, ticket.Title))
+}
@@ -0,0 +1,212 @@
+package userroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// List contacts of authenticated user
+func (ur *UserRoutes) contacts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contacts, err := ur.store.Contacts(r.Context()).FindByUser(middleware.UserId(r))
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, contacts)
+	}
+}
+
+// Add contact and send verification code to it
+func (ur *UserRoutes) addContact() http.HandlerFunc {
+	type request struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		contact := &models.Contact{
+			UserId: middleware.UserId(r),
+			Kind:   req.Kind,
+			Value:  req.Value,
+		}
+		if err := ur.store.Contacts(r.Context()).Create(contact); err != nil {
+			if err == store.ErrContactExists {
+				responses.SendError(w, r, http.StatusConflict, err)
+				return
+			}
+			sendValidationErrors(w, r, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusCreated, map[string]interface{}{
+			"contact":           contact,
+			"verification_sent": ur.sendCode(r, contact) == nil,
+		})
+	}
+}
+
+// Confirm contact with code
+func (ur *UserRoutes) verifyContact() http.HandlerFunc {
+	type request struct {
+		Code string `json:"code"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		contact, err := ur.ownContact(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		// Attempt is counted before code is checked, so code can not be guessed
+		if contact.CodeAttempts, err = ur.store.Contacts(r.Context()).CountAttempt(contact.ID); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !contact.Verify(req.Code) {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrInvalidCode)
+			return
+		}
+
+		if err := ur.store.Contacts(r.Context()).Update(contact); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, contact)
+	}
+}
+
+// Send new verification code
+func (ur *UserRoutes) resendCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contact, err := ur.ownContact(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		if contact.Verified {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrContactVerified)
+			return
+		}
+
+		if err := contact.NewVerificationCode(); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := ur.store.Contacts(r.Context()).Update(contact); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]interface{}{
+			"verification_sent": ur.sendCode(r, contact) == nil,
+		})
+	}
+}
+
+// Enable or disable notifications to contact. Only verified contact can be enabled
+func (ur *UserRoutes) updateContact() http.HandlerFunc {
+	type request struct {
+		Enabled *bool `json:"enabled"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil || req.Enabled == nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		contact, err := ur.ownContact(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		if *req.Enabled && !contact.Verified {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrContactNotVerified)
+			return
+		}
+
+		contact.Enabled = *req.Enabled
+		if err := ur.store.Contacts(r.Context()).Update(contact); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, contact)
+	}
+}
+
+func (ur *UserRoutes) deleteContact() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contact, err := ur.ownContact(r)
+		if err != nil {
+			responses.SendError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		if err := ur.store.Contacts(r.Context()).Delete(contact.ID); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		responses.Respond(w, r, http.StatusOK, map[string]string{
+			"message": "contact deleted",
+		})
+	}
+}
+
+// Find contact from url which belongs to authenticated user
+func (ur *UserRoutes) ownContact(r *http.Request) (*models.Contact, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, store.ErrRecordNotFound
+	}
+
+	contact, err := ur.store.Contacts(r.Context()).Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if contact.UserId != middleware.UserId(r) {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return contact, nil
+}
+
+func (ur *UserRoutes) sendCode(r *http.Request, contact *models.Contact) error {
+	return ur.dispatcher.SendTo(
+		r.Context(),
+		contact,
+		fmt.Sprintf("Your InhumanLight verification code: %s", contact.VerificationCode),
+	)
+}
@@ -0,0 +1,110 @@
+package userroute
+
+import (
+	"encoding/json"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// User as shown to admins and in patch responses. Api token is never exposed
+type UserView struct {
+	*models.User
+	Token    string `json:"api_token,omitempty"`
+	IsActive bool   `json:"is_active"`
+}
+
+// Redacted view of the user
+func NewUserView(user *models.User) *UserView {
+	return &UserView{User: user, IsActive: user.IsActive}
+}
+
+// Apply JSON Merge Patch from request body to the user and save it.
+// Editable fields depend on role of authenticated user
+func PatchUser(s store.Store, w http.ResponseWriter, r *http.Request, user *models.User) {
+	patch := make(map[string]json.RawMessage)
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+		return
+	}
+
+	role := roles.USER
+	if middleware.IsAdmin(r) {
+		role = roles.ADMIN
+	}
+
+	oldRole, oldActive := user.Role, user.IsActive
+	if err := user.ApplyPatch(patch, role); err != nil {
+		sendValidationErrors(w, r, err)
+		return
+	}
+
+	if err := s.User(r.Context()).Update(user); err != nil {
+		switch err {
+		case store.ErrEmailTaken:
+			sendValidationErrors(w, r, validation.Errors{"email": err})
+		case store.ErrUsernameTaken:
+			sendValidationErrors(w, r, validation.Errors{"login": err})
+		default:
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if oldActive && !user.IsActive {
+		if err := s.Sessions(r.Context()).RevokeAll(user.ID, ""); err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if oldRole != user.Role {
+		middleware.Audit(s, r, &models.AuditLog{
+			Action: auditAction.RoleChange,
+			Target: models.AuditTarget("user", user.ID),
+			Details: map[string]interface{}{
+				"from": oldRole,
+				"to":   user.Role,
+			},
+		})
+	}
+	if oldActive != user.IsActive {
+		middleware.Audit(s, r, &models.AuditLog{
+			Action: auditAction.ActiveChange,
+			Target: models.AuditTarget("user", user.ID),
+			Details: map[string]interface{}{
+				"from": oldActive,
+				"to":   user.IsActive,
+			},
+		})
+	}
+
+	responses.Respond(w, r, http.StatusOK, NewUserView(user))
+}
+
+// Send validation errors per field. Attempt to change not editable field is a permission error
+func sendValidationErrors(w http.ResponseWriter, r *http.Request, err error) {
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		responses.SendError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	fields := make(map[string]string, len(errs))
+	code, apiErr := http.StatusBadRequest, apierrors.ErrValidation
+	for field, fieldErr := range errs {
+		fields[field] = fieldErr.Error()
+		if fieldErr == models.ErrFieldNotEditable {
+			code, apiErr = http.StatusUnauthorized, apierrors.ErrPermissionDenied
+		}
+	}
+
+	responses.SendFieldErrors(w, r, code, apiErr, fields)
+}
@@ -0,0 +1,192 @@
+package userroute
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"net/http"
+
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Ticket with messages for data export
+type exportTicket struct {
+	*models.Ticket
+	Messages []*models.TicketMessage `json:"messages"`
+}
+
+// Export all personal data of authenticated user as ZIP of JSON files
+func (ur *UserRoutes) export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		files, err := ur.collectUserData(r, middleware.UserId(r))
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		middleware.Audit(ur.store, r, &models.AuditLog{
+			Action: auditAction.DataExport,
+			Target: models.AuditTarget("user", middleware.UserId(r)),
+		})
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", "attachment; filename=export.zip")
+		w.WriteHeader(http.StatusOK)
+
+		archive := zip.NewWriter(w)
+		for _, name := range exportFiles {
+			f, err := archive.Create(name)
+			if err != nil {
+				return
+			}
+			encoder := json.NewEncoder(f)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(files[name]); err != nil {
+				return
+			}
+		}
+		archive.Close()
+	}
+}
+
+// Files of data export in archive order
+var exportFiles = []string{
+	"profile.json",
+	"tickets.json",
+	"balance.json",
+	"notifications.json",
+	"sessions.json",
+	"contacts.json",
+	"deletion.json",
+}
+
+// Collect user data from every repository. Key is file name in export
+func (ur *UserRoutes) collectUserData(r *http.Request, userId int) (map[string]interface{}, error) {
+	user, err := ur.store.User(r.Context()).FindById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := ur.store.Tickets(r.Context()).FindAll(uint(userId))
+	if err != nil {
+		return nil, err
+	}
+	exportTickets := make([]*exportTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		messages, err := ur.store.Tickets(r.Context()).TakeMessages(ticket.ID)
+		if err != nil {
+			return nil, err
+		}
+		exportTickets = append(exportTickets, &exportTicket{Ticket: ticket, Messages: messages})
+	}
+
+	transactions, err := ur.store.Balance(r.Context()).AllTransactions(uint(userId))
+	if err != nil && err != store.ErrRecordNotFound {
+		return nil, err
+	}
+
+	notifications, err := ur.store.Notifications(r.Context()).FindAll(uint(userId))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := ur.store.Sessions(r.Context()).FindByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := ur.store.Contacts(r.Context()).FindByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	deletion, err := ur.store.Deletions(r.Context()).Find(userId)
+	if err != nil && err != store.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"profile.json":       user,
+		"tickets.json":       exportTickets,
+		"balance.json":       transactions,
+		"notifications.json": notifications,
+		"sessions.json":      sessions,
+		"contacts.json":      contacts,
+		"deletion.json":      deletion,
+	}, nil
+}
+
+// Request deletion of authenticated user account. Personal data is purged
+// after grace period, until then request can be cancelled
+func (ur *UserRoutes) requestDeletion() http.HandlerFunc {
+	type request struct {
+		Password string `json:"password"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
+			return
+		}
+
+		user, err := ur.store.User(r.Context()).FindById(middleware.UserId(r))
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !user.ComparePassword(req.Password) {
+			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrIncorrectPassword)
+			return
+		}
+
+		deletion := &models.AccountDeletion{UserId: user.ID}
+		if err := ur.store.Deletions(r.Context()).Create(deletion); err != nil {
+			if err == store.ErrDeletionPending {
+				responses.SendError(w, r, http.StatusConflict, err)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		middleware.Audit(ur.store, r, &models.AuditLog{
+			Action: auditAction.DeletionRequest,
+			Target: models.AuditTarget("user", user.ID),
+			Details: map[string]interface{}{
+				"purge_at": deletion.PurgeAt,
+			},
+		})
+
+		responses.Respond(w, r, http.StatusAccepted, deletion)
+	}
+}
+
+// Cancel pending deletion of authenticated user account
+func (ur *UserRoutes) cancelDeletion() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId := middleware.UserId(r)
+		if err := ur.store.Deletions(r.Context()).Cancel(userId); err != nil {
+			if err == store.ErrRecordNotFound {
+				responses.SendError(w, r, http.StatusNotFound, err)
+				return
+			}
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		middleware.Audit(ur.store, r, &models.AuditLog{
+			Action: auditAction.DeletionCancel,
+			Target: models.AuditTarget("user", userId),
+		})
+
+		responses.Respond(w, r, http.StatusOK, map[string]string{
+			"message": "deletion cancelled",
+		})
+	}
+}
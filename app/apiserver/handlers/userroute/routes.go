@@ -3,7 +3,6 @@ package userroute
 import (
 	"encoding/json"
 	"net/http"
-	"reflect"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -11,22 +10,37 @@ import (
 	"github.com/inhumanLightBackend/app/apiserver/middleware"
 	"github.com/inhumanLightBackend/app/apiserver/responses"
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/models/notificationStatus"
 	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/utils/notifications"
 )
 
 type UserRoutes struct {
-	store store.Store
+	store      store.Store
+	dispatcher *notifications.Dispatcher
 }
 
-func New(store store.Store) *UserRoutes {
+func New(store store.Store, dispatcher *notifications.Dispatcher) *UserRoutes {
 	return &UserRoutes{
-		store: store,
+		store:      store,
+		dispatcher: dispatcher,
 	}
 }
 
 func (ur *UserRoutes) SetUpRoutes(r *mux.Router) {
 	r.HandleFunc("/user", ur.user()).Methods("GET")
-	r.HandleFunc("/updateUser", ur.updateUser()).Methods("POST")
+	r.Handle("/users/me", middleware.NotImpersonated(ur.patchMe())).Methods("PATCH")
+	r.Handle("/users/me/password", middleware.NotImpersonated(ur.changePassword())).Methods("POST")
+	r.Handle("/users/me/export", middleware.NotImpersonated(ur.export())).Methods("GET")
+	r.Handle("/users/me/deletion", middleware.NotImpersonated(ur.requestDeletion())).Methods("POST")
+	r.Handle("/users/me/deletion", middleware.NotImpersonated(ur.cancelDeletion())).Methods("DELETE")
+	r.HandleFunc("/users/me/contacts", ur.contacts()).Methods("GET")
+	r.Handle("/users/me/contacts", middleware.NotImpersonated(ur.addContact())).Methods("POST")
+	r.Handle("/users/me/contacts/{id:[0-9]+}", middleware.NotImpersonated(ur.updateContact())).Methods("PATCH")
+	r.Handle("/users/me/contacts/{id:[0-9]+}", middleware.NotImpersonated(ur.deleteContact())).Methods("DELETE")
+	r.Handle("/users/me/contacts/{id:[0-9]+}/verify", middleware.NotImpersonated(ur.verifyContact())).Methods("POST")
+	r.Handle("/users/me/contacts/{id:[0-9]+}/resend", middleware.NotImpersonated(ur.resendCode())).Methods("POST")
 	r.HandleFunc("/notif/update", ur.updateNotif()).Methods("GET")
 	r.HandleFunc("/notif/check", ur.checkNotif()).Methods("POST")
 }
@@ -60,59 +74,92 @@ func (ur *UserRoutes) user() http.HandlerFunc {
 	}
 }
 
-func (ur *UserRoutes) updateUser() http.HandlerFunc {
-	isZeroValue := func(x interface{}) bool {
-		return x == reflect.Zero(reflect.TypeOf(x)).Interface()
+// Patch authenticated user with JSON Merge Patch
+func (ur *UserRoutes) patchMe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := ur.store.User(r.Context()).FindById(middleware.UserId(r))
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		PatchUser(ur.store, w, r, user)
+	}
+}
+
+// Change password of authenticated user. Other sessions of the user are revoked
+func (ur *UserRoutes) changePassword() http.HandlerFunc {
+	type request struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		userModel := &models.User{}
-		if err := json.NewDecoder(r.Body).Decode(userModel); err != nil {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
 			responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrNotValidBody)
 			return
 		}
 
-		if !middleware.IsAdmin(r) && userModel.Role != "" {
-			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrPermissionDenied)
+		user, err := ur.store.User(r.Context()).FindById(middleware.UserId(r))
+		if err != nil {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		if userModel.Token != "" {
-			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrPermissionDenied)
+		if !user.ComparePassword(req.CurrentPassword) {
+			responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+				"current_password": apierrors.ErrIncorrectPassword.Error(),
+			})
 			return
 		}
 
-		userCtx := middleware.UserContextMap(r.Context().Value(middleware.CtxUserKey))
-		userId, _ := strconv.Atoi(userCtx["id"])
-		authenticatedUser, err := ur.store.User(r.Context()).FindById(userId)
-		if err != nil {
-			responses.SendError(w, r, http.StatusInternalServerError, err)
+		if err := user.ValidateNewPassword(req.NewPassword); err != nil {
+			responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+				"new_password": err.Error(),
+			})
 			return
 		}
 
-		rNewModel := reflect.ValueOf(userModel)
-		if rNewModel.Kind() == reflect.Ptr {
-			rNewModel = rNewModel.Elem()
-			for i := 0; i < rNewModel.NumField(); i++ {
-				field := rNewModel.Field(i)
-				if !isZeroValue(field.Interface()) {
-					reflect.ValueOf(authenticatedUser).Elem().Field(i).Set(field)
-				}
-			}
+		if req.NewPassword == req.CurrentPassword {
+			responses.SendFieldErrors(w, r, http.StatusBadRequest, apierrors.ErrValidation, map[string]string{
+				"new_password": apierrors.ErrSamePassword.Error(),
+			})
+			return
 		}
 
-		if err := authenticatedUser.Validate(); err != nil {
+		if err := user.SetPassword(req.NewPassword); err != nil {
 			responses.SendError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		if err := ur.store.User(r.Context()).Update(authenticatedUser); err != nil {
+		message := "Your password was changed. If it was not you, contact support"
+		if err := ur.store.WithTx(r.Context(), func(tx store.Store) error {
+			if err := tx.User(r.Context()).Update(user); err != nil {
+				return err
+			}
+			if err := tx.Sessions(r.Context()).RevokeAll(user.ID, middleware.SessionId(r)); err != nil {
+				return err
+			}
+
+			return tx.Notifications(r.Context()).Create(&models.Notification{
+				Message: message,
+				Status:  notificationStatus.Warnign,
+				For:     user.ID,
+			})
+		}); err != nil {
 			responses.SendError(w, r, http.StatusInternalServerError, err)
 			return
 		}
+		ur.dispatcher.Notify(r.Context(), user.ID, message)
+
+		middleware.Audit(ur.store, r, &models.AuditLog{
+			Action: auditAction.PasswordChange,
+			Target: models.AuditTarget("user", user.ID),
+		})
 
 		responses.Respond(w, r, http.StatusOK, map[string]string{
-			"message": "updated",
+			"message": "password changed",
 		})
 	}
 }
@@ -0,0 +1,243 @@
+package wsroute
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/inhumanLightBackend/app/apiserver/apierrors"
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/apiserver/policy"
+	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+const (
+	// Time allowed to write message to client
+	writeWait = 10 * time.Second
+	// Time allowed to read next pong from client
+	pongWait = 60 * time.Second
+	// Send pings to client with this period. Must be less than pongWait
+	pingPeriod = pongWait * 9 / 10
+	// Max size of client command
+	maxCommandSize = 512
+)
+
+// Commands sent by client
+const (
+	cmdSubscribe   = "subscribe"
+	cmdUnsubscribe = "unsubscribe"
+	cmdTyping      = "typing"
+)
+
+// Command sent by client
+type command struct {
+	Type     string `json:"type"`
+	TicketId uint   `json:"ticket_id"`
+}
+
+// Reply to client command
+type reply struct {
+	Type     string `json:"type"`
+	TicketId uint   `json:"ticket_id"`
+	Error    string `json:"error,omitempty"`
+}
+
+type WsRoutes struct {
+	store    store.Store
+	bus      realtime.Bus
+	upgrader websocket.Upgrader
+}
+
+func New(store store.Store, bus realtime.Bus) *WsRoutes {
+	return &WsRoutes{
+		store: store,
+		bus:   bus,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Api is open for all origins, token is required anyway
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (wr *WsRoutes) SetUpRoutes(r *mux.Router) {
+	r.HandleFunc("/ws", wr.connect()).Methods("GET")
+}
+
+// Open connection receiving events of tickets. Tickets from optional
+// 'tickets' query param (comma separated ids) are subscribed on connect,
+// others are subscribed by commands
+func (wr *WsRoutes) connect() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tickets := make([]uint, 0)
+		if value := r.URL.Query().Get("tickets"); value != "" {
+			for _, item := range strings.Split(value, ",") {
+				id, err := strconv.ParseUint(item, 10, 32)
+				if err != nil || id == 0 {
+					responses.SendError(w, r, http.StatusBadRequest, apierrors.ErrEmptyParam)
+					return
+				}
+				if err := wr.canSubscribe(r, uint(id)); err != nil {
+					sendTicketError(w, r, err)
+					return
+				}
+				tickets = append(tickets, uint(id))
+			}
+		}
+
+		conn, err := wr.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrader already sent error to client
+			return
+		}
+
+		c := &client{
+			conn:    conn,
+			sub:     wr.bus.Subscribe(),
+			replies: make(chan *reply, 16),
+			userId:  middleware.UserId(r),
+		}
+		for _, id := range tickets {
+			c.sub.Join(id)
+		}
+
+		go c.write()
+		wr.read(c, r)
+	}
+}
+
+// Read client commands until connection is closed
+func (wr *WsRoutes) read(c *client, r *http.Request) {
+	defer c.sub.Close()
+
+	c.conn.SetReadLimit(maxCommandSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		cmd := &command{}
+		if err := c.conn.ReadJSON(cmd); err != nil {
+			switch err.(type) {
+			case *json.SyntaxError, *json.UnmarshalTypeError:
+				// Connection is still usable
+				c.reply(&reply{Type: "error", Error: apierrors.ErrNotValidBody.Error()})
+				continue
+			}
+
+			return
+		}
+
+		switch cmd.Type {
+		case cmdSubscribe:
+			if err := wr.canSubscribe(r, cmd.TicketId); err != nil {
+				c.reply(&reply{Type: "error", TicketId: cmd.TicketId, Error: err.Error()})
+				continue
+			}
+			c.sub.Join(cmd.TicketId)
+			c.reply(&reply{Type: "subscribed", TicketId: cmd.TicketId})
+		case cmdUnsubscribe:
+			c.sub.Leave(cmd.TicketId)
+			c.reply(&reply{Type: "unsubscribed", TicketId: cmd.TicketId})
+		case cmdTyping:
+			if !c.sub.Joined(cmd.TicketId) {
+				c.reply(&reply{Type: "error", TicketId: cmd.TicketId, Error: store.ErrRecordNotFound.Error()})
+				continue
+			}
+			// Typing indicator is best effort
+			wr.bus.Publish(&realtime.Event{
+				Type:     realtime.EventTyping,
+				TicketId: cmd.TicketId,
+				Actor:    c.userId,
+			})
+		default:
+			c.reply(&reply{Type: "error", Error: apierrors.ErrEmptyParam.Error()})
+		}
+	}
+}
+
+// Check if ticket is visible to user. Not visible ticket looks like
+// not existing one
+func (wr *WsRoutes) canSubscribe(r *http.Request, ticketId uint) error {
+	ticket, err := wr.store.Tickets(r.Context()).Find(ticketId)
+	if err != nil {
+		return err
+	}
+	if !policy.CanAccessTicket(policy.FromRequest(r), ticket) {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Connected user. Only write loop writes to connection
+type client struct {
+	conn    *websocket.Conn
+	sub     *realtime.Subscription
+	replies chan *reply
+	userId  int
+}
+
+// Queue reply to command. Reply is dropped if client does not read them
+func (c *client) reply(rep *reply) {
+	select {
+	case c.replies <- rep:
+	default:
+	}
+}
+
+// Write events and replies to client until subscription is closed
+// or connection is broken
+func (c *client) write() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		var message interface{}
+		select {
+		case event, ok := <-c.sub.Events():
+			if !ok {
+				c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+				return
+			}
+			// Own typing indicator is not sent back
+			if event.Type == realtime.EventTyping && event.Actor == c.userId {
+				continue
+			}
+			message = event
+		case rep := <-c.replies:
+			message = rep
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+			continue
+		}
+
+		c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := c.conn.WriteJSON(message); err != nil {
+			return
+		}
+	}
+}
+
+// Send error of ticket lookup
+func sendTicketError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == store.ErrRecordNotFound {
+		responses.SendError(w, r, http.StatusNotFound, err)
+		return
+	}
+
+	responses.SendError(w, r, http.StatusInternalServerError, err)
+}
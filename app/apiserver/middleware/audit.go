@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Write entry to the audit log. Ip address and actor (if empty)
+// are taken from the request
+func Audit(s store.Store, r *http.Request, entry *models.AuditLog) error {
+	if entry.Actor == 0 {
+		entry.Actor = UserId(r)
+	}
+	entry.IP = RemoteAddr(r)
+	if IsImpersonated(r) {
+		if entry.Details == nil {
+			entry.Details = make(map[string]interface{})
+		}
+		entry.Details["impersonator_id"] = ImpersonatorId(r)
+	}
+
+	return s.Audit(r.Context()).Create(entry)
+}
@@ -16,4 +16,40 @@ func UserContextMap(ctx interface{}) map[string]string {
 func IsAdmin(r *http.Request) bool {
 	userCtx := UserContextMap(r.Context().Value(CtxUserKey))
 	return userCtx["access"] == roles.ADMIN
-}
\ No newline at end of file
+}
+
+// Get role of user in context of request
+func Role(r *http.Request) string {
+	userCtx := UserContextMap(r.Context().Value(CtxUserKey))
+	return userCtx["access"]
+}
+
+// Check if user in context is support helper or admin
+func IsStaff(r *http.Request) bool {
+	userCtx := UserContextMap(r.Context().Value(CtxUserKey))
+	return userCtx["access"] == roles.SUPPORT || userCtx["access"] == roles.ADMIN
+}
+
+// Get id of user in context of request. Returns 0 if request is anonymous
+func UserId(r *http.Request) int {
+	userCtx := UserContextMap(r.Context().Value(CtxUserKey))
+	return cast.ToInt(userCtx["id"])
+}
+
+// Get id of admin who impersonates user in context of request.
+// Returns 0 if request is not impersonated
+func ImpersonatorId(r *http.Request) int {
+	userCtx := UserContextMap(r.Context().Value(CtxUserKey))
+	return cast.ToInt(userCtx["impersonator_id"])
+}
+
+// Check if request made with impersonation token
+func IsImpersonated(r *http.Request) bool {
+	return ImpersonatorId(r) != 0
+}
+
+// Get id of session the request token was issued for
+func SessionId(r *http.Request) string {
+	userCtx := UserContextMap(r.Context().Value(CtxUserKey))
+	return userCtx["session_id"]
+}
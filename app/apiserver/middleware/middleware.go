@@ -8,6 +8,9 @@ import (
 	"github.com/felixge/httpsnoop"
 	"github.com/inhumanLightBackend/app/apiserver/apierrors"
 	"github.com/inhumanLightBackend/app/apiserver/responses"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/store"
 	"github.com/inhumanLightBackend/app/utils/jwtHelper"
 	"github.com/sirupsen/logrus"
 )
@@ -32,12 +35,14 @@ const (
 )
 
 type Middleware struct {
+	store  store.Store
 	logger *logrus.Logger
 }
 
 // New instance of middleware
-func New(logger *logrus.Logger) *Middleware {
+func New(store store.Store, logger *logrus.Logger) *Middleware {
 	return &Middleware{
+		store:  store,
 		logger: logger,
 	}
 }
@@ -46,59 +51,115 @@ func New(logger *logrus.Logger) *Middleware {
 func (m * Middleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token, err := GetToken(r)
+		if err == apierrors.ErrNotAuthenticated {
+			if token = upgradeToken(r); token != "" {
+				err = nil
+			}
+		}
 		if err != nil {
-			responses.SendError(w, r, http.StatusUnauthorized, err)
+			SendAuthError(w, r, err)
 			return
 		}
 
 		claims, err := jwtHelper.Validate(token)
-		if err != nil || claims.Type == "refresh" {
-			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrNotAuthenticated)
+		if err != nil {
+			SendAuthError(w, r, err)
+			return
+		}
+		if claims.Type == "refresh" {
+			SendAuthError(w, r, apierrors.ErrInvalidToken)
+			return
+		}
+
+		if claims.SessionId != "" {
+			session, err := m.store.Sessions(r.Context()).Find(claims.SessionId)
+			if err != nil || !session.IsActive() {
+				SendAuthError(w, r, apierrors.ErrSessionRevoked)
+				return
+			}
+		}
+
+		// Accounts are never deleted, purged ones are deactivated.
+		// Impersonation tokens have no session, so their target must exist
+		user, err := m.store.User(r.Context()).FindById(claims.UserId)
+		if err != nil && err != store.ErrRecordNotFound {
+			responses.SendError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if (user == nil && claims.ImpersonatorId != 0) || (user != nil && !user.IsActive) {
+			SendAuthError(w, r, apierrors.ErrAccountDisabled)
 			return
 		}
+		// Role may have changed since the token was issued
+		role := claims.Access
+		if user != nil {
+			role = user.Role
+		}
 
 		ctx := context.WithValue(r.Context(), CtxUserKey, map[string]interface{}{
-			"id":     claims.UserId,
-			"access": claims.Access,
+			"id":              claims.UserId,
+			"access":          role,
+			"impersonator_id": claims.ImpersonatorId,
+			"session_id":      claims.SessionId,
 		})
+		r = r.WithContext(ctx)
+
+		if claims.ImpersonatorId != 0 {
+			if err := Audit(m.store, r, &models.AuditLog{
+				Action: auditAction.ImpersonatedAccess,
+				Actor:  claims.ImpersonatorId,
+				Target: models.AuditTarget("user", claims.UserId),
+				Details: map[string]interface{}{
+					"method": r.Method,
+					"uri":    r.URL.String(),
+				},
+			}); err != nil {
+				m.logger.WithError(err).Error("Can not audit impersonated request")
+			}
+		}
 		
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
 
-// Loggin request recived by API
-func (m *Middleware) Logging(next http.Handler) http.Handler {
+// Allow request only for users with admin privilege
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsAdmin(r) {
+			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrPermissionDenied)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
 
-	ipAddrFromRemoteAddr := func(s string) string {
-		idx := strings.LastIndex(s, ":")
-		if idx == -1 {
-			return s
+// Allow request only for support helpers and admins
+func StaffOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsStaff(r) {
+			responses.SendError(w, r, http.StatusUnauthorized, apierrors.ErrPermissionDenied)
+			return
 		}
 
-		return s[:idx]
-	}
-	
-	// getRemoteAddr returns ip address of the client making the request,
-	// taking into account http proxies
-	getRemoteAddr := func(r *http.Request) string {
-		header := r.Header
-		hRealIp := header.Get("X-Real-IP")
-		hForwardedFor := header.Get("X-Forwarded-For")
-		if hRealIp == "" && hForwardedFor == "" {
-			return ipAddrFromRemoteAddr(r.RemoteAddr)
-		} 
-		if hForwardedFor != "" {
-			parts := strings.Split(hForwardedFor, ",")
-			for i, p := range parts {
-				parts[i] = strings.TrimSpace(p)
-			}
+		next.ServeHTTP(w, r)
+	})
+}
 
-			return parts[0]
+// Block sensitive operations for requests made with impersonation token
+func NotImpersonated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsImpersonated(r) {
+			responses.SendError(w, r, http.StatusForbidden, apierrors.ErrNotAllowedImpersonating)
+			return
 		}
 
-		return hRealIp
-	}
+		next.ServeHTTP(w, r)
+	})
+}
 
+// Loggin request recived by API
+func (m *Middleware) Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		metrics := httpsnoop.CaptureMetrics(next, w, r)
 
@@ -107,7 +168,7 @@ func (m *Middleware) Logging(next http.Handler) http.Handler {
 			uri: r.URL.String(),
 			refer: r.Header.Get("Refer"),
 			userAgent: r.Header.Get("User-Agent"),
-			ipaddr: getRemoteAddr(r),
+			ipaddr: RemoteAddr(r),
 			code: metrics.Code,
 			size: metrics.Written,
 			duration: metrics.Duration.Seconds(),
@@ -126,22 +187,54 @@ func (m *Middleware) Logging(next http.Handler) http.Handler {
 	})
 }
 
-// Get auth token from header
+// Get auth token from Authorization header (RFC 6750).
+// Deprecated Authentication header is used as fallback
 func GetToken(r *http.Request) (string, error) {
-	header := r.Header.Get("Authentication")
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		header = r.Header.Get("Authentication")
+	}
 	if header == "" {
 		return "", apierrors.ErrNotAuthenticated
 	}
 
-	splitedToken := strings.Split(header, " ")
-	if len(splitedToken) != 2 {
-		return "", apierrors.ErrNotAuthenticated
+	parts := strings.Fields(header)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", apierrors.ErrInvalidAuthHeader
 	}
 
-	token := splitedToken[1]
-	if token == "" {
-		return "", apierrors.ErrNotAuthenticated
+	return parts[1], nil
+}
+
+// Browsers can not set headers of WebSocket handshake, so access token
+// of upgrade request may be passed in 'access_token' query param
+func upgradeToken(r *http.Request) string {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return ""
+	}
+
+	return r.URL.Query().Get("access_token")
+}
+
+// Send 401 with WWW-Authenticate challenge describing what is wrong with the token
+func SendAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	challenge := `Bearer realm="api"`
+	switch err {
+	case apierrors.ErrNotAuthenticated:
+	case apierrors.ErrInvalidAuthHeader:
+		challenge += `, error="invalid_request", error_description="Malformed authorization header"`
+	case jwtHelper.ErrTokenExpired, apierrors.ErrTokenExpired:
+		err = apierrors.ErrTokenExpired
+		challenge += `, error="invalid_token", error_description="The access token expired"`
+	case apierrors.ErrSessionRevoked:
+		challenge += `, error="invalid_token", error_description="The session was revoked"`
+	case apierrors.ErrAccountDisabled:
+		challenge += `, error="invalid_token", error_description="The account is disabled"`
+	default:
+		err = apierrors.ErrInvalidToken
+		challenge += `, error="invalid_token", error_description="The access token is invalid"`
 	}
 
-	return token, nil
-}
\ No newline at end of file
+	w.Header().Set("WWW-Authenticate", challenge)
+	responses.SendError(w, r, http.StatusUnauthorized, err)
+}
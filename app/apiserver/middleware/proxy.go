@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Networks of proxies allowed to pass client address in headers
+var trustedProxies []*net.IPNet
+
+// Set networks of trusted proxies in CIDR notation. Single address
+// is a network of one host. Without trusted proxies headers are ignored
+func SetTrustedProxies(cidrs []string) error {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+
+	return nil
+}
+
+// RemoteAddr returns ip address of the client making the request.
+// X-Forwarded-For and X-Real-IP are used only if request came from
+// trusted proxy, otherwise client could put any address there
+func RemoteAddr(r *http.Request) string {
+	addr := ipAddrFromRemoteAddr(r.RemoteAddr)
+	if !isTrustedProxy(addr) {
+		return addr
+	}
+
+	// Proxies append addresses, the last untrusted one is the client
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		parts := strings.Split(forwardedFor, ",")
+		for i := len(parts) - 1; i >= 0; i-- {
+			part := strings.TrimSpace(parts[i])
+			if part != "" && !isTrustedProxy(part) {
+				return part
+			}
+		}
+	}
+	if realIp := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIp != "" {
+		return realIp
+	}
+
+	return addr
+}
+
+func isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ipAddrFromRemoteAddr(s string) string {
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		return s
+	}
+
+	return host
+}
@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"net/http"
+
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// User making request
+type Actor struct {
+	Id   int
+	Role string
+}
+
+// Get actor from authenticated request
+func FromRequest(r *http.Request) *Actor {
+	return &Actor{
+		Id:   middleware.UserId(r),
+		Role: middleware.Role(r),
+	}
+}
+
+// Check if actor can read, post to and change status of ticket.
+// Customer accesses only own tickets, helper also tickets assigned
+// to them or not assigned at all, admin accesses everything
+func CanAccessTicket(actor *Actor, ticket *models.Ticket) bool {
+	if int(ticket.From) == actor.Id {
+		return true
+	}
+
+	switch actor.Role {
+	case roles.ADMIN:
+		return true
+	case roles.SUPPORT:
+		return ticket.Helper == actor.Id || ticket.Helper == -1
+	default:
+		return false
+	}
+}
+
+// Search filter matching tickets actor can access, same as CanAccessTicket
+func SearchScope(actor *Actor, query string) *store.SearchFilter {
+	filter := &store.SearchFilter{
+		Query: query,
+		From:  actor.Id,
+	}
+
+	switch actor.Role {
+	case roles.ADMIN:
+		filter.All = true
+	case roles.SUPPORT:
+		filter.Helpers = []int{actor.Id, -1}
+	}
+
+	return filter
+}
+
+// Limit queue filter to tickets actor can access, same as CanAccessTicket.
+// Returns false if filter asks for tickets of other helper
+func QueueScope(actor *Actor, filter *store.TicketFilter) bool {
+	if actor.Role != roles.SUPPORT {
+		return true
+	}
+	if filter.Helper != nil && *filter.Helper != actor.Id && *filter.Helper != -1 {
+		return false
+	}
+
+	filter.Helpers = []int{actor.Id, -1}
+	return true
+}
+
+// Party actor plays in ticket status transitions. Owner is customer even if
+// they are staff. Helper must accept ticket before changing its status, admin
+// acts as helper on any ticket. Empty party can not change status
+func TicketParty(actor *Actor, ticket *models.Ticket) string {
+	switch {
+	case int(ticket.From) == actor.Id:
+		return ticketStatus.Customer
+	case actor.Role == roles.ADMIN:
+		return ticketStatus.Helper
+	case actor.Role == roles.SUPPORT && ticket.Helper == actor.Id:
+		return ticketStatus.Helper
+	default:
+		return ""
+	}
+}
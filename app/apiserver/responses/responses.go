@@ -15,4 +15,11 @@ func Respond(w http.ResponseWriter, r *http.Request, code int, data interface{})
 // Send error to the user
 func SendError(w http.ResponseWriter, r *http.Request, code int, err error) {
 	Respond(w, r, code, map[string]string{"error": err.Error()})
-}
\ No newline at end of file
+}
+// Send error with message per invalid field
+func SendFieldErrors(w http.ResponseWriter, r *http.Request, code int, err error, fields map[string]string) {
+	Respond(w, r, code, map[string]interface{}{
+		"error":  err.Error(),
+		"fields": fields,
+	})
+}
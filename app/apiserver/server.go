@@ -5,18 +5,33 @@ import (
 	"time"
 
 	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models/contactKind"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/storage"
 	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/utils/notifications"
+	"github.com/inhumanLightBackend/app/utils/notifications/email"
+	"github.com/inhumanLightBackend/app/utils/notifications/telegram"
+	"github.com/inhumanLightBackend/app/utils/notifications/webhook"
 	"github.com/sirupsen/logrus"
 )
 
 // Init new server
-func NewServer(store store.Store, config *Config) *http.Server {
+func NewServer(store store.Store, config *Config, bus realtime.Bus) (*http.Server, error) {
 	l := logrus.New()
 	l.SetFormatter(&logrus.TextFormatter{
 		TimestampFormat: "2006-01-02 15:04:05",
 		FullTimestamp: true,
 	})
 	h := handlers.New(store, l)
+	h.SetServiceClients(config.IntrospectionClients)
+	h.SetDispatcher(newDispatcher(store, config, l))
+	files, err := storage.NewLocal(config.ResourcesDir)
+	if err != nil {
+		return nil, err
+	}
+	h.SetStorage(files, config.MaxUploadSize)
+	h.SetBus(bus)
 	h.SetupRoutes()
 	s := &http.Server{
 		Addr: config.Port,
@@ -26,5 +41,25 @@ func NewServer(store store.Store, config *Config) *http.Server {
 		Handler: h,
 	}
 
-	return s
+	return s, nil
+}
+
+// Init dispatcher with channels enabled in config
+func newDispatcher(store store.Store, config *Config, l *logrus.Logger) *notifications.Dispatcher {
+	d := notifications.NewDispatcher(store, l)
+	d.Register(contactKind.Webhook, webhook.NewChannel())
+	if config.TelegramToken != "" {
+		d.Register(contactKind.Telegram, telegram.NewChannel(config.TelegramToken))
+	}
+	if config.SmtpHost != "" {
+		d.Register(contactKind.Email, email.NewChannel(&email.Config{
+			Host:     config.SmtpHost,
+			Port:     config.SmtpPort,
+			Username: config.SmtpUsername,
+			Password: config.SmtpPassword,
+			From:     config.SmtpFrom,
+		}))
+	}
+
+	return d
 }
@@ -0,0 +1,454 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_HandleAdminAudit(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	for i := 0; i < 5; i++ {
+		store.Audit(context.Background()).Create(models.NewTestAuditLog(t))
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		role          string
+		expectedCode  int
+		expectedCount int
+	}{
+		{
+			name:          "valid",
+			path:          "?action=sign_in&limit=2",
+			role:          roles.ADMIN,
+			expectedCode:  http.StatusOK,
+			expectedCount: 2,
+		},
+		{
+			name:          "filter by actor",
+			path:          "?actor=44",
+			role:          roles.ADMIN,
+			expectedCode:  http.StatusOK,
+			expectedCount: 0,
+		},
+		{
+			name:         "invalid date",
+			path:         "?from=yesterday",
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid limit",
+			path:         "?limit=-1",
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "not admin",
+			path:         "",
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/admin/audit"+tc.path, http.MethodGet, nil)
+			setAuthTokenWithRole(r, 1, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				response := &struct {
+					Entries []*models.AuditLog `json:"entries"`
+					Total   int                `json:"total"`
+				}{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(response))
+				assert.Equal(t, tc.expectedCount, len(response.Entries))
+			}
+		})
+	}
+}
+
+func TestServer_HandleAdminAuditExport(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	count := 5
+	for i := 0; i < count; i++ {
+		store.Audit(context.Background()).Create(models.NewTestAuditLog(t))
+	}
+
+	w, r := httpParams("/api/v1/admin/audit/export", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 1, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, count)
+	entry := &models.AuditLog{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), entry))
+	assert.Equal(t, auditAction.SignIn, entry.Action)
+}
+
+func TestServer_HandleAdminAdjustBalance(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		role         string
+		expectedCode int
+	}{
+		{
+			name: "valid add",
+			payload: map[string]interface{}{
+				"user_id": 3,
+				"value":   100,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "valid remove",
+			payload: map[string]interface{}{
+				"user_id": 3,
+				"value":   -50,
+				"comment": "refund",
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "insufficient funds",
+			payload: map[string]interface{}{
+				"user_id": 3,
+				"value":   -500,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "empty value",
+			payload: map[string]interface{}{
+				"user_id": 3,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "not admin",
+			payload: map[string]interface{}{
+				"user_id": 3,
+				"value":   100,
+			},
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/admin/balance/adjust", http.MethodPost, tc.payload)
+			setAuthTokenWithRole(r, 1, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	total, _ := s.Audit(context.Background()).Count(&store.AuditFilter{Action: auditAction.BalanceAdjust})
+	assert.Equal(t, 2, total)
+}
+
+func TestServer_HandleAdminImpersonate(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	user := models.NewTestUser(t)
+	s.User(context.Background()).Create(user)
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		role         string
+		expectedCode int
+	}{
+		{
+			name: "valid",
+			payload: map[string]interface{}{
+				"user_id": user.ID,
+				"minutes": 5,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "too long",
+			payload: map[string]interface{}{
+				"user_id": user.ID,
+				"minutes": 600,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "user not found",
+			payload: map[string]interface{}{
+				"user_id": 555,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "not admin",
+			payload: map[string]interface{}{
+				"user_id": user.ID,
+			},
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/admin/impersonate", http.MethodPost, tc.payload)
+			setAuthTokenWithRole(r, 100, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_ImpersonatedRequest(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	for i := 0; i < 3; i++ {
+		user := models.NewTestUser(t)
+		user.Email = fmt.Sprintf("user%d@gmail.com", i)
+		user.Login = fmt.Sprintf("user%d", i)
+		s.User(context.Background()).Create(user)
+	}
+
+	token, err := jwtHelper.CreateImpersonated(&models.User{
+		ID:   3,
+		Role: roles.USER,
+	}, 100, time.Minute)
+	assert.NoError(t, err)
+
+	w, r := httpParams("/api/v1/notif/update", http.MethodGet, nil)
+	r.Header.Set("Authentication", "Bearer "+token)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, r = httpParams("/api/v1/users/me/password", http.MethodPost, map[string]string{
+		"current_password": "qwerty42",
+		"new_password":     "newpassword1",
+	})
+	r.Header.Set("Authentication", "Bearer "+token)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, r = httpParams("/api/v1/users/me", http.MethodPatch, map[string]string{
+		"email": "other@gmail.com",
+	})
+	r.Header.Set("Authentication", "Bearer "+token)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	entries, _ := s.Audit(context.Background()).Find(&store.AuditFilter{Action: auditAction.ImpersonatedAccess})
+	require.Len(t, entries, 3)
+	assert.Equal(t, 100, entries[0].Actor)
+	assert.Equal(t, models.AuditTarget("user", 3), entries[0].Target)
+
+	user, _ := s.User(context.Background()).FindById(3)
+	user.IsActive = false
+	w, r = httpParams("/api/v1/notif/update", http.MethodGet, nil)
+	r.Header.Set("Authentication", "Bearer "+token)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	missing, _ := jwtHelper.CreateImpersonated(&models.User{ID: 50, Role: roles.USER}, 100, time.Minute)
+	w, r = httpParams("/api/v1/notif/update", http.MethodGet, nil)
+	r.Header.Set("Authentication", "Bearer "+missing)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestServer_HandleAdminUsers(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	for i, email := range []string{"a@gmail.com", "b@gmail.com", "c@yandex.ru"} {
+		user := models.NewTestUser(t)
+		user.Email = email
+		user.Login = fmt.Sprintf("user%d", i)
+		s.User(context.Background()).Create(user)
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		role          string
+		expectedCode  int
+		expectedCount int
+		expectedTotal int
+	}{
+		{
+			name:          "valid",
+			path:          "?sort=email&limit=2",
+			role:          roles.ADMIN,
+			expectedCode:  http.StatusOK,
+			expectedCount: 2,
+			expectedTotal: 3,
+		},
+		{
+			name:          "search",
+			path:          "?q=gmail&active=true",
+			role:          roles.ADMIN,
+			expectedCode:  http.StatusOK,
+			expectedCount: 2,
+			expectedTotal: 2,
+		},
+		{
+			name:         "invalid sort",
+			path:         "?sort=password",
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid cursor",
+			path:         "?cursor=!!!",
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "not admin",
+			path:         "",
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/admin/users"+tc.path, http.MethodGet, nil)
+			setAuthTokenWithRole(r, 100, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				response := &struct {
+					Users []map[string]interface{} `json:"users"`
+					Total int                      `json:"total"`
+				}{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(response))
+				require.Len(t, response.Users, tc.expectedCount)
+				assert.Equal(t, tc.expectedTotal, response.Total)
+				assert.NotContains(t, response.Users[0], "api_token")
+				assert.Equal(t, true, response.Users[0]["is_active"])
+			}
+		})
+	}
+}
+
+func TestServer_HandleAdminPatchUser(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	user := models.NewTestUser(t)
+	s.User(context.Background()).Create(user)
+
+	testCases := []struct {
+		name         string
+		path         string
+		payload      interface{}
+		role         string
+		expectedCode int
+	}{
+		{
+			name: "valid",
+			path: "/1",
+			payload: map[string]interface{}{
+				"user_role": roles.ADMIN,
+				"is_active": false,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "invalid role",
+			path: "/1",
+			payload: map[string]interface{}{
+				"user_role": "ROOT",
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "null status",
+			path: "/1",
+			payload: map[string]interface{}{
+				"is_active": nil,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "not found",
+			path:         "/100",
+			payload:      map[string]interface{}{},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "not admin",
+			path:         "/1",
+			payload:      map[string]interface{}{},
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/admin/users"+tc.path, http.MethodPatch, tc.payload)
+			setAuthTokenWithRole(r, 2, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	updated, _ := s.User(context.Background()).FindById(user.ID)
+	assert.Equal(t, roles.ADMIN, updated.Role)
+	assert.False(t, updated.IsActive)
+
+	entries, _ := s.Audit(context.Background()).Find(&store.AuditFilter{Target: "user:1"})
+	assert.Equal(t, 2, len(entries))
+}
@@ -0,0 +1,189 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketAction"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleTicketAssignment(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, role := range []string{roles.USER, roles.SUPPORT, roles.SUPPORT, roles.ADMIN} {
+		user := models.NewTestUser(t)
+		user.Email = fmt.Sprintf("user%d@gmail.com", i)
+		user.Login = fmt.Sprintf("user%d", i)
+		s.User(ctx).Create(user)
+		user.Role = role
+		s.User(ctx).Update(user)
+	}
+	for i := 0; i < 2; i++ {
+		ticket := models.NewTestTicket(t)
+		ticket.From = 1
+		s.Tickets(ctx).Create(ticket)
+	}
+	s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{TicketId: 2, To: ticketStatus.Closed})
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name         string
+		path         string
+		payload      interface{}
+		userId       int
+		role         string
+		expectedCode int
+	}{
+		{
+			name:         "customer accept",
+			path:         "/1/accept",
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "accept",
+			path:         "/1/accept",
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "accept assigned",
+			path:         "/1/accept",
+			userId:       3,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "unassign by other helper",
+			path:         "/1/unassign",
+			userId:       3,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "assign by support",
+			path:         "/1/assign",
+			payload:      map[string]int{"helper_id": 3},
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "assign to customer",
+			path:         "/1/assign",
+			payload:      map[string]int{"helper_id": 1},
+			userId:       4,
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "reassign",
+			path:         "/1/assign",
+			payload:      map[string]int{"helper_id": 3},
+			userId:       4,
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unassign",
+			path:         "/1/unassign",
+			userId:       3,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "accept closed",
+			path:         "/2/accept",
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "not found",
+			path:         "/5/accept",
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/support/tickets"+tc.path, http.MethodPost, tc.payload)
+			setAuthTokenWithRole(r, tc.userId, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	ticket, _ := s.Tickets(ctx).Find(1)
+	assert.Equal(t, -1, ticket.Helper)
+	assert.Equal(t, ticketStatus.Opened, ticket.Status)
+
+	history, _ := s.TicketHistory(ctx).FindByTicket(1)
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, ticketAction.Assign, history[1].Action)
+	assert.Equal(t, 2, history[1].FromHelper)
+	assert.Equal(t, 3, history[1].ToHelper)
+
+	changes, _ := s.Tickets(ctx).StatusHistory(1)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, ticketStatus.InProcess, changes[0].To)
+	assert.Equal(t, 2, changes[0].Actor)
+	assert.Equal(t, ticketStatus.Opened, changes[1].To)
+	assert.Equal(t, 0, changes[1].Actor)
+
+	customer, _ := s.Notifications(ctx).FindAll(1)
+	assert.Equal(t, 3, len(customer))
+	helper, _ := s.Notifications(ctx).FindAll(3)
+	assert.Equal(t, 1, len(helper))
+
+	w, r := httpParams("/api/v1/support/tickets/1/history", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 2, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServer_HandleTicketAssignment_KeepsStatus(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, role := range []string{roles.USER, roles.SUPPORT, roles.ADMIN} {
+		user := models.NewTestUser(t)
+		user.Email = fmt.Sprintf("user%d@gmail.com", i)
+		user.Login = fmt.Sprintf("user%d", i)
+		s.User(ctx).Create(user)
+		user.Role = role
+		s.User(ctx).Update(user)
+	}
+	ticket := models.NewTestTicket(t)
+	ticket.From = 1
+	s.Tickets(ctx).Create(ticket)
+	s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{TicketId: ticket.ID, To: ticketStatus.Resolved})
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	w, r := httpParams("/api/v1/support/tickets/1/assign", http.MethodPost, map[string]int{"helper_id": 2})
+	setAuthTokenWithRole(r, 3, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, r = httpParams("/api/v1/support/tickets/1/unassign", http.MethodPost, nil)
+	setAuthTokenWithRole(r, 2, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, ticketStatus.Resolved, ticket.Status)
+	changes, _ := s.Tickets(ctx).StatusHistory(ticket.ID)
+	assert.Equal(t, 1, len(changes))
+}
@@ -0,0 +1,163 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleMessageAttachments(t *testing.T) {
+	s := teststore.New()
+	ticket := models.NewTestTicket(t)
+	ticket.From = 1
+	s.Tickets(context.Background()).Create(ticket)
+	s.Tickets(context.Background()).Accept(ticket.ID, &models.User{ID: 2})
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	for _, userId := range []int{1, 1, 2} {
+		w := httptest.NewRecorder()
+		r := uploadRequest(nil, "screenshot.png", pngContent)
+		setAuthTokenWithRole(r, userId, roles.SUPPORT)
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		userId       int
+		role         string
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			payload:      map[string]interface{}{"ticket_id": 1, "message": "Logs", "attachments": []int{1, 2}},
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "already attached",
+			payload:      map[string]interface{}{"ticket_id": 1, "message": "Logs", "attachments": []int{1}},
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "other user file",
+			payload:      map[string]interface{}{"ticket_id": 1, "message": "Logs", "attachments": []int{3}},
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "not existing file",
+			payload:      map[string]interface{}{"ticket_id": 1, "message": "Logs", "attachments": []int{10}},
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "too many",
+			payload:      map[string]interface{}{"ticket_id": 1, "message": "Logs", "attachments": []int{4, 5, 6, 7, 8, 9}},
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "helper without text",
+			payload:      map[string]interface{}{"ticket_id": 1, "attachments": []int{3}},
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/support/message/add", http.MethodPost, tc.payload)
+			setAuthTokenWithRole(r, tc.userId, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	w, r := httpParams("/api/v1/support/messages?id=1", http.MethodGet, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	messages := make([]*models.TicketMessage, 0)
+	json.NewDecoder(w.Body).Decode(&messages)
+	assert.Equal(t, 2, len(messages))
+	assert.Equal(t, 2, len(messages[0].Attachments))
+	assert.Equal(t, "screenshot.png", messages[0].Attachments[0].Filename)
+	assert.Equal(t, 1, len(messages[1].Attachments))
+
+	w, r = httpParams("/api/v1/resources/3/download", http.MethodGet, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, r = httpParams("/api/v1/resources/3/download", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 4, roles.USER)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServer_HandleCreateTicketAttachments(t *testing.T) {
+	s := teststore.New()
+	s.Sections(context.Background()).Create(&models.Section{
+		Name:     "bugs",
+		Priority: ticketPriority.High,
+		Routing:  sectionRouting.RoundRobin,
+	})
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	w := httptest.NewRecorder()
+	r := uploadRequest(nil, "screenshot.png", pngContent)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	payload := map[string]interface{}{
+		"title":       "Crash",
+		"description": "App crashes on start",
+		"section":     "bugs",
+		"attachments": []int{1, 2},
+	}
+	w, r = httpParams("/api/v1/support/ticket/create", http.MethodPost, payload)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	_, err := s.Tickets(context.Background()).Find(1)
+	assert.Error(t, err)
+
+	payload["attachments"] = []int{1}
+	w, r = httpParams("/api/v1/support/ticket/create", http.MethodPost, payload)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, r = httpParams("/api/v1/support/ticket?id=1", http.MethodGet, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	ticket := &models.Ticket{}
+	json.NewDecoder(w.Body).Decode(ticket)
+	assert.Equal(t, 1, len(ticket.Attachments))
+	assert.Equal(t, "screenshot.png", ticket.Attachments[0].Filename)
+	assert.Equal(t, ticketPriority.High, ticket.Priority)
+}
@@ -0,0 +1,206 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Authenticate(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	user := &models.User{
+		ID:   1,
+		Role: roles.USER,
+	}
+	token, _ := jwtHelper.Create(user, 1, "access")
+	refresh, _ := jwtHelper.Create(user, 30, "refresh")
+	expired, _ := jwtHelper.CreateImpersonated(user, 100, -time.Minute)
+
+	testCases := []struct {
+		name              string
+		header            string
+		value             string
+		expectedCode      int
+		expectedChallenge string
+	}{
+		{
+			name:         "authorization bearer",
+			header:       "Authorization",
+			value:        "Bearer " + token,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "case insensitive scheme",
+			header:       "Authorization",
+			value:        "bEaReR " + token,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "legacy header",
+			header:       "Authentication",
+			value:        "Bearer " + token,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:              "no header",
+			expectedCode:      http.StatusUnauthorized,
+			expectedChallenge: `Bearer realm="api"`,
+		},
+		{
+			name:              "wrong scheme",
+			header:            "Authorization",
+			value:             "Basic " + token,
+			expectedCode:      http.StatusUnauthorized,
+			expectedChallenge: `Bearer realm="api", error="invalid_request", error_description="Malformed authorization header"`,
+		},
+		{
+			name:              "invalid token",
+			header:            "Authorization",
+			value:             "Bearer " + token + "123",
+			expectedCode:      http.StatusUnauthorized,
+			expectedChallenge: `Bearer realm="api", error="invalid_token", error_description="The access token is invalid"`,
+		},
+		{
+			name:              "malformed token",
+			header:            "Authorization",
+			value:             "Bearer token",
+			expectedCode:      http.StatusUnauthorized,
+			expectedChallenge: `Bearer realm="api", error="invalid_token", error_description="The access token is invalid"`,
+		},
+		{
+			name:              "refresh token",
+			header:            "Authorization",
+			value:             "Bearer " + refresh,
+			expectedCode:      http.StatusUnauthorized,
+			expectedChallenge: `Bearer realm="api", error="invalid_token", error_description="The access token is invalid"`,
+		},
+		{
+			name:              "expired token",
+			header:            "Authorization",
+			value:             "Bearer " + expired,
+			expectedCode:      http.StatusUnauthorized,
+			expectedChallenge: `Bearer realm="api", error="invalid_token", error_description="The access token expired"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/notif/update", http.MethodGet, nil)
+			if tc.header != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedChallenge, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestServer_InactiveUser(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	user := models.NewTestUser(t)
+	store.User(context.Background()).Create(user)
+	credentials := map[string]string{
+		"login":    user.Login,
+		"password": "qwerty42",
+	}
+
+	w, r := httpParams("/signin", http.MethodPost, credentials)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	tokens := make(map[string]string)
+	json.NewDecoder(w.Body).Decode(&tokens)
+
+	w, r = httpParams("/api/v1/admin/users/1", http.MethodPatch, map[string]interface{}{"is_active": false})
+	setAuthTokenWithRole(r, 2, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	patched := make(map[string]interface{})
+	json.NewDecoder(w.Body).Decode(&patched)
+	assert.NotContains(t, patched, "api_token")
+	assert.Equal(t, false, patched["is_active"])
+
+	w, r = httpParams("/api/v1/notif/update", http.MethodGet, nil)
+	r.Header.Set("Authorization", "Bearer "+tokens["access_token"])
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "revoked")
+
+	token, _ := jwtHelper.Create(user, 1, "access")
+	w, r = httpParams("/api/v1/notif/update", http.MethodGet, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "disabled")
+
+	w, r = httpParams("/signin", http.MethodPost, credentials)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestServer_HandleJWKS(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	w, r := httpParams("/.well-known/jwks.json", http.MethodGet, nil)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	jwks := &jwtHelper.JWKS{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(jwks))
+	assert.NotNil(t, jwks.Keys)
+}
+
+func TestServer_DemotedUser(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	user := models.NewTestUser(t)
+	store.User(context.Background()).Create(user)
+	user.Role = roles.ADMIN
+	access, _ := jwtHelper.Create(user, 1, "access")
+	refresh, _ := jwtHelper.Create(user, 30, "refresh")
+
+	w, r := httpParams("/api/v1/admin/users", http.MethodGet, nil)
+	r.Header.Set("Authorization", "Bearer "+access)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, r = httpParams("/api/v1/admin/users/1", http.MethodPatch, map[string]interface{}{"user_role": roles.USER})
+	setAuthTokenWithRole(r, 2, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, r = httpParams("/api/v1/admin/users", http.MethodGet, nil)
+	r.Header.Set("Authorization", "Bearer "+access)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w, r = httpParams("/checkAccess", http.MethodGet, nil)
+	r.Header.Set("Authorization", "Bearer "+refresh)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	tokens := make(map[string]string)
+	json.NewDecoder(w.Body).Decode(&tokens)
+	claims, err := jwtHelper.Validate(tokens["access_token"])
+	assert.NoError(t, err)
+	assert.Equal(t, roles.USER, claims.Access)
+}
@@ -0,0 +1,241 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleCannedResponses(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name         string
+		path         string
+		method       string
+		payload      interface{}
+		userId       int
+		role         string
+		expectedCode int
+	}{
+		{
+			name:   "create personal",
+			path:   "/api/v1/support/canned",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"title": "Greeting",
+				"body":  "Hi {{user.login}}",
+			},
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "create shared",
+			path:   "/api/v1/support/canned",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"title":  "Resolved",
+				"body":   "Ticket #{{ticket.id}} is resolved",
+				"status": ticketStatus.Resolved,
+				"shared": true,
+			},
+			userId:       9,
+			role:         roles.ADMIN,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "shared by helper",
+			path:   "/api/v1/support/canned",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"title":  "Bye",
+				"body":   "Bye",
+				"shared": true,
+			},
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "not valid template",
+			path:   "/api/v1/support/canned",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"title": "Bye",
+				"body":  "Bye {{customer.name}}",
+			},
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "customer",
+			path:   "/api/v1/support/canned",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"title": "Bye",
+				"body":  "Bye",
+			},
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "update personal",
+			path:   "/api/v1/support/canned/1",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"body": "Hello {{user.login}}",
+			},
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "update personal of other helper",
+			path:   "/api/v1/support/canned/1",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"body": "Bye",
+			},
+			userId:       3,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "update shared by helper",
+			path:   "/api/v1/support/canned/2",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"body": "Bye",
+			},
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "delete shared by helper",
+			path:         "/api/v1/support/canned/2",
+			method:       http.MethodDelete,
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams(tc.path, tc.method, tc.payload)
+			setAuthTokenWithRole(r, tc.userId, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	list := func(userId int) []*models.CannedResponse {
+		w, r := httpParams("/api/v1/support/canned", http.MethodGet, nil)
+		setAuthTokenWithRole(r, userId, roles.SUPPORT)
+		h.ServeHTTP(w, r)
+		responses := make([]*models.CannedResponse, 0)
+		json.NewDecoder(w.Body).Decode(&responses)
+		return responses
+	}
+	responses := list(2)
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "Hello {{user.login}}", responses[0].Body)
+	assert.Len(t, list(3), 1)
+
+	w, r := httpParams("/api/v1/support/canned/1", http.MethodDelete, nil)
+	setAuthTokenWithRole(r, 2, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, list(2), 1)
+}
+
+func TestServer_HandleMacro(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, role := range []string{roles.USER, roles.SUPPORT, roles.SUPPORT} {
+		user := models.NewTestUser(t)
+		user.Login, user.Email = user.Login+string(rune('a'+i)), string(rune('a'+i))+user.Email
+		assert.NoError(t, s.User(ctx).Create(user))
+		user.Role = role
+	}
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	ticket := models.NewTestTicket(t)
+	ticket.From = 1
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	assert.NoError(t, s.CannedResponses(ctx).Create(&models.CannedResponse{
+		Title:  "Need details",
+		Body:   "Hi {{user.login}}, please send logs for #{{ticket.id}}. {{helper.login}}",
+		Status: ticketStatus.WaitingForCustomer,
+	}))
+	assert.NoError(t, s.CannedResponses(ctx).Create(&models.CannedResponse{
+		Owner: 3,
+		Title: "Private",
+		Body:  "Private",
+	}))
+
+	send := func(path string, payload interface{}, userId int) (int, map[string]interface{}) {
+		w, r := httpParams(path, http.MethodPost, payload)
+		setAuthTokenWithRole(r, userId, roles.SUPPORT)
+		h.ServeHTTP(w, r)
+		body := make(map[string]interface{})
+		json.NewDecoder(w.Body).Decode(&body)
+		return w.Code, body
+	}
+	payload := map[string]interface{}{"ticket_id": ticket.ID}
+
+	code, body := send("/api/v1/support/canned/1/render", payload, 2)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Hi Usernmaea, please send logs for #1. Usernmaeb", body["message"])
+
+	// Helper must take ticket before changing its status
+	code, _ = send("/api/v1/support/canned/1/apply", payload, 2)
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = send("/api/v1/support/canned/2/apply", payload, 2)
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = send("/api/v1/support/canned/1/apply", map[string]interface{}{"ticket_id": 5}, 2)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	assert.NoError(t, s.Tickets(ctx).Accept(ticket.ID, &models.User{ID: 2}))
+	ticket.Status = ticketStatus.InProcess
+	code, body = send("/api/v1/support/canned/1/apply", payload, 2)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, body["change"])
+
+	ticket, _ = s.Tickets(ctx).Find(ticket.ID)
+	assert.Equal(t, ticketStatus.WaitingForCustomer, ticket.Status)
+	messages, _ := s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, uint(2), messages[0].Who)
+	notes, _ := s.Notifications(ctx).FindAll(1)
+	assert.Len(t, notes, 1)
+
+	// Ticket already waits for customer, only message is posted
+	code, body = send("/api/v1/support/canned/1/apply", payload, 2)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, body["change"])
+	messages, _ = s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.Len(t, messages, 2)
+	changes, _ := s.Tickets(ctx).StatusHistory(ticket.ID)
+	assert.Len(t, changes, 1)
+
+	// Render is a preview and is not counted
+	response, _ := s.CannedResponses(ctx).Find(1)
+	assert.Equal(t, 2, response.UsageCount)
+}
@@ -0,0 +1,157 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/contactKind"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/inhumanLightBackend/app/utils/notifications"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChannel struct {
+	messages []string
+}
+
+func (c *fakeChannel) Send(ctx context.Context, contact *models.Contact, message string) error {
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+func TestServer_HandleContacts(t *testing.T) {
+	s := teststore.New()
+	channel := &fakeChannel{}
+	dispatcher := notifications.NewDispatcher(s, logrus.New())
+	dispatcher.Register(contactKind.Telegram, channel)
+	h := handlers.New(s, logrus.New())
+	h.SetDispatcher(dispatcher)
+	h.SetupRoutes()
+
+	w, r := httpParams("/api/v1/users/me/contacts", http.MethodPost, map[string]string{
+		"kind":  contactKind.Telegram,
+		"value": "@username",
+	})
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, r = httpParams("/api/v1/users/me/contacts", http.MethodPost, map[string]string{
+		"kind":  contactKind.Telegram,
+		"value": "708015155",
+	})
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, len(channel.messages))
+
+	contact, _ := s.Contacts(context.Background()).Find(1)
+	testCases := []struct {
+		name         string
+		path         string
+		method       string
+		payload      interface{}
+		userId       int
+		expectedCode int
+	}{
+		{
+			name:         "enable not verified",
+			path:         "/1",
+			method:       http.MethodPatch,
+			payload:      map[string]bool{"enabled": true},
+			userId:       1,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "wrong code",
+			path:         "/1/verify",
+			method:       http.MethodPost,
+			payload:      map[string]string{"code": "code"},
+			userId:       1,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "other user contact",
+			path:         "/1/verify",
+			method:       http.MethodPost,
+			payload:      map[string]string{"code": contact.VerificationCode},
+			userId:       2,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "valid code",
+			path:         "/1/verify",
+			method:       http.MethodPost,
+			payload:      map[string]string{"code": contact.VerificationCode},
+			userId:       1,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "disable",
+			path:         "/1",
+			method:       http.MethodPatch,
+			payload:      map[string]bool{"enabled": false},
+			userId:       1,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "resend to verified",
+			path:         "/1/resend",
+			method:       http.MethodPost,
+			userId:       1,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/users/me/contacts"+tc.path, tc.method, tc.payload)
+			setAuthTokenWithRole(r, tc.userId, "USER")
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	w, r = httpParams("/api/v1/users/me/contacts", http.MethodGet, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	contacts := make([]*models.Contact, 0)
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&contacts))
+	assert.Equal(t, 1, len(contacts))
+	assert.True(t, contacts[0].Verified)
+	assert.False(t, contacts[0].Enabled)
+}
+
+func TestServer_HandleContacts_VerifyAttempts(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	contact := models.NewTestContact(t)
+	contact.UserId = 1
+	assert.NoError(t, s.Contacts(context.Background()).Create(contact))
+	code := contact.VerificationCode
+
+	verify := func(code string) int {
+		w, r := httpParams("/api/v1/users/me/contacts/1/verify", http.MethodPost, map[string]string{"code": code})
+		setAuthToken(r)
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	for i := 0; i < models.MaxCodeAttempts; i++ {
+		assert.Equal(t, http.StatusBadRequest, verify("wrong"))
+	}
+	// Code is invalid after too many attempts
+	assert.Equal(t, http.StatusBadRequest, verify(code))
+
+	w, r := httpParams("/api/v1/users/me/contacts/1/resend", http.MethodPost, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusOK, verify(contact.VerificationCode))
+}
@@ -0,0 +1,197 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleIntrospect(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetServiceClients(map[string]string{"gateway": "secret"})
+	h.SetupRoutes()
+
+	user := models.NewTestUser(t)
+	store.User(context.Background()).Create(user)
+
+	session := models.NewTestSession(t)
+	session.UserId = user.ID
+	store.Sessions(context.Background()).Create(session)
+	token, _ := jwtHelper.CreateForSession(user, session.ID, 1, "access")
+	refreshToken, _ := jwtHelper.CreateForSession(user, session.ID, 30, "refresh")
+
+	revoked := models.NewTestSession(t)
+	revoked.UserId = user.ID
+	store.Sessions(context.Background()).Create(revoked)
+	store.Sessions(context.Background()).Revoke(revoked.ID)
+	revokedToken, _ := jwtHelper.CreateForSession(user, revoked.ID, 1, "access")
+
+	disabled := models.NewTestInactiveUser(t)
+	disabled.Login = "disabled"
+	disabled.Email = "disabled@gmail.com"
+	store.User(context.Background()).Create(disabled)
+	disabled.IsActive = false
+	disabled.Token = "disabledtoken"
+
+	testCases := []struct {
+		name           string
+		client         string
+		secret         string
+		token          string
+		hint           string
+		expectedCode   int
+		expectedActive bool
+		expectedType   string
+	}{
+		{
+			name:           "valid jwt",
+			client:         "gateway",
+			secret:         "secret",
+			token:          token,
+			expectedCode:   http.StatusOK,
+			expectedActive: true,
+			expectedType:   "access",
+		},
+		{
+			name:           "valid api token",
+			client:         "gateway",
+			secret:         "secret",
+			token:          user.Token,
+			hint:           "api_token",
+			expectedCode:   http.StatusOK,
+			expectedActive: true,
+			expectedType:   "api_token",
+		},
+		{
+			name:           "jwt with api token hint",
+			client:         "gateway",
+			secret:         "secret",
+			token:          token,
+			hint:           "api_token",
+			expectedCode:   http.StatusOK,
+			expectedActive: true,
+			expectedType:   "access",
+		},
+		{
+			name:           "api token without hint",
+			client:         "gateway",
+			secret:         "secret",
+			token:          user.Token,
+			expectedCode:   http.StatusOK,
+			expectedActive: true,
+			expectedType:   "api_token",
+		},
+		{
+			name:           "refresh token",
+			client:         "gateway",
+			secret:         "secret",
+			token:          refreshToken,
+			expectedCode:   http.StatusOK,
+			expectedActive: false,
+		},
+		{
+			name:           "revoked session",
+			client:         "gateway",
+			secret:         "secret",
+			token:          revokedToken,
+			expectedCode:   http.StatusOK,
+			expectedActive: false,
+		},
+		{
+			name:           "inactive user api token",
+			client:         "gateway",
+			secret:         "secret",
+			token:          disabled.Token,
+			hint:           "api_token",
+			expectedCode:   http.StatusOK,
+			expectedActive: false,
+		},
+		{
+			name:           "unknown token",
+			client:         "gateway",
+			secret:         "secret",
+			token:          "unknown",
+			expectedCode:   http.StatusOK,
+			expectedActive: false,
+		},
+		{
+			name:         "empty token",
+			client:       "gateway",
+			secret:       "secret",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "wrong secret",
+			client:       "gateway",
+			secret:       "wrong",
+			token:        token,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "without credentials",
+			token:        token,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Set("token", tc.token)
+			form.Set("token_type_hint", tc.hint)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/oauth/introspect", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.client != "" {
+				r.SetBasicAuth(tc.client, tc.secret)
+			}
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				response := map[string]interface{}{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tc.expectedActive, response["active"])
+				if !tc.expectedActive {
+					assert.Equal(t, 1, len(response))
+				}
+				if tc.expectedType != "" {
+					assert.Equal(t, tc.expectedType, response["token_type"])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_RevokedSession(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	session := models.NewTestSession(t)
+	store.Sessions(context.Background()).Create(session)
+	token, _ := jwtHelper.CreateForSession(&models.User{ID: session.UserId}, session.ID, 1, "access")
+
+	w, r := httpParams("/api/v1/notif/update", http.MethodGet, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	store.Sessions(context.Background()).Revoke(session.ID)
+	w, r = httpParams("/api/v1/notif/update", http.MethodGet, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "revoked")
+}
@@ -0,0 +1,108 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_TicketAccessPolicy(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		ticket := models.NewTestTicket(t)
+		ticket.From = 1
+		s.Tickets(ctx).Create(ticket)
+	}
+	s.Tickets(ctx).Accept(2, &models.User{ID: 2})
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	actors := []struct {
+		name string
+		id   int
+		role string
+		// Visibility of not assigned and assigned ticket
+		visible [2]bool
+	}{
+		{name: "owner", id: 1, role: roles.USER, visible: [2]bool{true, true}},
+		{name: "other customer", id: 6, role: roles.USER, visible: [2]bool{false, false}},
+		{name: "assigned helper", id: 2, role: roles.SUPPORT, visible: [2]bool{true, true}},
+		{name: "other helper", id: 3, role: roles.SUPPORT, visible: [2]bool{true, false}},
+		{name: "admin", id: 4, role: roles.ADMIN, visible: [2]bool{true, true}},
+	}
+	endpoints := []struct {
+		name    string
+		method  string
+		path    string
+		payload func(ticketId int) interface{}
+	}{
+		{name: "ticket", method: http.MethodGet, path: "/api/v1/support/ticket?id=%d"},
+		{name: "messages", method: http.MethodGet, path: "/api/v1/support/messages?id=%d"},
+		{name: "statuses", method: http.MethodGet, path: "/api/v1/support/tickets/%d/statuses"},
+		{
+			name:   "add message",
+			method: http.MethodPost,
+			path:   "/api/v1/support/message/add",
+			payload: func(ticketId int) interface{} {
+				return map[string]interface{}{"message": "Hello", "ticket_id": ticketId}
+			},
+		},
+	}
+
+	for _, actor := range actors {
+		for _, endpoint := range endpoints {
+			for i, ticketId := range []int{1, 2} {
+				name := fmt.Sprintf("%s %s ticket %d", actor.name, endpoint.name, ticketId)
+				t.Run(name, func(t *testing.T) {
+					path := endpoint.path
+					if endpoint.payload == nil {
+						path = fmt.Sprintf(endpoint.path, ticketId)
+					}
+					var payload interface{}
+					if endpoint.payload != nil {
+						payload = endpoint.payload(ticketId)
+					}
+
+					w, r := httpParams(path, endpoint.method, payload)
+					setAuthTokenWithRole(r, actor.id, actor.role)
+					h.ServeHTTP(w, r)
+					if actor.visible[i] {
+						assert.Equal(t, http.StatusOK, w.Code)
+					} else {
+						assert.Equal(t, http.StatusNotFound, w.Code)
+					}
+				})
+			}
+		}
+
+		t.Run(actor.name+" queue", func(t *testing.T) {
+			w, r := httpParams("/api/v1/support/queue", http.MethodGet, nil)
+			setAuthTokenWithRole(r, actor.id, actor.role)
+			h.ServeHTTP(w, r)
+			if actor.role == roles.USER {
+				assert.Equal(t, http.StatusUnauthorized, w.Code)
+				return
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+			body := &struct {
+				Tickets []*models.Ticket `json:"tickets"`
+			}{}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(body))
+			listed := [2]bool{}
+			for _, ticket := range body.Tickets {
+				listed[ticket.ID-1] = true
+			}
+			assert.Equal(t, actor.visible, listed)
+		})
+	}
+}
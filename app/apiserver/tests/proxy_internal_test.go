@@ -0,0 +1,32 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteAddr(t *testing.T) {
+	defer middleware.SetTrustedProxies(nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.5:4321"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+	r.Header.Set("X-Real-IP", "3.3.3.3")
+
+	// Headers of untrusted client are ignored
+	assert.Equal(t, "10.0.0.5", middleware.RemoteAddr(r))
+
+	assert.NoError(t, middleware.SetTrustedProxies([]string{"10.0.0.0/8", "2.2.2.2"}))
+	assert.Equal(t, "1.1.1.1", middleware.RemoteAddr(r))
+	r.Header.Del("X-Forwarded-For")
+	assert.Equal(t, "3.3.3.3", middleware.RemoteAddr(r))
+
+	r.RemoteAddr = "[::1]:4321"
+	assert.Equal(t, "::1", middleware.RemoteAddr(r))
+
+	assert.Error(t, middleware.SetTrustedProxies([]string{"proxy"}))
+}
@@ -0,0 +1,129 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleTicketQueue(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, section := range []string{"billing", "api", "billing"} {
+		ticket := models.NewTestTicket(t)
+		ticket.Section = section
+		ticket.From = uint(10 + i)
+		s.Tickets(ctx).Create(ticket)
+	}
+	s.Tickets(ctx).Accept(2, &models.User{ID: 2})
+	s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{TicketId: 3, To: ticketStatus.Closed})
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		expectedCode  int
+		expectedCount int
+	}{
+		{
+			name:          "all tickets",
+			query:         "",
+			role:          roles.SUPPORT,
+			expectedCode:  http.StatusOK,
+			expectedCount: 3,
+		},
+		{
+			name:          "by status and section",
+			query:         "?status=opened,closed&section=billing",
+			role:          roles.ADMIN,
+			expectedCode:  http.StatusOK,
+			expectedCount: 2,
+		},
+		{
+			name:          "assigned to me",
+			query:         "?helper=me",
+			role:          roles.SUPPORT,
+			expectedCode:  http.StatusOK,
+			expectedCount: 1,
+		},
+		{
+			name:          "not assigned",
+			query:         "?helper=none&sort=last_activity&order=desc",
+			role:          roles.SUPPORT,
+			expectedCode:  http.StatusOK,
+			expectedCount: 2,
+		},
+		{
+			name:         "other helper",
+			query:        "?helper=5",
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:          "other helper by admin",
+			query:         "?helper=5",
+			role:          roles.ADMIN,
+			expectedCode:  http.StatusOK,
+			expectedCount: 0,
+		},
+		{
+			name:         "unknown status",
+			query:        "?status=lost",
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid cursor",
+			query:        "?cursor=???",
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "customer",
+			query:        "",
+			role:         roles.USER,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/support/queue"+tc.query, http.MethodGet, nil)
+			setAuthTokenWithRole(r, 2, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				body := &struct {
+					Tickets []*models.Ticket `json:"tickets"`
+					Counts  map[string]int   `json:"counts"`
+				}{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(body))
+				assert.Equal(t, tc.expectedCount, len(body.Tickets))
+				assert.Contains(t, body.Counts, ticketStatus.InProcess)
+			}
+		})
+	}
+
+	w, r := httpParams("/api/v1/support/queue?limit=2", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 2, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	body := map[string]interface{}{}
+	json.NewDecoder(w.Body).Decode(&body)
+	w, r = httpParams("/api/v1/support/queue?limit=2&cursor="+body["next_cursor"].(string), http.MethodGet, nil)
+	setAuthTokenWithRole(r, 2, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	json.NewDecoder(w.Body).Decode(&body)
+	assert.Equal(t, 1, len(body["tickets"].([]interface{})))
+	assert.Equal(t, "", body["next_cursor"])
+}
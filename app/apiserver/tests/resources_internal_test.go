@@ -0,0 +1,233 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/storage"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var pngContent = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 24)...)
+
+func uploadRequest(fields map[string]string, filename string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	for k, v := range fields {
+		form.WriteField(k, v)
+	}
+	if filename != "" {
+		part, _ := form.CreateFormFile("file", filename)
+		part.Write(content)
+	}
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/resources", body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	return r
+}
+
+func TestServer_HandleUploadResource(t *testing.T) {
+	s := teststore.New()
+	ticket := models.NewTestTicket(t)
+	ticket.From = 3
+	s.Tickets(context.Background()).Create(ticket)
+	h := handlers.New(s, logrus.New())
+	h.SetStorage(storage.NewMemory(), 64)
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name         string
+		fields       map[string]string
+		filename     string
+		content      []byte
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			filename:     "../../screenshot.png",
+			content:      pngContent,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "no file",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "too large",
+			filename:     "big.txt",
+			content:      bytes.Repeat([]byte("a"), 65),
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "not allowed type",
+			filename:     "photo.png",
+			content:      []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff\x00\x00"),
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "other user ticket",
+			fields:       map[string]string{"ticket_id": "1"},
+			filename:     "log.txt",
+			content:      []byte("log"),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "invalid ticket",
+			fields:       map[string]string{"ticket_id": "first"},
+			filename:     "log.txt",
+			content:      []byte("log"),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := uploadRequest(tc.fields, tc.filename, tc.content)
+			setAuthToken(r)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	res, err := s.Resources(context.Background()).Find(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "screenshot.png", res.Filename)
+	assert.Equal(t, "image/png", res.MimeType)
+	assert.Equal(t, int64(len(pngContent)), res.Size)
+}
+
+func TestServer_HandleDownloadResource(t *testing.T) {
+	s := teststore.New()
+	ticket := models.NewTestTicket(t)
+	ticket.From = 1
+	s.Tickets(context.Background()).Create(ticket)
+	s.Tickets(context.Background()).Accept(ticket.ID, &models.User{ID: 2})
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	for _, fields := range []map[string]string{{"ticket_id": "1"}, nil} {
+		w := httptest.NewRecorder()
+		r := uploadRequest(fields, "screenshot.png", pngContent)
+		setAuthToken(r)
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	testCases := []struct {
+		name         string
+		path         string
+		userId       int
+		role         string
+		expectedCode int
+	}{
+		{
+			name:         "owner",
+			path:         "/api/v1/resources/2/download",
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "ticket helper",
+			path:         "/api/v1/resources/1/download",
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "helper without ticket",
+			path:         "/api/v1/resources/2/download",
+			userId:       2,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "not assigned support",
+			path:         "/api/v1/resources/1/download",
+			userId:       5,
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "stranger",
+			path:         "/api/v1/resources/1",
+			userId:       3,
+			role:         roles.USER,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "admin on ticket",
+			path:         "/api/v1/resources/1/download",
+			userId:       4,
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "not found",
+			path:         "/api/v1/resources/3/download",
+			userId:       1,
+			role:         roles.USER,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams(tc.path, http.MethodGet, nil)
+			setAuthTokenWithRole(r, tc.userId, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, pngContent, w.Body.Bytes())
+				assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+				assert.Equal(t, "attachment; filename=screenshot.png", w.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+
+	unassigned := models.NewTestTicket(t)
+	unassigned.From = 1
+	s.Tickets(context.Background()).Create(unassigned)
+	w := httptest.NewRecorder()
+	r := uploadRequest(map[string]string{"ticket_id": "2"}, "screenshot.png", pngContent)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Not assigned ticket is open to all helpers, so are its files
+	w, r = httpParams("/api/v1/resources/3/download", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 5, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, r = httpParams("/api/v1/resources/1", http.MethodDelete, nil)
+	setAuthTokenWithRole(r, 2, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, r = httpParams("/api/v1/resources/1", http.MethodDelete, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, r = httpParams("/api/v1/resources/2", http.MethodDelete, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, r = httpParams("/api/v1/resources/2/download", http.MethodGet, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,95 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleSearch(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	// Ticket 1 of customer 1 assigned to helper 5, ticket 2 of customer 2
+	// assigned to helper 6, ticket 3 of customer 2 not assigned
+	for i, helper := range []int{5, 6, -1} {
+		ticket := models.NewTestTicket(t)
+		ticket.From = uint((i+1)/2 + 1)
+		ticket.Title = "Printer is broken"
+		assert.NoError(t, s.Tickets(ctx).Create(ticket))
+		if helper != -1 {
+			assert.NoError(t, s.Tickets(ctx).Accept(ticket.ID, &models.User{ID: helper}))
+		}
+	}
+	message := models.NewTestTicketMessage(t)
+	message.Message = "Restart the PRINTER and check the broken cable"
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+
+	search := func(query url.Values, userId int, role string) (int, []*models.SearchHit) {
+		w, r := httpParams("/api/v1/support/search?"+query.Encode(), http.MethodGet, nil)
+		setAuthTokenWithRole(r, userId, role)
+		h.ServeHTTP(w, r)
+
+		response := struct {
+			Results []*models.SearchHit `json:"results"`
+		}{}
+		json.NewDecoder(w.Body).Decode(&response)
+		return w.Code, response.Results
+	}
+	ticketIds := func(hits []*models.SearchHit) map[uint]bool {
+		ids := make(map[uint]bool)
+		for _, hit := range hits {
+			ids[hit.TicketId] = true
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name         string
+		query        url.Values
+		userId       int
+		role         string
+		expectedCode int
+		tickets      map[uint]bool
+	}{
+		{name: "customer", query: url.Values{"q": {"broken printer"}}, userId: 1, role: roles.USER, expectedCode: http.StatusOK, tickets: map[uint]bool{1: true}},
+		{name: "other customer", query: url.Values{"q": {"broken printer"}}, userId: 2, role: roles.USER, expectedCode: http.StatusOK, tickets: map[uint]bool{2: true, 3: true}},
+		{name: "helper", query: url.Values{"q": {"printer"}}, userId: 5, role: roles.SUPPORT, expectedCode: http.StatusOK, tickets: map[uint]bool{1: true, 3: true}},
+		{name: "admin", query: url.Values{"q": {"printer"}}, userId: 7, role: roles.ADMIN, expectedCode: http.StatusOK, tickets: map[uint]bool{1: true, 2: true, 3: true}},
+		{name: "not all words", query: url.Values{"q": {"printer scanner"}}, userId: 7, role: roles.ADMIN, expectedCode: http.StatusOK, tickets: map[uint]bool{}},
+		{name: "empty query", query: url.Values{"q": {"  "}}, userId: 7, role: roles.ADMIN, expectedCode: http.StatusBadRequest},
+		{name: "not valid limit", query: url.Values{"q": {"printer"}, "limit": {"-1"}}, userId: 7, role: roles.ADMIN, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, hits := search(tc.query, tc.userId, tc.role)
+			assert.Equal(t, tc.expectedCode, code)
+			if tc.tickets != nil {
+				assert.Equal(t, tc.tickets, ticketIds(hits))
+			}
+		})
+	}
+
+	// Message matches twice, so it goes before its ticket
+	_, hits := search(url.Values{"q": {"broken printer"}}, 1, roles.USER)
+	assert.Len(t, hits, 2)
+	assert.Equal(t, message.ID, hits[0].MessageId)
+	assert.Equal(t, "Restart the <mark>PRINTER</mark> and check the <mark>broken</mark> cable", hits[0].Snippet)
+	assert.Equal(t, uint(0), hits[1].MessageId)
+	assert.Equal(t, "Printer is broken", hits[1].Title)
+
+	_, hits = search(url.Values{"q": {"printer"}, "limit": {"1"}}, 7, roles.ADMIN)
+	assert.Len(t, hits, 1)
+}
@@ -0,0 +1,234 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/models/ticketAction"
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleSections(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, role := range []string{roles.USER, roles.SUPPORT} {
+		user := models.NewTestUser(t)
+		user.Login, user.Email = user.Login+string(rune('a'+i)), string(rune('a'+i))+user.Email
+		assert.NoError(t, s.User(ctx).Create(user))
+		user.Role = role
+	}
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name         string
+		path         string
+		method       string
+		payload      interface{}
+		role         string
+		expectedCode int
+	}{
+		{
+			name:   "create",
+			path:   "/api/v1/admin/sections",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"name":        "Billing",
+				"description": "Payments and refunds",
+				"helpers":     []int{2, 2},
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "duplicate name",
+			path:   "/api/v1/admin/sections",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"name": "Billing",
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "customer in pool",
+			path:   "/api/v1/admin/sections",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"name":    "Bugs",
+				"helpers": []int{1},
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown priority",
+			path:   "/api/v1/admin/sections",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"name":     "Bugs",
+				"priority": "asap",
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "empty name",
+			path:   "/api/v1/admin/sections",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"description": "Bugs",
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "not admin",
+			path:   "/api/v1/admin/sections",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"name": "Bugs",
+			},
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "update",
+			path:   "/api/v1/admin/sections/1",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"name":     "Payments",
+				"priority": ticketPriority.High,
+				"routing":  sectionRouting.LeastLoaded,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "update not existing",
+			path:   "/api/v1/admin/sections/10",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"priority": ticketPriority.High,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "update unknown routing",
+			path:   "/api/v1/admin/sections/1",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"routing": "random",
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams(tc.path, tc.method, tc.payload)
+			setAuthTokenWithRole(r, 100, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	section, err := s.Sections(ctx).Find(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Billing", section.Name)
+	assert.Equal(t, "Payments and refunds", section.Description)
+	assert.Equal(t, ticketPriority.High, section.Priority)
+	assert.Equal(t, sectionRouting.LeastLoaded, section.Routing)
+	assert.Equal(t, []int{2}, section.Helpers)
+
+	sections := make([]*models.Section, 0)
+	w, r := httpParams("/api/v1/support/sections", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 100, roles.USER)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.NewDecoder(w.Body).Decode(&sections)
+	assert.Len(t, sections, 1)
+	assert.Empty(t, sections[0].Helpers)
+
+	w, r = httpParams("/api/v1/admin/sections/1", http.MethodDelete, nil)
+	setAuthTokenWithRole(r, 100, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, err = s.Sections(ctx).Find(1)
+	assert.Error(t, err)
+}
+
+func TestServer_TicketRouting(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, role := range []string{roles.USER, roles.SUPPORT, roles.SUPPORT, roles.SUPPORT} {
+		user := models.NewTestUser(t)
+		user.Login, user.Email = user.Login+string(rune('a'+i)), string(rune('a'+i))+user.Email
+		assert.NoError(t, s.User(ctx).Create(user))
+		user.Role = role
+	}
+	inactive, _ := s.User(ctx).FindById(4)
+	inactive.IsActive = false
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	section := models.NewTestSection(t)
+	section.Helpers = []int{2, 3, 4}
+	assert.NoError(t, s.Sections(ctx).Create(section))
+	create := func() *models.Ticket {
+		w, r := httpParams("/api/v1/support/ticket/create", http.MethodPost, map[string]string{
+			"title":       "Crash",
+			"description": "App crashes on start",
+			"section":     section.Name,
+		})
+		setAuthTokenWithRole(r, 1, roles.USER)
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		tickets, _ := s.Tickets(ctx).FindAll(1)
+		latest := tickets[0]
+		for _, ticket := range tickets {
+			if ticket.ID > latest.ID {
+				latest = ticket
+			}
+		}
+		return latest
+	}
+
+	// Inactive helper is skipped in turn
+	assert.Equal(t, 2, create().Helper)
+	assert.Equal(t, 3, create().Helper)
+	assert.Equal(t, 2, create().Helper)
+
+	history, _ := s.TicketHistory(ctx).FindByTicket(1)
+	assert.Len(t, history, 1)
+	assert.Equal(t, ticketAction.AutoAssign, history[0].Action)
+	assert.Equal(t, 0, history[0].Actor)
+	notes, _ := s.Notifications(ctx).FindAll(2)
+	assert.Len(t, notes, 2)
+	notes, _ = s.Notifications(ctx).FindAll(3)
+	assert.Len(t, notes, 1)
+
+	// Helper 2 has two tickets, helper 3 has one
+	section.Routing = sectionRouting.LeastLoaded
+	assert.Equal(t, 3, create().Helper)
+	assert.Equal(t, 2, create().Helper)
+
+	// Nobody available, ticket waits in the queue and all helpers are notified
+	section.Helpers = []int{4}
+	ticket := create()
+	assert.Equal(t, -1, ticket.Helper)
+	notes, _ = s.Notifications(ctx).FindAll(3)
+	assert.Len(t, notes, 3)
+}
@@ -1,6 +1,7 @@
 package apiserver
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -13,6 +14,7 @@ import (
 	"github.com/inhumanLightBackend/app/apiserver/handlers"
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
 	"github.com/inhumanLightBackend/app/store/teststore"
 	"github.com/inhumanLightBackend/app/utils/jwtHelper"
 	"github.com/sirupsen/logrus"
@@ -28,6 +30,14 @@ var (
 
 		r.Header.Set("Authentication", fmt.Sprintf("%s %s", "Bearer", jwt))
 	}
+	setAuthTokenWithRole = func(r *http.Request, id int, role string) {
+		jwt, _ := jwtHelper.Create(&models.User{
+			ID:   id,
+			Role: role,
+		}, 1, "access")
+
+		r.Header.Set("Authentication", fmt.Sprintf("%s %s", "Bearer", jwt))
+	}
 	httpParams = func(path string, method string, payload interface{}) (*httptest.ResponseRecorder, *http.Request) {
 		rec := httptest.NewRecorder()
 		bPayload := &bytes.Buffer{}
@@ -60,12 +70,43 @@ func TestServer_HandleSignUp(t *testing.T) {
 		{
 			name: "valid",
 			in: newRequest("/signup", http.MethodPost, map[string]string{
+				"username": "user123",
 				"email":    "user123@gmail.com",
-				"password": "123456",
+				"password": "qwerty42",
 			}),
 			out:          httptest.NewRecorder(),
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name: "username taken",
+			in: newRequest("/signup", http.MethodPost, map[string]string{
+				"username": "USER123",
+				"email":    "user456@gmail.com",
+				"password": "qwerty42",
+			}),
+			out:          httptest.NewRecorder(),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "invalid username",
+			in: newRequest("/signup", http.MethodPost, map[string]string{
+				"username": "user 123",
+				"email":    "user789@gmail.com",
+				"password": "qwerty42",
+			}),
+			out:          httptest.NewRecorder(),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "weak password",
+			in: newRequest("/signup", http.MethodPost, map[string]string{
+				"username": "user789",
+				"email":    "user789@gmail.com",
+				"password": "user789pass",
+			}),
+			out:          httptest.NewRecorder(),
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:         "invalid payload",
 			in:           newRequest("/signup", http.MethodPost, "invalid"),
@@ -88,11 +129,58 @@ func TestServer_HandleSignUp(t *testing.T) {
 			assert.Equal(t, tc.out.Code, tc.expectedCode)
 		})
 	}
+
+	balance, err := store.Balance(context.Background()).LookForBalance(1)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0), balance.BalanceNow)
+}
+
+func TestServer_HandleCheckUsername(t *testing.T) {
+	store := teststore.New()
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+	store.User(context.Background()).Create(models.NewTestUser(t))
+
+	testCases := []struct {
+		name              string
+		username          string
+		expectedCode      int
+		expectedAvailable bool
+	}{
+		{
+			name:              "available",
+			username:          "new_user",
+			expectedCode:      http.StatusOK,
+			expectedAvailable: true,
+		},
+		{
+			name:         "taken in other case",
+			username:     "usernmae",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid format",
+			username:     "a",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/signup/check?username="+tc.username, http.MethodGet, nil)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				response := map[string]interface{}{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tc.expectedAvailable, response["available"])
+			}
+		})
+	}
 }
 
 func TestServer_HandleSignIn(t *testing.T) {
 	email := "user123@gmail.com"
-	password := "123456"
+	password := "qwerty42"
 
 	store := teststore.New()
 	h := handlers.New(store, logrus.New())
@@ -118,6 +206,24 @@ func TestServer_HandleSignIn(t *testing.T) {
 			out:          httptest.NewRecorder(),
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "valid by username",
+			in: newRequest("/signin", http.MethodGet, map[string]string{
+				"login":    "USERNMAE",
+				"password": password,
+			}),
+			out:          httptest.NewRecorder(),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "valid by email in login",
+			in: newRequest("/signin", http.MethodGet, map[string]string{
+				"login":    email,
+				"password": password,
+			}),
+			out:          httptest.NewRecorder(),
+			expectedCode: http.StatusOK,
+		},
 		{
 			name:         "invalid body",
 			in:           newRequest("/signin", http.MethodGet, "invalid"),
@@ -277,7 +383,7 @@ func TestServer_HandleUserInfo(t *testing.T) {
 	}
 }
 
-func TestServer_HandleUpdateUser(t *testing.T) {
+func TestServer_HandlePatchMe(t *testing.T) {
 	user := models.NewTestUser(t)
 	store := teststore.New()
 	store.User(context.Background()).Create(user)
@@ -289,6 +395,7 @@ func TestServer_HandleUpdateUser(t *testing.T) {
 		name string
 		payload interface{}
 		expectedCode int
+		expectedField string
 	}{
 		{
 			name: "valid email update",
@@ -298,18 +405,19 @@ func TestServer_HandleUpdateUser(t *testing.T) {
 			expectedCode: http.StatusOK,
 		},
 		{
-			name: "valid contacts update",
+			name: "valid login update",
 			payload: map[string]string {
-				"contacts": "CONTACTS",
+				"login": "User_good_123",
 			},
 			expectedCode: http.StatusOK,
 		},
 		{
-			name: "valid login update",
-			payload: map[string]string {
-				"login": "User_good_123",
+			name: "legacy contacts are read only",
+			payload: map[string]interface{} {
+				"contacts": nil,
 			},
-			expectedCode: http.StatusOK,
+			expectedCode: http.StatusUnauthorized,
+			expectedField: "contacts",
 		},
 		{
 			name: "Not admin trying to change ROLE",
@@ -317,6 +425,15 @@ func TestServer_HandleUpdateUser(t *testing.T) {
 				"user_role": "ADMIN",
 			},
 			expectedCode: http.StatusUnauthorized,
+			expectedField: "user_role",
+		},
+		{
+			name: "Not admin trying to change active status",
+			payload: map[string]bool {
+				"is_active": false,
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedField: "is_active",
 		},
 		{
 			name: "Trying to change TOKEN",
@@ -324,6 +441,7 @@ func TestServer_HandleUpdateUser(t *testing.T) {
 				"api_token": "1232131231",
 			},
 			expectedCode: http.StatusUnauthorized,
+			expectedField: "api_token",
 		},
 		{
 			name: "invalid email",
@@ -331,17 +449,123 @@ func TestServer_HandleUpdateUser(t *testing.T) {
 				"email": "123@",
 			},
 			expectedCode: http.StatusBadRequest,
+			expectedField: "email",
+		},
+		{
+			name: "clear email with null",
+			payload: map[string]interface{} {
+				"email": nil,
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedField: "email",
+		},
+		{
+			name: "unknown field",
+			payload: map[string]string {
+				"nickname": "user",
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedField: "nickname",
+		},
+		{
+			name: "not an object",
+			payload: []string{"email"},
+			expectedCode: http.StatusBadRequest,
 		},
 	}
 	for _, tc := range testCases {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
-			w, r := httpParams("/api/v1/updateUser", http.MethodPost, tc.payload)
+			w, r := httpParams("/api/v1/users/me", http.MethodPatch, tc.payload)
 			setAuthToken(r)
 			h.ServeHTTP(w, r)
 			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedField != "" {
+				response := &struct {
+					Fields map[string]string `json:"fields"`
+				}{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(response))
+				assert.Contains(t, response.Fields, tc.expectedField)
+			}
+		})
+	}
+
+	updated, _ := store.User(context.Background()).FindById(user.ID)
+	assert.Equal(t, "Contacts", updated.Contacts)
+	assert.Equal(t, roles.USER, updated.Role)
+	assert.True(t, updated.IsActive)
+}
+
+func TestServer_HandleChangePassword(t *testing.T) {
+	store := teststore.New()
+	ctx := context.Background()
+	user := models.NewTestUser(t)
+	store.User(ctx).Create(user)
+	current, other := models.NewTestSession(t), models.NewTestSession(t)
+	current.UserId, other.UserId = user.ID, user.ID
+	store.Sessions(ctx).Create(current)
+	store.Sessions(ctx).Create(other)
+
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name string
+		payload interface{}
+		expectedCode int
+	}{
+		{
+			name: "wrong current password",
+			payload: map[string]string{
+				"current_password": "654321",
+				"new_password": "newpassword1",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "weak password",
+			payload: map[string]string{
+				"current_password": "qwerty42",
+				"new_password": "password",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "password contains login",
+			payload: map[string]string{
+				"current_password": "qwerty42",
+				"new_password": "usernmae123",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "valid",
+			payload: map[string]string{
+				"current_password": "qwerty42",
+				"new_password": "newpassword1",
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			jwt, _ := jwtHelper.CreateForSession(user, current.ID, 1, "access")
+			w, r := httpParams("/api/v1/users/me/password", http.MethodPost, tc.payload)
+			r.Header.Set("Authorization", "Bearer "+jwt)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
 		})
 	}
+
+	updated, _ := store.User(ctx).FindById(user.ID)
+	assert.True(t, updated.ComparePassword("newpassword1"))
+	s1, _ := store.Sessions(ctx).Find(current.ID)
+	assert.True(t, s1.IsActive())
+	s2, _ := store.Sessions(ctx).Find(other.ID)
+	assert.False(t, s2.IsActive())
+	notifs, _ := store.Notifications(ctx).FindById(uint(user.ID))
+	assert.Equal(t, 1, len(notifs))
 }
 
 func TestServer_HandleNotifUpdate(t *testing.T) {
@@ -452,6 +676,7 @@ func TestServer_HandleNotifCheck(t *testing.T) {
 func TestServer_HandleTicketCreate(t *testing.T) {
 	ticket := models.NewTestTicket(t)
 	store := teststore.New()
+	store.Sections(context.Background()).Create(models.NewTestSection(t))
 	h := handlers.New(store, logrus.New())
 	h.SetupRoutes()
 
@@ -481,6 +706,16 @@ func TestServer_HandleTicketCreate(t *testing.T) {
 			authenticated: true,
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "unknown section",
+			payload: map[string]string {
+				"title": ticket.Title,
+				"description": ticket.Description,
+				"section": "Other question",
+			},
+			authenticated: true,
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name: "invalid json",
 			payload: "invalid",
@@ -512,8 +747,10 @@ func TestServer_HandleTicketCreate(t *testing.T) {
 
 func TestServer_HandleTicket(t *testing.T) {
 	ticket := models.NewTestTicket(t)
+	ticket.From = 1
 	store := teststore.New()
 	store.Tickets(context.Background()).Create(ticket)
+	store.Tickets(context.Background()).Create(models.NewTestTicket(t))
 	h := handlers.New(store, logrus.New())
 	h.SetupRoutes()
 
@@ -537,6 +774,16 @@ func TestServer_HandleTicket(t *testing.T) {
 			path: "invalid",
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name: "other user ticket",
+			path: "2",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "not found",
+			path: "555",
+			expectedCode: http.StatusNotFound,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -594,6 +841,13 @@ func TestServer_HandleAddMessage(t *testing.T) {
 	h := handlers.New(store, logrus.New())
 	h.SetupRoutes()
 
+	for i := 0; i < 4; i++ {
+		ticket := models.NewTestTicket(t)
+		ticket.From = 1
+		store.Tickets(context.Background()).Create(ticket)
+	}
+	store.Tickets(context.Background()).Create(models.NewTestTicket(t))
+
 	testCases := []struct {
 		name string
 		payload interface{}
@@ -622,6 +876,14 @@ func TestServer_HandleAddMessage(t *testing.T) {
 			},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name: "not valid. other user ticket",
+			payload: map[string]interface{} {
+				"message": "Some message",
+				"ticket_id": 5,
+			},
+			expectedCode: http.StatusNotFound,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -639,6 +901,12 @@ func TestServer_HandleTakeMessages(t *testing.T) {
 	h := handlers.New(store, logrus.New())
 	h.SetupRoutes()
 	
+	for i := 0; i < 3; i++ {
+		ticket := models.NewTestTicket(t)
+		ticket.From = 1
+		store.Tickets(context.Background()).Create(ticket)
+	}
+
 	var ticketId uint = 3
 	for i := 0; i < 5; i++ {
 		message := models.NewTestTicketMessage(t)
@@ -662,9 +930,9 @@ func TestServer_HandleTakeMessages(t *testing.T) {
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "valid. id doesn`t exist. empty response",
+			name: "not valid. id doesn`t exist",
 			path: "?id=555",
-			expectedCode: http.StatusOK,
+			expectedCode: http.StatusNotFound,
 		},
 		{
 			name: "not valid. id param doesn`t exist",
@@ -683,75 +951,219 @@ func TestServer_HandleTakeMessages(t *testing.T) {
 	}
 }
 
-func TestServer_HandleChangeStatus(t *testing.T) {
+func TestServer_HandleTransition(t *testing.T) {
 	store := teststore.New()
 	h := handlers.New(store, logrus.New())
 	h.SetupRoutes()
 
 	ticket := models.NewTestTicket(t)
+	ticket.From = 1
 	store.Tickets(context.Background()).Create(ticket)
+	store.Tickets(context.Background()).Accept(ticket.ID, &models.User{ID: 2})
+	ticket.Status = ticketStatus.InProcess
 
 	testCases := []struct {
 		name string
-		path string
+		payload interface{}
+		userId int
+		role string
 		expectedCode int
 	} {
 		{
-			name: "valid",
-			path: "?id=1&st=in process",
+			name: "customer resolves",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.Resolved},
+			userId: 1,
+			role: roles.USER,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "not assigned helper",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.WaitingForCustomer},
+			userId: 3,
+			role: roles.SUPPORT,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "helper asks customer",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.WaitingForCustomer, "comment": "Send logs"},
+			userId: 2,
+			role: roles.SUPPORT,
 			expectedCode: http.StatusOK,
 		},
 		{
-			name: "valid",
-			path: "?id=1&st=closed",
+			name: "customer answers",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.InProcess},
+			userId: 1,
+			role: roles.USER,
 			expectedCode: http.StatusOK,
 		},
 		{
-			name: "empty id",
-			path: "?id=&st=in process",
-			expectedCode: http.StatusBadRequest,
+			name: "helper resolves",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.Resolved},
+			userId: 2,
+			role: roles.SUPPORT,
+			expectedCode: http.StatusOK,
 		},
 		{
-			name: "invalid id number",
-			path: "?id=-30&st=in process",
-			expectedCode: http.StatusBadRequest,
+			name: "customer closes",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.Closed},
+			userId: 1,
+			role: roles.USER,
+			expectedCode: http.StatusOK,
 		},
 		{
-			name: "invalid id. doesn`t exist",
-			path: "?id=555&st=in process",
-			expectedCode: http.StatusBadRequest,
+			name: "helper reopens",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.Opened},
+			userId: 2,
+			role: roles.SUPPORT,
+			expectedCode: http.StatusConflict,
 		},
 		{
-			name: "invalid status",
-			path: "?id=1&st=in 123123process",
-			expectedCode: http.StatusBadRequest,
+			name: "customer reopens",
+			payload: map[string]interface{}{"ticket_id": 1, "status": ticketStatus.Opened},
+			userId: 1,
+			role: roles.USER,
+			expectedCode: http.StatusOK,
 		},
 		{
-			name: "empty id param",
-			path: "?st=in process",
+			name: "invalid status",
+			payload: map[string]interface{}{"ticket_id": 1, "status": "in 123123process"},
+			userId: 1,
+			role: roles.USER,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "empty status",
-			path: "?id=1&st=",
+			name: "empty id",
+			payload: map[string]interface{}{"status": ticketStatus.Closed},
+			userId: 1,
+			role: roles.USER,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "empty status param",
-			path: "?id=1",
-			expectedCode: http.StatusBadRequest,
+			name: "invalid id. doesn`t exist",
+			payload: map[string]interface{}{"ticket_id": 555, "status": ticketStatus.Closed},
+			userId: 1,
+			role: roles.USER,
+			expectedCode: http.StatusNotFound,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			w, r := httpParams("/api/v1/support/ticket/status" + tc.path, http.MethodGet, nil)
-			setAuthToken(r)
+			w, r := httpParams("/api/v1/support/ticket/transition", http.MethodPost, tc.payload)
+			setAuthTokenWithRole(r, tc.userId, tc.role)
 			h.ServeHTTP(w, r)
 			assert.Equal(t, tc.expectedCode, w.Code)
 		})
 	}
+
+	w, r := httpParams("/api/v1/support/tickets/1/statuses", http.MethodGet, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	changes := make([]*models.TicketStatusChange, 0)
+	json.NewDecoder(w.Body).Decode(&changes)
+	assert.Equal(t, 5, len(changes))
+	assert.Equal(t, "Send logs", changes[0].Comment)
+	assert.Equal(t, 2, changes[0].Actor)
+}
+
+func TestServer_HandleExport(t *testing.T) {
+	store := teststore.New()
+	ctx := context.Background()
+	user := models.NewTestUser(t)
+	store.User(ctx).Create(user)
+	store.Balance(ctx).CreateBalance(uint(user.ID))
+	ticket := models.NewTestTicket(t)
+	ticket.From = uint(user.ID)
+	store.Tickets(ctx).Create(ticket)
+	store.Tickets(ctx).AddMessage(&models.TicketMessage{Who: uint(user.ID), TicketId: ticket.ID, Message: "Hello"})
+
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
+
+	w, r := httpParams("/api/v1/users/me/export", http.MethodGet, nil)
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(t, err)
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	assert.Contains(t, files, "profile.json")
+	assert.Contains(t, files, "balance.json")
+
+	f, _ := files["tickets.json"].Open()
+	defer f.Close()
+	tickets := []struct {
+		ID       uint                    `json:"id"`
+		Messages []*models.TicketMessage `json:"messages"`
+	}{}
+	assert.NoError(t, json.NewDecoder(f).Decode(&tickets))
+	assert.Equal(t, 1, len(tickets))
+	assert.Equal(t, 1, len(tickets[0].Messages))
 }
 
+func TestServer_HandleAccountDeletion(t *testing.T) {
+	store := teststore.New()
+	user := models.NewTestUser(t)
+	store.User(context.Background()).Create(user)
 
+	h := handlers.New(store, logrus.New())
+	h.SetupRoutes()
 
+	testCases := []struct {
+		name string
+		method string
+		payload interface{}
+		expectedCode int
+	}{
+		{
+			name: "wrong password",
+			method: http.MethodPost,
+			payload: map[string]string{
+				"password": "654321",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "valid request",
+			method: http.MethodPost,
+			payload: map[string]string{
+				"password": "qwerty42",
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name: "already requested",
+			method: http.MethodPost,
+			payload: map[string]string{
+				"password": "qwerty42",
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "cancel",
+			method: http.MethodDelete,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "nothing to cancel",
+			method: http.MethodDelete,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/users/me/deletion", tc.method, tc.payload)
+			setAuthToken(r)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
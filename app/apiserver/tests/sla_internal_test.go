@@ -0,0 +1,251 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/slaKind"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleSlaPolicies(t *testing.T) {
+	s := teststore.New()
+	section := models.NewTestSection(t)
+	section.Name = "Billing"
+	s.Sections(context.Background()).Create(section)
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name         string
+		path         string
+		method       string
+		payload      interface{}
+		role         string
+		expectedCode int
+	}{
+		{
+			name:   "create default",
+			path:   "/api/v1/admin/sla/policies",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"first_response_minutes": 60,
+				"resolution_minutes":     480,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "create section",
+			path:   "/api/v1/admin/sla/policies",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"section":                "Billing",
+				"first_response_minutes": 30,
+				"resolution_minutes":     240,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "duplicate section",
+			path:   "/api/v1/admin/sla/policies",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"section":                "Billing",
+				"first_response_minutes": 10,
+				"resolution_minutes":     20,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "unknown section",
+			path:   "/api/v1/admin/sla/policies",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"section":                "Other",
+				"first_response_minutes": 60,
+				"resolution_minutes":     120,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "resolution before response",
+			path:   "/api/v1/admin/sla/policies",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"section":                "Billing",
+				"first_response_minutes": 60,
+				"resolution_minutes":     30,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "not valid body",
+			path:         "/api/v1/admin/sla/policies",
+			method:       http.MethodPost,
+			payload:      "policy",
+			role:         roles.ADMIN,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "not admin",
+			path:   "/api/v1/admin/sla/policies",
+			method: http.MethodPost,
+			payload: map[string]interface{}{
+				"section":                "Other",
+				"first_response_minutes": 10,
+				"resolution_minutes":     20,
+			},
+			role:         roles.SUPPORT,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "update",
+			path:   "/api/v1/admin/sla/policies/2",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"section":                "Billing",
+				"first_response_minutes": 15,
+				"resolution_minutes":     120,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "update to taken section",
+			path:   "/api/v1/admin/sla/policies/2",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"first_response_minutes": 15,
+				"resolution_minutes":     120,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "update not existing",
+			path:   "/api/v1/admin/sla/policies/10",
+			method: http.MethodPut,
+			payload: map[string]interface{}{
+				"section":                "Billing",
+				"first_response_minutes": 15,
+				"resolution_minutes":     120,
+			},
+			role:         roles.ADMIN,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "delete",
+			path:         "/api/v1/admin/sla/policies/1",
+			method:       http.MethodDelete,
+			role:         roles.ADMIN,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "delete not existing",
+			path:         "/api/v1/admin/sla/policies/1",
+			method:       http.MethodDelete,
+			role:         roles.ADMIN,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams(tc.path, tc.method, tc.payload)
+			setAuthTokenWithRole(r, 1, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	w, r := httpParams("/api/v1/admin/sla/policies", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 1, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	policies := make([]*models.SlaPolicy, 0)
+	json.NewDecoder(w.Body).Decode(&policies)
+	assert.Len(t, policies, 1)
+	assert.Equal(t, "Billing", policies[0].Section)
+	assert.Equal(t, 15, policies[0].FirstResponseMinutes)
+}
+
+func TestServer_HandleSlaReport(t *testing.T) {
+	s := teststore.New()
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+	ctx := context.Background()
+
+	assert.NoError(t, s.Sla(ctx).CreatePolicy(&models.SlaPolicy{FirstResponseMinutes: 60, ResolutionMinutes: 240}))
+	for i := 0; i < 2; i++ {
+		ticket := models.NewTestTicket(t)
+		assert.NoError(t, s.Tickets(ctx).Create(ticket))
+		ticket.Created_at = time.Now().UTC().Add(-2 * time.Hour)
+	}
+	reply := models.NewTestTicketMessage(t)
+	assert.NoError(t, s.Tickets(ctx).AddMessage(reply))
+	reply.Date = time.Now().UTC().Add(-90 * time.Minute)
+	assert.NoError(t, s.Sla(ctx).CreateBreach(&models.SlaBreach{TicketId: 2, PolicyId: 1, Kind: slaKind.FirstResponse}))
+
+	testCases := []struct {
+		name         string
+		query        url.Values
+		role         string
+		expectedCode int
+	}{
+		{name: "default period", role: roles.ADMIN, expectedCode: http.StatusOK},
+		{name: "not valid time", query: url.Values{"from": {"yesterday"}}, role: roles.ADMIN, expectedCode: http.StatusBadRequest},
+		{name: "empty period", query: url.Values{"from": {"2020-05-01T00:00:00Z"}, "to": {"2020-05-01T00:00:00Z"}}, role: roles.ADMIN, expectedCode: http.StatusBadRequest},
+		{name: "not admin", role: roles.USER, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams("/api/v1/admin/sla/report?"+tc.query.Encode(), http.MethodGet, nil)
+			setAuthTokenWithRole(r, 1, tc.role)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	w, r := httpParams("/api/v1/admin/sla/report", http.MethodGet, nil)
+	setAuthTokenWithRole(r, 1, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	report := struct {
+		Total struct {
+			Tickets       int `json:"tickets"`
+			FirstResponse struct {
+				Met        int     `json:"met"`
+				Breached   int     `json:"breached"`
+				Compliance float64 `json:"compliance"`
+			} `json:"first_response"`
+		} `json:"total"`
+	}{}
+	json.NewDecoder(w.Body).Decode(&report)
+	assert.Equal(t, 2, report.Total.Tickets)
+	assert.Equal(t, 1, report.Total.FirstResponse.Met)
+	assert.Equal(t, 1, report.Total.FirstResponse.Breached)
+	assert.Equal(t, float64(50), report.Total.FirstResponse.Compliance)
+
+	w, r = httpParams("/api/v1/admin/sla/breaches?from="+url.QueryEscape(time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)), http.MethodGet, nil)
+	setAuthTokenWithRole(r, 1, roles.ADMIN)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	breaches := make([]*models.SlaBreach, 0)
+	json.NewDecoder(w.Body).Decode(&breaches)
+	assert.Len(t, breaches, 1)
+	assert.Equal(t, uint(2), breaches[0].TicketId)
+}
@@ -0,0 +1,132 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_TicketNotifications(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, role := range []string{roles.USER, roles.SUPPORT, roles.SUPPORT} {
+		user := models.NewTestUser(t)
+		user.Login, user.Email = user.Login+string(rune('a'+i)), string(rune('a'+i))+user.Email
+		assert.NoError(t, s.User(ctx).Create(user))
+		user.Role = role
+	}
+	s.Sections(ctx).Create(&models.Section{
+		Name:     "bugs",
+		Priority: ticketPriority.Normal,
+		Routing:  sectionRouting.RoundRobin,
+	})
+	inactive, _ := s.User(ctx).FindById(3)
+	inactive.IsActive = false
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	count := func(userId uint) int {
+		notes, _ := s.Notifications(ctx).FindAll(userId)
+		return len(notes)
+	}
+	send := func(path, method string, payload interface{}, userId int, role string) int {
+		w, r := httpParams(path, method, payload)
+		setAuthTokenWithRole(r, userId, role)
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("/api/v1/support/ticket/create", http.MethodPost, map[string]string{
+		"title":       "Crash",
+		"description": "App crashes on start",
+		"section":     "bugs",
+	}, 1, roles.USER))
+	assert.Equal(t, 1, count(2))
+	assert.Equal(t, 0, count(3))
+	assert.Equal(t, 0, count(1))
+
+	assert.Equal(t, http.StatusOK, send("/api/v1/support/tickets/1/accept", http.MethodPost, nil, 2, roles.SUPPORT))
+	assert.Equal(t, 1, count(1))
+
+	assert.Equal(t, http.StatusOK, send("/api/v1/support/message/add", http.MethodPost, map[string]interface{}{
+		"ticket_id": 1,
+		"message":   "Send logs please",
+	}, 2, roles.SUPPORT))
+	assert.Equal(t, 2, count(1))
+	assert.Equal(t, 1, count(2))
+
+	assert.Equal(t, http.StatusOK, send("/api/v1/support/ticket/transition", http.MethodPost, map[string]interface{}{
+		"ticket_id": 1,
+		"status":    ticketStatus.WaitingForCustomer,
+	}, 2, roles.SUPPORT))
+	assert.Equal(t, 3, count(1))
+
+	assert.Equal(t, http.StatusOK, send("/api/v1/support/tickets/1/mute", http.MethodPost, nil, 1, roles.USER))
+	assert.Equal(t, http.StatusOK, send("/api/v1/support/message/add", http.MethodPost, map[string]interface{}{
+		"ticket_id": 1,
+		"message":   "Are you there?",
+	}, 2, roles.SUPPORT))
+	assert.Equal(t, 3, count(1))
+
+	assert.Equal(t, http.StatusOK, send("/api/v1/support/ticket/transition", http.MethodPost, map[string]interface{}{
+		"ticket_id": 1,
+		"status":    ticketStatus.Closed,
+	}, 1, roles.USER))
+	notes, _ := s.Notifications(ctx).FindAll(2)
+	assert.Equal(t, 2, len(notes))
+	closed := false
+	for _, note := range notes {
+		closed = closed || note.Message == "Ticket #1 \"Crash\" was closed"
+	}
+	assert.True(t, closed)
+}
+
+func TestServer_HandleTicketMute(t *testing.T) {
+	s := teststore.New()
+	ticket := models.NewTestTicket(t)
+	ticket.From = 1
+	s.Tickets(context.Background()).Create(ticket)
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+
+	testCases := []struct {
+		name          string
+		path          string
+		method        string
+		userId        int
+		expectedCode  int
+		expectedMuted bool
+	}{
+		{name: "not muted", path: "/api/v1/support/tickets/1/mute", method: http.MethodGet, userId: 1, expectedCode: http.StatusOK},
+		{name: "mute", path: "/api/v1/support/tickets/1/mute", method: http.MethodPost, userId: 1, expectedCode: http.StatusOK, expectedMuted: true},
+		{name: "muted", path: "/api/v1/support/tickets/1/mute", method: http.MethodGet, userId: 1, expectedCode: http.StatusOK, expectedMuted: true},
+		{name: "other user", path: "/api/v1/support/tickets/1/mute", method: http.MethodPost, userId: 3, expectedCode: http.StatusNotFound},
+		{name: "not existing", path: "/api/v1/support/tickets/5/mute", method: http.MethodPost, userId: 1, expectedCode: http.StatusNotFound},
+		{name: "unmute", path: "/api/v1/support/tickets/1/mute", method: http.MethodDelete, userId: 1, expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := httpParams(tc.path, tc.method, nil)
+			setAuthTokenWithRole(r, tc.userId, roles.USER)
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				body := map[string]interface{}{}
+				json.NewDecoder(w.Body).Decode(&body)
+				assert.Equal(t, tc.expectedMuted, body["muted"])
+			}
+		})
+	}
+}
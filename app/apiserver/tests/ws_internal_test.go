@@ -0,0 +1,112 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/inhumanLightBackend/app/apiserver/handlers"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type wsMessage struct {
+	Type     string                 `json:"type"`
+	TicketId uint                   `json:"ticket_id"`
+	Actor    int                    `json:"actor"`
+	Data     map[string]interface{} `json:"data"`
+	Error    string                 `json:"error"`
+}
+
+func wsDial(t *testing.T, server *httptest.Server, query string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/ws" + query
+	return websocket.DefaultDialer.Dial(url, header)
+}
+
+func wsRead(t *testing.T, conn *websocket.Conn) *wsMessage {
+	message := &wsMessage{}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	assert.NoError(t, conn.ReadJSON(message))
+	return message
+}
+
+func TestServer_HandleWebSocket(t *testing.T) {
+	s := teststore.New()
+	ticket := models.NewTestTicket(t)
+	ticket.From = 1
+	s.Tickets(context.Background()).Create(ticket)
+	s.Tickets(context.Background()).Accept(ticket.ID, &models.User{ID: 2})
+	ticket.Status = ticketStatus.InProcess
+	foreign := models.NewTestTicket(t)
+	foreign.From = 3
+	s.Tickets(context.Background()).Create(foreign)
+	h := handlers.New(s, logrus.New())
+	h.SetupRoutes()
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	customerToken, _ := jwtHelper.Create(&models.User{ID: 1, Role: roles.USER}, 1, "access")
+	helperToken, _ := jwtHelper.Create(&models.User{ID: 2, Role: roles.SUPPORT}, 1, "access")
+
+	_, resp, err := wsDial(t, server, "", nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	_, resp, err = wsDial(t, server, "?tickets=2&access_token="+customerToken, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	_, resp, err = wsDial(t, server, "?tickets=first&access_token="+customerToken, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	customer, _, err := wsDial(t, server, "?tickets=1&access_token="+customerToken, nil)
+	assert.NoError(t, err)
+	defer customer.Close()
+	helper, _, err := wsDial(t, server, "", http.Header{"Authorization": {"Bearer " + helperToken}})
+	assert.NoError(t, err)
+	defer helper.Close()
+
+	helper.WriteJSON(map[string]interface{}{"type": "subscribe", "ticket_id": 1})
+	assert.Equal(t, "subscribed", wsRead(t, helper).Type)
+	customer.WriteJSON(map[string]interface{}{"type": "subscribe", "ticket_id": 2})
+	reply := wsRead(t, customer)
+	assert.Equal(t, "error", reply.Type)
+	assert.Equal(t, uint(2), reply.TicketId)
+
+	helper.WriteJSON(map[string]interface{}{"type": "typing", "ticket_id": 1})
+	event := wsRead(t, customer)
+	assert.Equal(t, realtime.EventTyping, event.Type)
+	assert.Equal(t, 2, event.Actor)
+
+	w, r := httpParams("/api/v1/support/message/add", http.MethodPost, map[string]interface{}{"ticket_id": 1, "message": "Hello"})
+	setAuthToken(r)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	event = wsRead(t, helper)
+	assert.Equal(t, realtime.EventMessage, event.Type)
+	assert.Equal(t, "Hello", event.Data["message"])
+	assert.Equal(t, realtime.EventMessage, wsRead(t, customer).Type)
+
+	w, r = httpParams("/api/v1/support/ticket/transition", http.MethodPost, map[string]interface{}{"ticket_id": 1, "status": ticketStatus.Resolved})
+	setAuthTokenWithRole(r, 2, roles.SUPPORT)
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	event = wsRead(t, customer)
+	assert.Equal(t, realtime.EventStatus, event.Type)
+	assert.Equal(t, ticketStatus.Resolved, event.Data["to"])
+	assert.Equal(t, realtime.EventStatus, wsRead(t, helper).Type)
+
+	helper.WriteJSON(map[string]interface{}{"type": "unsubscribe", "ticket_id": 1})
+	assert.Equal(t, "unsubscribed", wsRead(t, helper).Type)
+	helper.WriteJSON(map[string]interface{}{"type": "typing", "ticket_id": 1})
+	assert.Equal(t, "error", wsRead(t, helper).Type)
+}
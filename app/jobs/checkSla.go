@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/notificationStatus"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/sla"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/utils/notifications"
+)
+
+// Time to wait for admin chat delivery
+const escalationTimeout = 10 * time.Second
+
+// Register SLA breaches of tickets created during lookback period and
+// escalate new ones to admins. Every target of ticket is escalated once
+func CheckSla(s store.Store, sender notifications.NotificationSender, lookback time.Duration) Job {
+	var adminChat chan string
+	if sender != nil {
+		adminChat = sender.Notify()
+	}
+
+	return func(ctx context.Context) error {
+		policies, err := s.Sla(ctx).Policies()
+		if err != nil || len(policies) == 0 {
+			return err
+		}
+
+		now := time.Now().UTC()
+		timelines, err := s.Sla(ctx).Timelines(now.Add(-lookback), time.Time{})
+		if err != nil {
+			return err
+		}
+
+		for _, timeline := range timelines {
+			policy := sla.PolicyFor(policies, timeline.Section)
+			if policy == nil {
+				continue
+			}
+
+			for _, kind := range sla.Kinds {
+				state, due := sla.Check(policy, timeline, kind, now)
+				if state != sla.Breached {
+					continue
+				}
+
+				breach := &models.SlaBreach{
+					TicketId: timeline.TicketId,
+					PolicyId: policy.ID,
+					Kind:     kind,
+					DueAt:    due,
+				}
+				if err := s.Sla(ctx).CreateBreach(breach); err != nil {
+					if err == store.ErrBreachRegistered {
+						continue
+					}
+					return err
+				}
+
+				if err := escalate(ctx, s, adminChat, timeline, breach); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// Notify active admins and admin chat about breach
+func escalate(ctx context.Context, s store.Store, adminChat chan string, timeline *models.TicketTimeline, breach *models.SlaBreach) error {
+	message := fmt.Sprintf("SLA breached: %s of ticket #%d in section \"%s\" was due %s",
+		breach.Kind, breach.TicketId, timeline.Section, breach.DueAt.Format(time.RFC3339))
+
+	active := true
+	admins, err := s.User(ctx).List(&store.UserFilter{
+		Role:   roles.ADMIN,
+		Active: &active,
+	})
+	if err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		if err := s.Notifications(ctx).Create(&models.Notification{
+			Message: message,
+			Status:  notificationStatus.Warnign,
+			For:     admin.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if adminChat == nil {
+		return nil
+	}
+
+	// Sender replies with delivery status to every message
+	timeout := time.NewTimer(escalationTimeout)
+	defer timeout.Stop()
+	select {
+	case adminChat <- message:
+	case <-timeout.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-adminChat:
+	case <-timeout.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
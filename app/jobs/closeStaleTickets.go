@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/utils/notifications"
+)
+
+// Remind customer about ticket waiting on them longer than remindAfter
+// since last message of helper. Ticket is closed by system if customer
+// does not reply during closeAfter since reminder
+func CloseStaleTickets(s store.Store, dispatcher *notifications.Dispatcher, bus realtime.Bus, remindAfter, closeAfter time.Duration) Job {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		tickets, err := s.Tickets(ctx).Stale(now.Add(-remindAfter))
+		if err != nil {
+			return err
+		}
+
+		for _, ticket := range tickets {
+			if !ticket.Reminded() {
+				if err := remindCustomer(ctx, s, dispatcher, ticket, now, closeAfter); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if now.Before(ticket.RemindedAt.Add(closeAfter)) {
+				continue
+			}
+			if err := closeStale(ctx, s, dispatcher, bus, ticket); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func remindCustomer(ctx context.Context, s store.Store, dispatcher *notifications.Dispatcher, ticket *models.StaleTicket, now time.Time, closeAfter time.Duration) error {
+	if err := s.Tickets(ctx).Remind(ticket.TicketId, now); err != nil {
+		return err
+	}
+
+	dispatcher.NotifyTicket(ctx, ticket.TicketId, []int{int(ticket.Customer)},
+		fmt.Sprintf("Ticket #%d \"%s\" is waiting for your reply. It will be closed %s if there is no reply",
+			ticket.TicketId, ticket.Title, now.Add(closeAfter).Format(time.RFC3339)))
+
+	return nil
+}
+
+// Close ticket on behalf of system. Ticket changed since it was found is skipped
+func closeStale(ctx context.Context, s store.Store, dispatcher *notifications.Dispatcher, bus realtime.Bus, ticket *models.StaleTicket) error {
+	change := &models.TicketStatusChange{
+		TicketId: ticket.TicketId,
+		From:     ticket.Status,
+		To:       ticketStatus.Closed,
+		Comment:  "Closed automatically, customer did not reply",
+	}
+	if err := s.Tickets(ctx).ChangeStatus(change); err != nil {
+		if err == store.ErrStatusChanged {
+			return nil
+		}
+		return err
+	}
+
+	// Events are best effort, change is already saved
+	bus.Publish(&realtime.Event{
+		Type:     realtime.EventStatus,
+		TicketId: ticket.TicketId,
+		Actor:    change.Actor,
+		Data:     change,
+	})
+
+	recipients := []int{int(ticket.Customer)}
+	if ticket.Helper > 0 {
+		recipients = append(recipients, ticket.Helper)
+	}
+
+	dispatcher.NotifyTicket(ctx, ticket.TicketId, recipients,
+		fmt.Sprintf("Ticket #%d \"%s\" was closed automatically, customer did not reply", ticket.TicketId, ticket.Title))
+
+	return nil
+}
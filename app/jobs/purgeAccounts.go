@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Anonymize accounts with passed deletion grace period. Balance records
+// are kept, user id in them becomes a pseudonym
+func PurgeAccounts(s store.Store) Job {
+	return func(ctx context.Context) error {
+		deletions, err := s.Deletions(ctx).FindDue(time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		for _, deletion := range deletions {
+			if err := purgeAccount(ctx, s, deletion.UserId); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Anonymize user, remove their contacts and complete deletion in one transaction
+func purgeAccount(ctx context.Context, s store.Store, userId int) error {
+	return s.WithTx(ctx, func(tx store.Store) error {
+		user, err := tx.User(ctx).FindById(userId)
+		if err != nil && err != store.ErrRecordNotFound {
+			return err
+		}
+
+		if user != nil {
+			user.Anonymize()
+			if err := tx.User(ctx).Update(user); err != nil {
+				return err
+			}
+
+			if err := tx.Sessions(ctx).RevokeAll(userId, ""); err != nil {
+				return err
+			}
+		}
+
+		// Contacts are personal data, nothing of them is kept
+		contacts, err := tx.Contacts(ctx).FindByUser(userId)
+		if err != nil {
+			return err
+		}
+		for _, contact := range contacts {
+			if err := tx.Contacts(ctx).Delete(contact.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Deletions(ctx).Complete(userId); err != nil {
+			return err
+		}
+
+		return tx.Audit(ctx).Create(&models.AuditLog{
+			Action: auditAction.AccountPurge,
+			Target: models.AuditTarget("user", userId),
+		})
+	})
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job executed periodically by scheduler
+type Job func(ctx context.Context) error
+
+type task struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Locker runs fn only if lock with given name is free. Instances
+// sharing one database use it to run each job only once
+type Locker interface {
+	TryLock(ctx context.Context, name string, fn func() error) (bool, error)
+}
+
+// Scheduler runs registered jobs by interval until context is done
+type Scheduler struct {
+	logger *logrus.Logger
+	locker Locker
+	tasks  []*task
+}
+
+// Create new scheduler. Each run of a job holds lock named by the job
+func NewScheduler(logger *logrus.Logger, locker Locker) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+		locker: locker,
+	}
+}
+
+// Register job executed every interval
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.tasks = append(s.tasks, &task{
+		name:     name,
+		interval: interval,
+		job:      job,
+	})
+}
+
+// Start all jobs in background. Each job runs once on start and then by interval
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, t := range s.tasks {
+		go s.run(ctx, t)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, t *task) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		ran, err := s.locker.TryLock(ctx, t.name, func() error {
+			return t.job(ctx)
+		})
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"Job":   t.name,
+				"Error": err.Error(),
+			}).Error("Job failed")
+		} else if !ran {
+			s.logger.WithFields(logrus.Fields{
+				"Job": t.name,
+			}).Debug("Job is running in other instance")
+		} else {
+			s.logger.WithFields(logrus.Fields{
+				"Job":      t.name,
+				"Duration": time.Since(start).Seconds(),
+			}).Debug("Job done")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package jobs_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/jobs"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/slaKind"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+// Sender replying to every message like telegram notificator does
+type fakeSender struct {
+	chat     chan string
+	received chan string
+}
+
+func newFakeSender() *fakeSender {
+	sender := &fakeSender{
+		chat:     make(chan string),
+		received: make(chan string, 16),
+	}
+	go func() {
+		for message := range sender.chat {
+			sender.received <- message
+			sender.chat <- "200"
+		}
+	}()
+
+	return sender
+}
+
+func (fs *fakeSender) Notify() chan string {
+	return fs.chat
+}
+
+func TestCheckSla(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	admin := models.NewTestUser(t)
+	assert.NoError(t, s.User(ctx).Create(admin))
+	admin.Role = roles.ADMIN
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	ticket.Created_at = time.Now().UTC().Add(-2 * time.Hour)
+	fresh := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(fresh))
+
+	sender := newFakeSender()
+	job := jobs.CheckSla(s, sender, 24*time.Hour)
+
+	// No policies, nothing is checked
+	assert.NoError(t, job(ctx))
+	breaches, _ := s.Sla(ctx).Breaches(time.Time{}, time.Time{})
+	assert.Empty(t, breaches)
+
+	policy := &models.SlaPolicy{FirstResponseMinutes: 60, ResolutionMinutes: 240}
+	assert.NoError(t, s.Sla(ctx).CreatePolicy(policy))
+	assert.NoError(t, job(ctx))
+	breaches, _ = s.Sla(ctx).Breaches(time.Time{}, time.Time{})
+	assert.Len(t, breaches, 1)
+	assert.Equal(t, ticket.ID, breaches[0].TicketId)
+	assert.Equal(t, policy.ID, breaches[0].PolicyId)
+	assert.Equal(t, slaKind.FirstResponse, breaches[0].Kind)
+
+	select {
+	case message := <-sender.received:
+		assert.True(t, strings.HasPrefix(message, "SLA breached: first_response of ticket #1"))
+	case <-time.After(time.Second):
+		t.Fatal("admin chat is not notified")
+	}
+	notes, _ := s.Notifications(ctx).FindAll(uint(admin.ID))
+	assert.Len(t, notes, 1)
+
+	// Breach is escalated once
+	assert.NoError(t, job(ctx))
+	breaches, _ = s.Sla(ctx).Breaches(time.Time{}, time.Time{})
+	assert.Len(t, breaches, 1)
+	notes, _ = s.Notifications(ctx).FindAll(uint(admin.ID))
+	assert.Len(t, notes, 1)
+
+	ticket.Created_at = time.Now().UTC().Add(-5 * time.Hour)
+	assert.NoError(t, job(ctx))
+	breaches, _ = s.Sla(ctx).Breaches(time.Time{}, time.Time{})
+	assert.Len(t, breaches, 2)
+	assert.Equal(t, slaKind.Resolution, breaches[1].Kind)
+}
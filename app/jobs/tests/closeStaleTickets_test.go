@@ -0,0 +1,105 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/jobs"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/inhumanLightBackend/app/utils/notifications"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseStaleTickets(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	customer := models.NewTestUser(t)
+	assert.NoError(t, s.User(ctx).Create(customer))
+	helper := models.NewTestUser(t)
+	helper.Email, helper.Login = "helper@gmail.com", "helper"
+	assert.NoError(t, s.User(ctx).Create(helper))
+
+	ticket := models.NewTestTicket(t)
+	ticket.From = uint(customer.ID)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	assert.NoError(t, s.Tickets(ctx).Accept(ticket.ID, helper))
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{
+		TicketId: ticket.ID,
+		To:       ticketStatus.WaitingForCustomer,
+		Actor:    helper.ID,
+	}))
+	message := &models.TicketMessage{Who: uint(helper.ID), TicketId: ticket.ID, Message: "Any news?"}
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+
+	bus := realtime.NewHub()
+	sub := bus.Subscribe()
+	defer sub.Close()
+	sub.Join(ticket.ID)
+	job := jobs.CloseStaleTickets(s, notifications.NewDispatcher(s, logrus.New()), bus, 72*time.Hour, 48*time.Hour)
+
+	// Helper replied recently
+	assert.NoError(t, job(ctx))
+	notes, _ := s.Notifications(ctx).FindAll(uint(customer.ID))
+	assert.Empty(t, notes)
+
+	// Customer is reminded once
+	message.Date = time.Now().UTC().Add(-73 * time.Hour)
+	assert.NoError(t, job(ctx))
+	assert.NoError(t, job(ctx))
+	notes, _ = s.Notifications(ctx).FindAll(uint(customer.ID))
+	assert.Len(t, notes, 1)
+	assert.Equal(t, ticketStatus.WaitingForCustomer, ticket.Status)
+
+	stale, _ := s.Tickets(ctx).Stale(time.Now().UTC())
+	require.Len(t, stale, 1)
+	reminded := stale[0].RemindedAt.Add(-49 * time.Hour)
+	assert.NoError(t, s.Tickets(ctx).Remind(ticket.ID, reminded))
+	assert.NoError(t, job(ctx))
+	assert.Equal(t, ticketStatus.Closed, ticket.Status)
+	changes, _ := s.Tickets(ctx).StatusHistory(ticket.ID)
+	assert.Equal(t, 0, changes[len(changes)-1].Actor)
+	notes, _ = s.Notifications(ctx).FindAll(uint(customer.ID))
+	assert.Len(t, notes, 2)
+	notes, _ = s.Notifications(ctx).FindAll(uint(helper.ID))
+	assert.Len(t, notes, 1)
+
+	select {
+	case event := <-sub.Events():
+		assert.Equal(t, realtime.EventStatus, event.Type)
+		assert.Equal(t, 0, event.Actor)
+	case <-time.After(time.Second):
+		t.Fatal("status event is not published")
+	}
+}
+
+func TestCloseStaleTickets_CustomerReplied(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{
+		TicketId: ticket.ID,
+		To:       ticketStatus.Resolved,
+	}))
+	answer := models.NewTestTicketMessage(t)
+	assert.NoError(t, s.Tickets(ctx).AddMessage(answer))
+	answer.Date = time.Now().UTC().Add(-100 * time.Hour)
+	assert.NoError(t, s.Tickets(ctx).Remind(ticket.ID, answer.Date.Add(time.Hour)))
+
+	reply := models.NewTestTicketMessage(t)
+	reply.Who = ticket.From
+	assert.NoError(t, s.Tickets(ctx).AddMessage(reply))
+	reply.Date = answer.Date.Add(2 * time.Hour)
+
+	job := jobs.CloseStaleTickets(s, notifications.NewDispatcher(s, logrus.New()), realtime.NewHub(), time.Hour, time.Hour)
+	assert.NoError(t, job(ctx))
+	assert.Equal(t, ticketStatus.Resolved, ticket.Status)
+}
@@ -0,0 +1,57 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/jobs"
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeAccounts(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	user := models.NewTestUser(t)
+	assert.NoError(t, s.User(ctx).Create(user))
+	assert.NoError(t, s.Balance(ctx).CreateBalance(uint(user.ID)))
+	session := models.NewTestSession(t)
+	session.UserId = user.ID
+	assert.NoError(t, s.Sessions(ctx).Create(session))
+
+	contact := models.NewTestContact(t)
+	contact.UserId = user.ID
+	assert.NoError(t, s.Contacts(ctx).Create(contact))
+
+	deletion := &models.AccountDeletion{UserId: user.ID}
+	assert.NoError(t, s.Deletions(ctx).Create(deletion))
+
+	assert.NoError(t, jobs.PurgeAccounts(s)(ctx))
+	purged, _ := s.User(ctx).FindById(user.ID)
+	assert.Equal(t, "testUser@gmail.com", purged.Email)
+
+	deletion.PurgeAt = time.Now().UTC().Add(-time.Minute)
+	assert.NoError(t, jobs.PurgeAccounts(s)(ctx))
+	purged, _ = s.User(ctx).FindById(user.ID)
+	assert.Equal(t, "deleted-1@deleted.invalid", purged.Email)
+	assert.False(t, purged.IsActive)
+	assert.False(t, purged.ComparePassword("qwerty42"))
+
+	s1, _ := s.Sessions(ctx).Find(session.ID)
+	assert.False(t, s1.IsActive())
+
+	contacts, err := s.Contacts(ctx).FindByUser(user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, contacts)
+
+	balance, err := s.Balance(ctx).LookForBalance(uint(user.ID))
+	assert.NoError(t, err)
+	assert.NotNil(t, balance)
+
+	deletion, _ = s.Deletions(ctx).Find(user.ID)
+	assert.NotNil(t, deletion.CompletedAt)
+	assert.Error(t, s.Deletions(ctx).Cancel(user.ID))
+}
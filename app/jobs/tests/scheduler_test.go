@@ -0,0 +1,38 @@
+package jobs_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/jobs"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_Locked(t *testing.T) {
+	s := teststore.New()
+	runs := int32(0)
+	start := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		scheduler := jobs.NewScheduler(logrus.New(), s)
+		scheduler.Every("job", time.Hour, func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		})
+		scheduler.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}
+
+	s.TryLock(context.Background(), "job", func() error {
+		start()
+		return nil
+	})
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+
+	start()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
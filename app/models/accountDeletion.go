@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Time between deletion request and purge of personal data.
+// User can cancel the request during this period
+const DeletionGracePeriod = 30 * 24 * time.Hour
+
+// Request for account deletion
+type AccountDeletion struct {
+	UserId      int        `json:"user_id"`
+	RequestedAt time.Time  `json:"requested_at"`
+	PurgeAt     time.Time  `json:"purge_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Fill fields before deletion request create
+func (d *AccountDeletion) BeforeCreate() {
+	d.RequestedAt = time.Now().UTC()
+	d.PurgeAt = d.RequestedAt.Add(DeletionGracePeriod)
+	d.CompletedAt = nil
+}
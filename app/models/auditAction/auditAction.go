@@ -0,0 +1,19 @@
+package auditAction
+
+// Actions written to the audit log
+const (
+	SignIn             = "sign_in"
+	SignInFailed       = "sign_in_failed"
+	TokenRefresh       = "token_refresh"
+	RoleChange         = "role_change"
+	ActiveChange       = "active_change"
+	PasswordChange     = "password_change"
+	DataExport         = "data_export"
+	DeletionRequest    = "deletion_request"
+	DeletionCancel     = "deletion_cancel"
+	AccountPurge       = "account_purge"
+	TicketStatusChange = "ticket_status_change"
+	BalanceAdjust      = "balance_adjust"
+	Impersonate        = "impersonate"
+	ImpersonatedAccess = "impersonated_access"
+)
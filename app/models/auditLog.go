@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Audit log entry model
+type AuditLog struct {
+	ID        int                    `json:"id"`
+	Action    string                 `json:"action"`
+	Actor     int                    `json:"actor"`
+	Target    string                 `json:"target"`
+	IP        string                 `json:"ip"`
+	Details   map[string]interface{} `json:"details"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// Validate audit entry
+func (a *AuditLog) Validate() error {
+	if a.Action == "" {
+		return errors.New("Empty param: 'action'")
+	}
+
+	return nil
+}
+
+// Fill fields before audit entry create
+func (a *AuditLog) BeforeCreate() {
+	a.CreatedAt = time.Now().UTC()
+	if a.Details == nil {
+		a.Details = make(map[string]interface{})
+	}
+}
+
+// Build audit target from entity kind and id. Example: user:12
+func AuditTarget(kind string, id interface{}) string {
+	return fmt.Sprintf("%s:%v", kind, id)
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+)
+
+// Saved answer of helpers. Personal response is visible to its owner only,
+// shared one (owner 0) to all staff. Response with status is a macro,
+// applying it also moves ticket to the status
+type CannedResponse struct {
+	ID         int       `json:"id"`
+	Owner      int       `json:"owner"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	Status     string    `json:"status,omitempty"`
+	UsageCount int       `json:"usage_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Values available in response body as {{user.login}}, {{ticket.id}},
+// {{helper.login}} and so on. User is customer of ticket, helper is
+// staff user answering.
+//
+// Body is not run as text/template. Bodies are written by staff, and
+// template actions like nested {{range}} can keep server busy without
+// limit, while shared macros render on every use. So only variables of
+// cannedVars are substituted and any other {{...}} fails validation
+type CannedVars struct {
+	User   *User
+	Ticket *Ticket
+	Helper *User
+}
+
+// Check if response is available for all staff
+func (c *CannedResponse) Shared() bool {
+	return c.Owner == 0
+}
+
+// Validate response fields and body template
+func (c *CannedResponse) Validate() error {
+	return validation.ValidateStruct(
+		c,
+		validation.Field(&c.Title, validation.Required, validation.Length(1, 100)),
+		validation.Field(&c.Body, validation.Required, validation.Length(1, 4000), validation.By(checkTemplate)),
+		validation.Field(&c.Status, validation.In(ticketStatus.InProcess, ticketStatus.WaitingForCustomer,
+			ticketStatus.Resolved, ticketStatus.Closed)),
+	)
+}
+
+// Fill fields before response create
+func (c *CannedResponse) BeforeCreate() {
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+	c.UsageCount = 0
+}
+
+// Render body with values of ticket. Missing values render empty
+func (c *CannedResponse) Render(vars *CannedVars) string {
+	return cannedReplacer(vars).Replace(c.Body)
+}
+
+// Variables of body, see CannedVars. They are just replaced with values,
+// so rendering takes time proportional to body length
+var cannedVars = []string{
+	"user.id", "user.login", "user.email",
+	"helper.id", "helper.login", "helper.email",
+	"ticket.id", "ticket.title", "ticket.section", "ticket.status", "ticket.priority",
+}
+
+func cannedReplacer(vars *CannedVars) *strings.Replacer {
+	user, ticket, helper := &User{}, &Ticket{}, &User{}
+	if vars.User != nil {
+		user = vars.User
+	}
+	if vars.Ticket != nil {
+		ticket = vars.Ticket
+	}
+	if vars.Helper != nil {
+		helper = vars.Helper
+	}
+
+	values := map[string]string{
+		"user.id":         userId(user),
+		"user.login":      user.Login,
+		"user.email":      user.Email,
+		"helper.id":       userId(helper),
+		"helper.login":    helper.Login,
+		"helper.email":    helper.Email,
+		"ticket.id":       "",
+		"ticket.title":    ticket.Title,
+		"ticket.section":  ticket.Section,
+		"ticket.status":   ticket.Status,
+		"ticket.priority": ticket.Priority,
+	}
+	if ticket.ID != 0 {
+		values["ticket.id"] = strconv.FormatUint(uint64(ticket.ID), 10)
+	}
+
+	pairs := make([]string, 0, len(cannedVars)*2)
+	for _, name := range cannedVars {
+		pairs = append(pairs, "{{"+name+"}}", values[name])
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+func userId(u *User) string {
+	if u.ID == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(u.ID)
+}
+
+// Body may contain only known variables
+func checkTemplate(value interface{}) error {
+	body, _ := value.(string)
+	rest := cannedReplacer(&CannedVars{}).Replace(body)
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return errors.New("unknown variable")
+	}
+
+	return nil
+}
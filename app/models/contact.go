@@ -0,0 +1,101 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/inhumanLightBackend/app/models/contactKind"
+)
+
+const (
+	// Lifetime of contact verification code
+	VerificationCodeTTL = time.Hour
+	// Attempts to enter verification code. Code is invalid after them,
+	// new one has to be sent
+	MaxCodeAttempts = 5
+)
+
+var (
+	telegramChatFormat = regexp.MustCompile(`^-?[0-9]{1,20}$`)
+	phoneFormat        = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	webhookFormat      = regexp.MustCompile(`^https://`)
+)
+
+// User contact. Notifications are delivered only to verified and enabled contacts
+type Contact struct {
+	ID               int       `json:"id"`
+	UserId           int       `json:"user_id"`
+	Kind             string    `json:"kind"`
+	Value            string    `json:"value"`
+	Verified         bool      `json:"verified"`
+	Enabled          bool      `json:"enabled"`
+	VerificationCode string    `json:"-"`
+	CodeExpiresAt    time.Time `json:"-"`
+	CodeAttempts     int       `json:"-"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Validate contact value by its kind
+func (c *Contact) Validate() error {
+	var rules []validation.Rule
+	switch c.Kind {
+	case contactKind.Email:
+		rules = []validation.Rule{is.Email}
+	case contactKind.Telegram:
+		rules = []validation.Rule{validation.Match(telegramChatFormat).Error("must be a telegram chat id")}
+	case contactKind.Phone:
+		rules = []validation.Rule{validation.Match(phoneFormat).Error("must be a phone in international format")}
+	case contactKind.Webhook:
+		rules = []validation.Rule{is.URL, validation.Match(webhookFormat).Error("must be a https url")}
+	default:
+		return validation.Errors{"kind": contactKind.ErrContactKindNotFound}
+	}
+
+	return validation.Errors{
+		"value": validation.Validate(c.Value, append([]validation.Rule{validation.Required}, rules...)...),
+	}.Filter()
+}
+
+// Fill fields before contact create. Contact is not verified until code is confirmed
+func (c *Contact) BeforeCreate() error {
+	c.CreatedAt = time.Now().UTC()
+	c.Verified = false
+	c.Enabled = false
+
+	return c.NewVerificationCode()
+}
+
+// Generate new 6-digit verification code
+func (c *Contact) NewVerificationCode() error {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return err
+	}
+
+	c.VerificationCode = fmt.Sprintf("%06d", n.Int64())
+	c.CodeExpiresAt = time.Now().UTC().Add(VerificationCodeTTL)
+	c.CodeAttempts = 0
+
+	return nil
+}
+
+// Verify contact with code. Verified contact is enabled for notifications.
+// CodeAttempts must already count this attempt
+func (c *Contact) Verify(code string) bool {
+	if c.VerificationCode == "" || time.Now().UTC().After(c.CodeExpiresAt) || c.CodeAttempts > MaxCodeAttempts ||
+		subtle.ConstantTimeCompare([]byte(c.VerificationCode), []byte(code)) != 1 {
+		return false
+	}
+
+	c.Verified = true
+	c.Enabled = true
+	c.VerificationCode = ""
+
+	return true
+}
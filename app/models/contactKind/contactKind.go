@@ -0,0 +1,15 @@
+package contactKind
+
+import "errors"
+
+var (
+	ErrContactKindNotFound = errors.New("Contact kind not found")
+)
+
+// Kinds of user contacts
+const (
+	Email    = "email"
+	Telegram = "telegram"
+	Phone    = "phone"
+	Webhook  = "webhook"
+)
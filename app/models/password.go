@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var (
+	// ErrWeakPassword
+	ErrWeakPassword = errors.New("must contain letters and digits")
+	// ErrPasswordLikeLogin
+	ErrPasswordLikeLogin = errors.New("must not contain login or email")
+)
+
+// Password policy
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 100
+)
+
+// Check new password against password policy
+func (user *User) ValidateNewPassword(password string) error {
+	return validation.Validate(password, append([]validation.Rule{validation.Required}, user.passwordRules()...)...)
+}
+
+// Rules of password policy. Password must not contain login or email of the user
+func (user *User) passwordRules() []validation.Rule {
+	return []validation.Rule{
+		validation.Length(PasswordMinLength, PasswordMaxLength),
+		validation.By(func(value interface{}) error {
+			var letter, digit bool
+			for _, r := range value.(string) {
+				letter = letter || unicode.IsLetter(r)
+				digit = digit || unicode.IsDigit(r)
+			}
+			if !letter || !digit {
+				return ErrWeakPassword
+			}
+			return nil
+		}),
+		validation.By(func(value interface{}) error {
+			lower := strings.ToLower(value.(string))
+			name := strings.Split(user.Email, "@")[0]
+			if (user.Login != "" && strings.Contains(lower, strings.ToLower(user.Login))) ||
+				(name != "" && strings.Contains(lower, strings.ToLower(name))) {
+				return ErrPasswordLikeLogin
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Uploaded file metadata. Content is kept in storage under Path
+type Resource struct {
+	ID        int       `json:"id"`
+	Owner     int       `json:"owner"`
+	Path      string    `json:"-"`
+	Filename  string    `json:"filename"`
+	MimeType  string    `json:"mime_type"`
+	Size      int64     `json:"size"`
+	TicketId  uint      `json:"ticket_id,omitempty"`
+	MessageId uint      `json:"message_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate resource
+func (res *Resource) Validate() error {
+	if res.Owner == 0 || res.Path == "" {
+		return errors.New("Empty param: 'owner' or 'path'")
+	}
+
+	return nil
+}
+
+// Fill fields before resource create
+func (res *Resource) BeforeCreate() {
+	res.CreatedAt = time.Now().UTC()
+	res.Filename = CleanFilename(res.Filename)
+}
+
+// Strip directories and control characters from client filename
+func CleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+
+	return name
+}
@@ -4,4 +4,5 @@ package roles
 const (
 	USER = "USER"
 	ADMIN = "ADMIN"
+	SUPPORT = "SUPPORT"
 )
\ No newline at end of file
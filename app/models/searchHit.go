@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Ticket or message found by search. MessageId is 0 if ticket itself
+// matched. Snippet is html: text is escaped and matched words are
+// wrapped in <mark></mark>
+type SearchHit struct {
+	TicketId  uint      `json:"ticket_id"`
+	MessageId uint      `json:"message_id"`
+	Title     string    `json:"title"`
+	Section   string    `json:"section"`
+	Status    string    `json:"status"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	Date      time.Time `json:"date"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
+)
+
+// Ticket section. New tickets get priority of section and are assigned
+// to helper from its pool. Tickets refer to section by name
+type Section struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Priority    string `json:"priority"`
+	Routing     string `json:"routing"`
+	Helpers     []int  `json:"helpers"`
+	// Helper who took the last round robin ticket
+	LastHelper int       `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Validate section fields
+func (s *Section) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&s.Description, validation.Length(0, 1000)),
+		validation.Field(&s.Priority, validation.Required, validation.In(ticketPriority.Low, ticketPriority.Normal, ticketPriority.High, ticketPriority.Urgent)),
+		validation.Field(&s.Routing, validation.Required, validation.In(sectionRouting.RoundRobin, sectionRouting.LeastLoaded)),
+	)
+}
+
+// Fill fields before section create
+func (s *Section) BeforeCreate() {
+	s.CreatedAt = time.Now().UTC()
+	if s.Helpers == nil {
+		s.Helpers = make([]int, 0)
+	}
+}
@@ -0,0 +1,9 @@
+package sectionRouting
+
+// Ways new tickets of section are assigned to helpers of its pool
+const (
+	// Helpers take tickets in turn
+	RoundRobin = "round_robin"
+	// Helper with the fewest not resolved tickets takes ticket
+	LeastLoaded = "least_loaded"
+)
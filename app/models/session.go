@@ -0,0 +1,42 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Session lifetime. Same as refresh token lifetime
+const SessionTTL = 30 * 24 * time.Hour
+
+// Session created on sign in. Tokens issued for the session
+// are valid until session expires or revoked
+type Session struct {
+	ID        string    `json:"id"`
+	UserId    int       `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Revoked   bool      `json:"revoked"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+}
+
+// Fill fields before session create
+func (s *Session) BeforeCreate() error {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+
+	s.ID = hex.EncodeToString(b)
+	s.CreatedAt = time.Now().UTC()
+	s.ExpiresAt = s.CreatedAt.Add(SessionTTL)
+	s.Revoked = false
+
+	return nil
+}
+
+// Check if session is not revoked and not expired
+func (s *Session) IsActive() bool {
+	return !s.Revoked && time.Now().UTC().Before(s.ExpiresAt)
+}
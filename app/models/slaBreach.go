@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Missed SLA target of ticket. One breach per ticket and target
+type SlaBreach struct {
+	ID         int       `json:"id"`
+	TicketId   uint      `json:"ticket_id"`
+	PolicyId   int       `json:"policy_id"`
+	Kind       string    `json:"kind"`
+	DueAt      time.Time `json:"due_at"`
+	BreachedAt time.Time `json:"breached_at"`
+}
+
+// Fill fields before breach create
+func (b *SlaBreach) BeforeCreate() {
+	b.BreachedAt = time.Now().UTC()
+}
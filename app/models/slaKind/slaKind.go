@@ -0,0 +1,7 @@
+package slaKind
+
+// Targets of SLA policy
+const (
+	FirstResponse = "first_response"
+	Resolution    = "resolution"
+)
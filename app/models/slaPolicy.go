@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/inhumanLightBackend/app/models/slaKind"
+)
+
+// Max time of first helper reply and of resolution for tickets of section.
+// Policy with empty section applies to sections without own policy
+type SlaPolicy struct {
+	ID                   int       `json:"id"`
+	Section              string    `json:"section"`
+	FirstResponseMinutes int       `json:"first_response_minutes"`
+	ResolutionMinutes    int       `json:"resolution_minutes"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// Validate policy limits
+func (p *SlaPolicy) Validate() error {
+	return validation.ValidateStruct(
+		p,
+		validation.Field(&p.FirstResponseMinutes, validation.Required, validation.Min(1)),
+		validation.Field(&p.ResolutionMinutes, validation.Required, validation.Min(p.FirstResponseMinutes)),
+	)
+}
+
+// Fill fields before policy create
+func (p *SlaPolicy) BeforeCreate() {
+	p.CreatedAt = time.Now().UTC()
+}
+
+// Time limit of SLA target
+func (p *SlaPolicy) Limit(kind string) time.Duration {
+	if kind == slaKind.FirstResponse {
+		return time.Duration(p.FirstResponseMinutes) * time.Minute
+	}
+
+	return time.Duration(p.ResolutionMinutes) * time.Minute
+}
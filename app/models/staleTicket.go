@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Ticket waiting on customer. Last message of ticket is not written by
+// customer. RemindedAt is the time customer was last reminded about it
+type StaleTicket struct {
+	TicketId      uint       `json:"ticket_id"`
+	Title         string     `json:"title"`
+	Customer      uint       `json:"customer"`
+	Helper        int        `json:"helper"`
+	Status        string     `json:"status"`
+	LastMessageAt time.Time  `json:"last_message_at"`
+	RemindedAt    *time.Time `json:"reminded_at"`
+}
+
+// Check if customer was reminded after last message of ticket
+func (st *StaleTicket) Reminded() bool {
+	return st.RemindedAt != nil && !st.RemindedAt.Before(st.LastMessageAt)
+}
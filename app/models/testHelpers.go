@@ -4,8 +4,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/models/contactKind"
 	"github.com/inhumanLightBackend/app/models/notificationStatus"
 	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
 	"github.com/inhumanLightBackend/app/models/ticketStatus"
 )
 
@@ -13,7 +17,7 @@ func NewTestUser(t *testing.T) *User {
 	return &User{
 		Email: "testUser@gmail.com",
 		Login: "Usernmae",
-		Password: "123456",
+		Password: "qwerty42",
 		Contacts: "Contacts",
 		CreatedAt: time.Now(),
 		IsActive: true,
@@ -26,7 +30,7 @@ func NewTestInactiveUser(t *testing.T) *User {
 	return &User{
 		Email: "testUser@gmail.com",
 		Login: "Usernmae",
-		Password: "123456",
+		Password: "qwerty42",
 		Contacts: "Contacts",
 		CreatedAt: time.Now(),
 		IsActive: false,
@@ -39,7 +43,7 @@ func NewTestUserEmptyFields(t *testing.T) *User {
 	return &User{
 		Email: "testUser@gmail.com",
 		Login: "Usernmae",
-		Password: "123456",
+		Password: "qwerty42",
 		Contacts: "Contacts",
 	}
 }
@@ -82,4 +86,52 @@ func NewTestNotification(t *testing.T) *Notification {
 		Status: notificationStatus.Info,
 		For: 3,
 	}
+}
+
+func NewTestAuditLog(t *testing.T) *AuditLog {
+	return &AuditLog{
+		Action: auditAction.SignIn,
+		Actor: 3,
+		Target: AuditTarget("user", 3),
+		IP: "127.0.0.1",
+		Details: map[string]interface{}{
+			"login": "testUser@gmail.com",
+		},
+	}
+}
+
+func NewTestSession(t *testing.T) *Session {
+	return &Session{
+		UserId: 3,
+		IP: "127.0.0.1",
+		UserAgent: "Go-http-client/1.1",
+	}
+}
+
+func NewTestContact(t *testing.T) *Contact {
+	return &Contact{
+		UserId: 3,
+		Kind: contactKind.Telegram,
+		Value: "708015155",
+	}
+}
+
+
+func NewTestSection(t *testing.T) *Section {
+	return &Section{
+		Name: "Super question",
+		Description: "Questions about everything",
+		Priority: ticketPriority.Normal,
+		Routing: sectionRouting.RoundRobin,
+	}
+}
+
+func NewTestResource(t *testing.T) *Resource {
+	return &Resource{
+		Owner: 3,
+		Path: "2020/04/28/0123456789abcdef",
+		Filename: "screenshot.png",
+		MimeType: "image/png",
+		Size: 1024,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCannedResponse_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response *models.CannedResponse
+		isValid  bool
+	}{
+		{name: "valid", response: &models.CannedResponse{Title: "Hello", Body: "Hi {{user.login}}"}, isValid: true},
+		{name: "macro", response: &models.CannedResponse{Title: "Done", Body: "Fixed", Status: ticketStatus.Resolved}, isValid: true},
+		{name: "empty title", response: &models.CannedResponse{Body: "Hi"}, isValid: false},
+		{name: "empty body", response: &models.CannedResponse{Title: "Hello"}, isValid: false},
+		{name: "unknown status", response: &models.CannedResponse{Title: "Hello", Body: "Hi", Status: "pending"}, isValid: false},
+		{name: "reopen status", response: &models.CannedResponse{Title: "Hello", Body: "Hi", Status: ticketStatus.Opened}, isValid: false},
+		{name: "not closed action", response: &models.CannedResponse{Title: "Hello", Body: "Hi {{user.login"}, isValid: false},
+		{name: "unknown variable", response: &models.CannedResponse{Title: "Hello", Body: "Hi {{customer.login}}"}, isValid: false},
+		{name: "unknown field", response: &models.CannedResponse{Title: "Hello", Body: "Hi {{user.password}}"}, isValid: false},
+		{name: "template action", response: &models.CannedResponse{Title: "Hello", Body: "{{range user}}Hi{{end}}"}, isValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.response.Validate())
+			} else {
+				assert.Error(t, tc.response.Validate())
+			}
+		})
+	}
+}
+
+func TestCannedResponse_Render(t *testing.T) {
+	response := &models.CannedResponse{
+		Body: "Hi {{user.login}}, ticket #{{ticket.id}} \"{{ticket.title}}\" is fixed. {{helper.login}}",
+	}
+	ticket := models.NewTestTicket(t)
+	ticket.ID = 7
+	customer := models.NewTestUser(t)
+	helper := models.NewTestUser(t)
+	helper.Login = "helper"
+
+	text := response.Render(&models.CannedVars{User: customer, Ticket: ticket, Helper: helper})
+	assert.Equal(t, "Hi Usernmae, ticket #7 \"Zagolovok\" is fixed. helper", text)
+
+	text = response.Render(&models.CannedVars{Ticket: ticket})
+	assert.Equal(t, "Hi , ticket #7 \"Zagolovok\" is fixed. ", text)
+}
@@ -0,0 +1,72 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/contactKind"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContact_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		kind    string
+		value   string
+		isValid bool
+	}{
+		{name: "email", kind: contactKind.Email, value: "user@example.com", isValid: true},
+		{name: "telegram", kind: contactKind.Telegram, value: "708015155", isValid: true},
+		{name: "phone", kind: contactKind.Phone, value: "+79991234567", isValid: true},
+		{name: "webhook", kind: contactKind.Webhook, value: "https://hooks.example.com/notify", isValid: true},
+		{name: "telegram username", kind: contactKind.Telegram, value: "@user", isValid: false},
+		{name: "local phone", kind: contactKind.Phone, value: "89991234567", isValid: false},
+		{name: "plain http webhook", kind: contactKind.Webhook, value: "http://hooks.example.com", isValid: false},
+		{name: "empty value", kind: contactKind.Email, value: "", isValid: false},
+		{name: "unknown kind", kind: "pigeon", value: "roof", isValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			contact := &models.Contact{Kind: tc.kind, Value: tc.value}
+			if tc.isValid {
+				assert.NoError(t, contact.Validate())
+			} else {
+				assert.Error(t, contact.Validate())
+			}
+		})
+	}
+}
+
+func TestContact_Verify(t *testing.T) {
+	contact := models.NewTestContact(t)
+	assert.NoError(t, contact.BeforeCreate())
+	assert.Len(t, contact.VerificationCode, 6)
+	assert.False(t, contact.Enabled)
+
+	assert.False(t, contact.Verify("wrong"))
+	code := contact.VerificationCode
+	contact.CodeExpiresAt = time.Now().UTC().Add(-time.Minute)
+	assert.False(t, contact.Verify(code))
+
+	contact.CodeExpiresAt = time.Now().UTC().Add(time.Minute)
+	assert.True(t, contact.Verify(code))
+	assert.True(t, contact.Verified)
+	assert.True(t, contact.Enabled)
+	assert.False(t, contact.Verify(code))
+}
+
+func TestContact_VerifyAttempts(t *testing.T) {
+	contact := models.NewTestContact(t)
+	assert.NoError(t, contact.BeforeCreate())
+
+	// Right code does not help after too many attempts
+	contact.CodeAttempts = models.MaxCodeAttempts + 1
+	assert.False(t, contact.Verify(contact.VerificationCode))
+
+	assert.NoError(t, contact.NewVerificationCode())
+	assert.Equal(t, 0, contact.CodeAttempts)
+	contact.CodeAttempts = models.MaxCodeAttempts
+	assert.True(t, contact.Verify(contact.VerificationCode))
+}
@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicketStatus_CanTransition(t *testing.T) {
+	testCases := []struct {
+		name    string
+		from    string
+		to      string
+		party   string
+		allowed bool
+	}{
+		{name: "helper takes ticket", from: ticketStatus.Opened, to: ticketStatus.InProcess, party: ticketStatus.Helper, allowed: true},
+		{name: "customer takes ticket", from: ticketStatus.Opened, to: ticketStatus.InProcess, party: ticketStatus.Customer, allowed: false},
+		{name: "customer answers", from: ticketStatus.WaitingForCustomer, to: ticketStatus.InProcess, party: ticketStatus.Customer, allowed: true},
+		{name: "customer resolves", from: ticketStatus.InProcess, to: ticketStatus.Resolved, party: ticketStatus.Customer, allowed: false},
+		{name: "system closes waiting", from: ticketStatus.WaitingForCustomer, to: ticketStatus.Closed, party: ticketStatus.System, allowed: true},
+		{name: "system closes in process", from: ticketStatus.InProcess, to: ticketStatus.Closed, party: ticketStatus.System, allowed: false},
+		{name: "customer reopens", from: ticketStatus.Closed, to: ticketStatus.Opened, party: ticketStatus.Customer, allowed: true},
+		{name: "helper reopens", from: ticketStatus.Closed, to: ticketStatus.Opened, party: ticketStatus.Helper, allowed: false},
+		{name: "system returns to queue", from: ticketStatus.InProcess, to: ticketStatus.Opened, party: ticketStatus.System, allowed: true},
+		{name: "helper returns to queue", from: ticketStatus.InProcess, to: ticketStatus.Opened, party: ticketStatus.Helper, allowed: false},
+		{name: "same status", from: ticketStatus.Opened, to: ticketStatus.Opened, party: ticketStatus.Helper, allowed: false},
+		{name: "unknown status", from: ticketStatus.Opened, to: "pending", party: ticketStatus.Helper, allowed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, ticketStatus.CanTransition(tc.from, tc.to, tc.party))
+		})
+	}
+}
+
+func TestTicketStatus_Next(t *testing.T) {
+	assert.Equal(t, []string{ticketStatus.Opened}, ticketStatus.Next(ticketStatus.Closed, ticketStatus.Customer))
+	assert.Empty(t, ticketStatus.Next(ticketStatus.Closed, ticketStatus.Helper))
+	assert.Empty(t, ticketStatus.Next(ticketStatus.Opened, ""))
+}
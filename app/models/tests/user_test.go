@@ -1,9 +1,12 @@
 package models_test
 
 import (
+	"encoding/json"
 	"testing"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,6 +41,24 @@ func TestUser_Validations(t *testing.T) {
 			},
 			isValid: false,
 		},
+		{
+			name: "Empty login",
+			u: func () *models.User {
+				user := models.NewTestUser(t)
+				user.Login = ""
+				return user
+			},
+			isValid: false,
+		},
+		{
+			name: "Login not valid",
+			u: func () *models.User {
+				user := models.NewTestUser(t)
+				user.Login = "user@name"
+				return user
+			},
+			isValid: false,
+		},
 		{
 			name: "Password is empty",
 			u: func () *models.User {
@@ -56,6 +77,24 @@ func TestUser_Validations(t *testing.T) {
 			},
 			isValid: false,
 		},
+		{
+			name: "weak password",
+			u: func () *models.User {
+				user := models.NewTestUser(t)
+				user.Password = "onlyletters"
+				return user
+			},
+			isValid: false,
+		},
+		{
+			name: "password contains login",
+			u: func () *models.User {
+				user := models.NewTestUser(t)
+				user.Password = "Usernmae42"
+				return user
+			},
+			isValid: false,
+		},
 		{
 			name: "with encrypted",
 			u: func () *models.User {
@@ -109,7 +148,7 @@ func TestUser_ChangeActiveStatus(t *testing.T) {
 
 func TestUser_ComparePassword(t *testing.T) {
 	user := models.NewTestUser(t)
-	assert.Equal(t, !user.ComparePassword("123456"), true)
+	assert.Equal(t, !user.ComparePassword("qwerty42"), true)
 }
 
 func TestUser_GenerateNewToken(t *testing.T) {
@@ -119,3 +158,43 @@ func TestUser_GenerateNewToken(t *testing.T) {
 	assert.NotEqual(t, oldToken, user.Token)
 }
 
+
+func TestUser_ApplyPatch(t *testing.T) {
+	user := models.NewTestUser(t)
+	patch := map[string]json.RawMessage{
+		"login": json.RawMessage(`"NewLogin"`),
+	}
+	assert.NoError(t, user.ApplyPatch(patch, roles.USER))
+	assert.Equal(t, "NewLogin", user.Login)
+
+	err := user.ApplyPatch(map[string]json.RawMessage{
+		"user_role": json.RawMessage(`"ADMIN"`),
+		"login":     json.RawMessage(`5`),
+	}, roles.USER)
+	errs, ok := err.(validation.Errors)
+	assert.True(t, ok)
+	assert.Equal(t, models.ErrFieldNotEditable, errs["user_role"])
+	assert.Error(t, errs["login"])
+
+	assert.NoError(t, user.ApplyPatch(map[string]json.RawMessage{
+		"user_role": json.RawMessage(`"ADMIN"`),
+	}, roles.ADMIN))
+	assert.Equal(t, roles.ADMIN, user.Role)
+
+	// Legacy contacts are read only even for admin
+	user.Contacts = "legacy"
+	err = user.ApplyPatch(map[string]json.RawMessage{"contacts": json.RawMessage(`null`)}, roles.ADMIN)
+	errs, ok = err.(validation.Errors)
+	assert.True(t, ok)
+	assert.Equal(t, models.ErrFieldNotEditable, errs["contacts"])
+	assert.Equal(t, "legacy", user.Contacts)
+}
+
+func TestUser_ValidateNewPassword(t *testing.T) {
+	user := models.NewTestUser(t)
+	assert.NoError(t, user.ValidateNewPassword("correct horse 42"))
+	assert.Error(t, user.ValidateNewPassword("short1"))
+	assert.Error(t, user.ValidateNewPassword("onlyletters"))
+	assert.Error(t, user.ValidateNewPassword("12345678"))
+	assert.Error(t, user.ValidateNewPassword("testuser2020"))
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
 	"github.com/inhumanLightBackend/app/models/ticketStatus"
 )
 
@@ -16,6 +17,11 @@ type Ticket struct {
 	Helper      int       `json:"helper"`
 	Created_at  time.Time `json:"created_at"`
 	Status      string    `json:"status"`
+	Priority    string    `json:"priority"`
+	// Time of last message, assignment or status change
+	LastActivityAt time.Time `json:"last_activity_at"`
+	// Metadata of files attached on ticket creation
+	Attachments []*Resource `json:"attachments,omitempty"`
 }
 
 // Fill fields before ticket create
@@ -23,4 +29,8 @@ func (t *Ticket) BeforeCreate() {
 	t.Helper = -1
 	t.Created_at = time.Now().UTC()
 	t.Status = ticketStatus.Opened
+	t.LastActivityAt = t.Created_at
+	if t.Priority == "" {
+		t.Priority = ticketPriority.Normal
+	}
 }
@@ -0,0 +1,10 @@
+package ticketAction
+
+// Changes of ticket helper written to ticket history
+const (
+	Accept   = "accept"
+	Assign   = "assign"
+	Unassign = "unassign"
+	// Ticket routed to helper of section pool on create
+	AutoAssign = "auto_assign"
+)
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Change of ticket helper. Helper -1 means ticket is not assigned
+type TicketHistory struct {
+	ID         int       `json:"id"`
+	TicketId   uint      `json:"ticket_id"`
+	Actor      int       `json:"actor"`
+	Action     string    `json:"action"`
+	FromHelper int       `json:"from_helper"`
+	ToHelper   int       `json:"to_helper"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Fill fields before history entry create
+func (h *TicketHistory) BeforeCreate() {
+	h.CreatedAt = time.Now().UTC()
+}
@@ -9,6 +9,8 @@ type TicketMessage struct {
 	TicketId uint      `json:"ticket_id"`
 	Message  string    `json:"message"`
 	Date     time.Time `json:"date"`
+	// Metadata of attached files
+	Attachments []*Resource `json:"attachments"`
 }
 
 // Fill fields before message create
@@ -0,0 +1,23 @@
+package ticketPriority
+
+// Ticket priority
+const (
+	Low    = "low"
+	Normal = "normal"
+	High   = "high"
+	Urgent = "urgent"
+)
+
+// All priorities from lowest to highest
+var All = []string{Low, Normal, High, Urgent}
+
+// Check if priority is known
+func IsValid(priority string) bool {
+	for _, item := range All {
+		if item == priority {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,7 +2,16 @@ package ticketStatus
 
 // Ticket proccessing status
 const (
-	Opened    = "opened"
-	InProcess = "in process"
-	Closed    = "closed"
+	Opened             = "opened"
+	InProcess          = "in process"
+	WaitingForCustomer = "waiting for customer"
+	Resolved           = "resolved"
+	Closed             = "closed"
 )
+
+// All statuses in processing order
+var All = []string{Opened, InProcess, WaitingForCustomer, Resolved, Closed}
+
+// Statuses of tickets waiting on customer. System closes such tickets
+// if customer does not reply
+var AwaitingCustomer = []string{WaitingForCustomer, Resolved}
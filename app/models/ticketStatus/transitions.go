@@ -0,0 +1,70 @@
+package ticketStatus
+
+// Parties of ticket allowed to change its status
+const (
+	Customer = "customer"
+	Helper   = "helper"
+	System   = "system"
+)
+
+// Allowed transitions. Current status to new status and parties allowed to set it.
+// Closed and resolved tickets are reopened by customer. System takes opened
+// ticket in process when it is assigned and returns it when it is unassigned
+var transitions = map[string]map[string][]string{
+	Opened: {
+		InProcess: {Helper, System},
+		Closed:    {Customer, Helper},
+	},
+	InProcess: {
+		Opened:             {System},
+		WaitingForCustomer: {Helper},
+		Resolved:           {Helper},
+		Closed:             {Customer, Helper},
+	},
+	WaitingForCustomer: {
+		InProcess: {Customer, Helper},
+		Resolved:  {Helper},
+		Closed:    {Customer, Helper, System},
+	},
+	Resolved: {
+		InProcess: {Customer},
+		Closed:    {Customer, Helper, System},
+	},
+	Closed: {
+		Opened: {Customer},
+	},
+}
+
+// Check if status is known
+func IsValid(status string) bool {
+	for _, item := range All {
+		if item == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if party can move ticket from one status to another
+func CanTransition(from, to, party string) bool {
+	for _, p := range transitions[from][to] {
+		if p == party {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Statuses party can move ticket to from current status
+func Next(from, party string) []string {
+	next := make([]string, 0)
+	for _, to := range All {
+		if CanTransition(from, to, party) {
+			next = append(next, to)
+		}
+	}
+
+	return next
+}
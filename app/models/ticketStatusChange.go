@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Change of ticket status. Actor 0 is automation
+type TicketStatusChange struct {
+	ID        int       `json:"id"`
+	TicketId  uint      `json:"ticket_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Actor     int       `json:"actor"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Fill fields before status change create
+func (c *TicketStatusChange) BeforeCreate() {
+	c.CreatedAt = time.Now().UTC()
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/inhumanLightBackend/app/models/slaKind"
+)
+
+// Times of ticket SLA is measured by. First response is the first message
+// not written by customer, resolution is the first move to resolved or closed
+type TicketTimeline struct {
+	TicketId        uint       `json:"ticket_id"`
+	Section         string     `json:"section"`
+	CreatedAt       time.Time  `json:"created_at"`
+	FirstResponseAt *time.Time `json:"first_response_at"`
+	ResolvedAt      *time.Time `json:"resolved_at"`
+}
+
+// Time target of kind was reached. Nil if not reached yet. Ticket
+// resolved without reply is responded at resolution
+func (tl *TicketTimeline) DoneAt(kind string) *time.Time {
+	if kind == slaKind.FirstResponse && tl.FirstResponseAt != nil {
+		return tl.FirstResponseAt
+	}
+
+	return tl.ResolvedAt
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -12,6 +14,12 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Login starts with letter or digit and contains latin letters, digits, '_', '.' and '-'
+var loginFormat = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{2,31}$`)
+
+// ErrLoginFormat
+var ErrLoginFormat = errors.New("must be 3-32 latin letters, digits, '_', '.' or '-'")
+
 // User model
 type User struct {
 	ID                int       `json:"id"`
@@ -21,7 +29,7 @@ type User struct {
 	EncryptedPassword string    `json:"-"`
 	CreatedAt         time.Time `json:"registration_date"`
 	Token             string    `json:"api_token"`
-	Contacts          string    `json:"contacts"`
+	Contacts          string    `json:"contacts"` // legacy free-form contacts, see Contact
 	Role              string    `json:"user_role"`
 	IsActive          bool      `json:"-"`
 }
@@ -30,11 +38,22 @@ type User struct {
 func (user *User) Validate() error {
 	return validation.ValidateStruct(
 		user,
+		validation.Field(&user.Login, validation.By(func(value interface{}) error {
+			return ValidateLogin(value.(string))
+		})),
 		validation.Field(&user.Email, validation.Required, is.Email),
-		validation.Field(&user.Password, validation.By(requiredIf(user.EncryptedPassword == "")), validation.Length(6, 100)),
+		validation.Field(&user.Password,
+			validation.By(requiredIf(user.EncryptedPassword == "")),
+			validation.When(user.Password != "", user.passwordRules()...),
+		),
 	)
 }
 
+// Validate login format
+func ValidateLogin(login string) error {
+	return validation.Validate(login, validation.Required, validation.Match(loginFormat).Error(ErrLoginFormat.Error()))
+}
+
 // Fill fields before user create
 func (user *User) BeforeCreate() error {
 	if len(user.Password) > 0 {
@@ -77,6 +96,18 @@ func (user *User) ChangeActiveStatus(newStatus bool) {
 	user.IsActive = newStatus
 }
 
+// Remove personal data from user. Id is kept as pseudonym,
+// so financial records stay linked to the account
+func (user *User) Anonymize() {
+	user.Login = fmt.Sprintf("deleted_%d", user.ID)
+	user.Email = fmt.Sprintf("deleted-%d@deleted.invalid", user.ID)
+	user.Password = ""
+	user.EncryptedPassword = ""
+	user.Contacts = ""
+	user.GenerateNewToken()
+	user.IsActive = false
+}
+
 // Compare password of user and request
 func (user *User) ComparePassword(pwd string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(user.EncryptedPassword), []byte(pwd)) == nil
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/inhumanLightBackend/app/models/roles"
+)
+
+var (
+	// ErrFieldNotEditable
+	ErrFieldNotEditable = errors.New("Field is not editable")
+	// ErrUnknownField
+	ErrUnknownField = errors.New("Unknown field")
+)
+
+// Setter of one user field. Value is raw json, null clears the field
+type userFieldSetter func(user *User, value json.RawMessage) error
+
+var userFieldSetters = map[string]userFieldSetter{
+	"login": stringSetter(func(u *User) *string { return &u.Login }),
+	"email": stringSetter(func(u *User) *string { return &u.Email }),
+	"user_role": func(user *User, value json.RawMessage) error {
+		role := ""
+		if err := json.Unmarshal(value, &role); err != nil {
+			return errors.New("must be a string")
+		}
+		if role != roles.USER && role != roles.SUPPORT && role != roles.ADMIN {
+			return errors.New("unknown role")
+		}
+		user.Role = role
+		return nil
+	},
+	"is_active": func(user *User, value json.RawMessage) error {
+		var active *bool
+		if err := json.Unmarshal(value, &active); err != nil || active == nil {
+			return errors.New("must be a boolean")
+		}
+		user.IsActive = *active
+		return nil
+	},
+}
+
+// Fields of user json which are never changed through patch. Legacy
+// contacts are only shown, contacts are managed with Contact now
+var userReadOnlyFields = []string{"id", "password", "registration_date", "api_token", "contacts"}
+
+// Fields which can be changed through patch by editor with role
+var userEditableFields = map[string][]string{
+	roles.USER:  {"login", "email"},
+	roles.ADMIN: {"login", "email", "user_role", "is_active"},
+}
+
+// Check if editor with role can change user field
+func UserFieldEditable(role, field string) bool {
+	for _, f := range userEditableFields[role] {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isReadOnlyUserField(field string) bool {
+	for _, f := range userReadOnlyFields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply JSON Merge Patch to user. Null clears the field.
+// Returns validation.Errors with message per field
+func (user *User) ApplyPatch(patch map[string]json.RawMessage, role string) error {
+	errs := validation.Errors{}
+	for field, value := range patch {
+		setter, ok := userFieldSetters[field]
+		if !ok && !isReadOnlyUserField(field) {
+			errs[field] = ErrUnknownField
+			continue
+		}
+		if !ok || !UserFieldEditable(role, field) {
+			errs[field] = ErrFieldNotEditable
+			continue
+		}
+		if err := setter(user, value); err != nil {
+			errs[field] = err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return user.Validate()
+}
+
+// Setter for string field, null sets empty string
+func stringSetter(field func(u *User) *string) userFieldSetter {
+	return func(user *User, value json.RawMessage) error {
+		var s *string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return errors.New("must be a string")
+		}
+		if s == nil {
+			*field(user) = ""
+			return nil
+		}
+		*field(user) = *s
+		return nil
+	}
+}
@@ -0,0 +1,33 @@
+package realtime
+
+import "encoding/json"
+
+// Types of ticket events
+const (
+	EventMessage = "message"
+	EventStatus  = "status"
+	EventTyping  = "typing"
+)
+
+// Event of ticket pushed to subscribers of the ticket
+type Event struct {
+	Type     string      `json:"type"`
+	TicketId uint        `json:"ticket_id"`
+	Actor    int         `json:"actor"`
+	Data     interface{} `json:"data,omitempty"`
+}
+
+// Encode event. Data of event is dropped if encoded event is longer
+// than limit, subscribers have to fetch it by api. Zero limit keeps data
+func (e *Event) Encode(limit int) ([]byte, error) {
+	payload, err := json.Marshal(e)
+	if err != nil || limit == 0 || len(payload) <= limit {
+		return payload, err
+	}
+
+	return json.Marshal(&Event{
+		Type:     e.Type,
+		TicketId: e.TicketId,
+		Actor:    e.Actor,
+	})
+}
@@ -0,0 +1,117 @@
+package realtime
+
+import "sync"
+
+// Size of subscription buffer. Events are dropped for subscriber
+// which does not read them
+const subscriptionBuffer = 64
+
+// Bus delivers ticket events to subscribers
+type Bus interface {
+	Publish(*Event) error
+	Subscribe() *Subscription
+}
+
+// Hub fans out events to subscribers of current server instance
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[uint]map[*Subscription]bool
+}
+
+// Create new hub
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[uint]map[*Subscription]bool),
+	}
+}
+
+// Deliver event to all subscribers of its ticket
+func (h *Hub) Publish(e *Event) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers[e.TicketId] {
+		select {
+		case sub.events <- e:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Create subscription without tickets
+func (h *Hub) Subscribe() *Subscription {
+	return &Subscription{
+		hub:     h,
+		events:  make(chan *Event, subscriptionBuffer),
+		tickets: make(map[uint]bool),
+	}
+}
+
+// Subscription to events of several tickets
+type Subscription struct {
+	hub     *Hub
+	events  chan *Event
+	tickets map[uint]bool
+	closed  bool
+}
+
+// Channel of events. Closed after subscription is closed
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Receive events of ticket
+func (s *Subscription) Join(ticketId uint) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	if s.hub.subscribers[ticketId] == nil {
+		s.hub.subscribers[ticketId] = make(map[*Subscription]bool)
+	}
+	s.hub.subscribers[ticketId][s] = true
+	s.tickets[ticketId] = true
+}
+
+// Stop receiving events of ticket
+func (s *Subscription) Leave(ticketId uint) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.leave(ticketId)
+}
+
+// Check if subscription receives events of ticket
+func (s *Subscription) Joined(ticketId uint) bool {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+
+	return s.tickets[ticketId]
+}
+
+// Leave all tickets and close events channel
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	for ticketId := range s.tickets {
+		s.leave(ticketId)
+	}
+	s.closed = true
+	close(s.events)
+}
+
+func (s *Subscription) leave(ticketId uint) {
+	delete(s.tickets, ticketId)
+	delete(s.hub.subscribers[ticketId], s)
+	if len(s.hub.subscribers[ticketId]) == 0 {
+		delete(s.hub.subscribers, ticketId)
+	}
+}
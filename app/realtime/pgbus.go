@@ -0,0 +1,79 @@
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// Postgres channel of ticket events
+const Channel = "ticket_events"
+
+// Max length of notification payload. Postgres limit is 8000 bytes
+const maxPayload = 7900
+
+// PgBus keeps several server instances in sync. Events are published with
+// NOTIFY and delivered to local subscribers by LISTEN of every instance,
+// including the publishing one
+type PgBus struct {
+	*Hub
+	db *sql.DB
+}
+
+// Create new postgres bus
+func NewPgBus(db *sql.DB) *PgBus {
+	return &PgBus{
+		Hub: NewHub(),
+		db:  db,
+	}
+}
+
+// Send event to all server instances
+func (b *PgBus) Publish(e *Event) error {
+	payload, err := e.Encode(maxPayload)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.db.Exec("select pg_notify($1, $2)", Channel, string(payload))
+	return err
+}
+
+// Listen to events of all server instances until context is done.
+// Events sent while connection is lost are not delivered
+func (b *PgBus) Listen(ctx context.Context, databaseURL string, logger *logrus.Logger) error {
+	listener := pq.NewListener(databaseURL, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.WithError(err).Error("Ticket events listener")
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(Channel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// Nil notification is sent after reconnect
+			if n == nil {
+				continue
+			}
+			e := &Event{}
+			if err := json.Unmarshal([]byte(n.Extra), e); err != nil {
+				logger.WithError(err).Error("Invalid ticket event")
+				continue
+			}
+			b.Hub.Publish(e)
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package realtime_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/realtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	hub := realtime.NewHub()
+	first, second := hub.Subscribe(), hub.Subscribe()
+	first.Join(1)
+	second.Join(1)
+	second.Join(2)
+	assert.True(t, second.Joined(2))
+
+	hub.Publish(&realtime.Event{Type: realtime.EventMessage, TicketId: 1})
+	hub.Publish(&realtime.Event{Type: realtime.EventStatus, TicketId: 2})
+	assert.Equal(t, realtime.EventMessage, (<-first.Events()).Type)
+	assert.Equal(t, realtime.EventMessage, (<-second.Events()).Type)
+	assert.Equal(t, realtime.EventStatus, (<-second.Events()).Type)
+	assert.Empty(t, first.Events())
+
+	first.Leave(1)
+	assert.False(t, first.Joined(1))
+	hub.Publish(&realtime.Event{Type: realtime.EventTyping, TicketId: 1})
+	assert.Empty(t, first.Events())
+	assert.Equal(t, realtime.EventTyping, (<-second.Events()).Type)
+
+	second.Close()
+	second.Close()
+	_, ok := <-second.Events()
+	assert.False(t, ok)
+	second.Join(1)
+	hub.Publish(&realtime.Event{Type: realtime.EventTyping, TicketId: 1})
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := realtime.NewHub()
+	sub := hub.Subscribe()
+	sub.Join(1)
+	for i := 0; i < 100; i++ {
+		hub.Publish(&realtime.Event{Type: realtime.EventTyping, TicketId: 1})
+	}
+	assert.Equal(t, 64, len(sub.Events()))
+}
+
+func TestEvent_Encode(t *testing.T) {
+	e := &realtime.Event{Type: realtime.EventMessage, TicketId: 1, Actor: 2, Data: strings.Repeat("a", 100)}
+	payload, err := e.Encode(0)
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), "data")
+
+	payload, err = e.Encode(50)
+	assert.NoError(t, err)
+	decoded := &realtime.Event{}
+	assert.NoError(t, json.Unmarshal(payload, decoded))
+	assert.Nil(t, decoded.Data)
+	assert.Equal(t, uint(1), decoded.TicketId)
+	assert.Equal(t, 2, decoded.Actor)
+}
@@ -0,0 +1,53 @@
+package routing
+
+import (
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+)
+
+// Helper of section pool who takes the next ticket. Available maps helpers
+// who can take tickets to count of their not resolved tickets.
+// Returns -1 if nobody from pool is available
+func PickHelper(section *models.Section, available map[int]int) int {
+	if section.Routing == sectionRouting.LeastLoaded {
+		return leastLoaded(section.Helpers, available)
+	}
+
+	return nextInTurn(section.Helpers, section.LastHelper, available)
+}
+
+// First available helper after the last one, pool is walked in a circle
+func nextInTurn(pool []int, last int, available map[int]int) int {
+	start := 0
+	for i, id := range pool {
+		if id == last {
+			start = i + 1
+			break
+		}
+	}
+
+	for i := 0; i < len(pool); i++ {
+		id := pool[(start+i)%len(pool)]
+		if _, ok := available[id]; ok {
+			return id
+		}
+	}
+
+	return -1
+}
+
+// Available helper with the fewest tickets. Ties go to the one listed first in pool
+func leastLoaded(pool []int, available map[int]int) int {
+	picked, min := -1, 0
+	for _, id := range pool {
+		load, ok := available[id]
+		if !ok {
+			continue
+		}
+		if picked == -1 || load < min {
+			picked, min = id, load
+		}
+	}
+
+	return picked
+}
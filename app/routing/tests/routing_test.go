@@ -0,0 +1,80 @@
+package routing_test
+
+import (
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/sectionRouting"
+	"github.com/inhumanLightBackend/app/routing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickHelper(t *testing.T) {
+	testCases := []struct {
+		name      string
+		section   *models.Section
+		available map[int]int
+		expected  int
+	}{
+		{
+			name:      "round robin first turn",
+			section:   &models.Section{Routing: sectionRouting.RoundRobin, Helpers: []int{3, 5, 7}},
+			available: map[int]int{3: 0, 5: 0, 7: 0},
+			expected:  3,
+		},
+		{
+			name:      "round robin next after last",
+			section:   &models.Section{Routing: sectionRouting.RoundRobin, Helpers: []int{3, 5, 7}, LastHelper: 5},
+			available: map[int]int{3: 0, 5: 0, 7: 0},
+			expected:  7,
+		},
+		{
+			name:      "round robin wraps",
+			section:   &models.Section{Routing: sectionRouting.RoundRobin, Helpers: []int{3, 5, 7}, LastHelper: 7},
+			available: map[int]int{3: 0, 5: 0, 7: 0},
+			expected:  3,
+		},
+		{
+			name:      "round robin skips unavailable",
+			section:   &models.Section{Routing: sectionRouting.RoundRobin, Helpers: []int{3, 5, 7}, LastHelper: 3},
+			available: map[int]int{3: 0, 7: 0},
+			expected:  7,
+		},
+		{
+			name:      "round robin last removed from pool",
+			section:   &models.Section{Routing: sectionRouting.RoundRobin, Helpers: []int{3, 5}, LastHelper: 9},
+			available: map[int]int{3: 0, 5: 0},
+			expected:  3,
+		},
+		{
+			name:      "least loaded",
+			section:   &models.Section{Routing: sectionRouting.LeastLoaded, Helpers: []int{3, 5, 7}},
+			available: map[int]int{3: 4, 5: 1, 7: 2},
+			expected:  5,
+		},
+		{
+			name:      "least loaded tie",
+			section:   &models.Section{Routing: sectionRouting.LeastLoaded, Helpers: []int{3, 5, 7}},
+			available: map[int]int{5: 2, 7: 2},
+			expected:  5,
+		},
+		{
+			name:      "nobody available",
+			section:   &models.Section{Routing: sectionRouting.LeastLoaded, Helpers: []int{3}},
+			available: map[int]int{},
+			expected:  -1,
+		},
+		{
+			name:      "empty pool",
+			section:   &models.Section{Routing: sectionRouting.RoundRobin},
+			available: map[int]int{},
+			expected:  -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, routing.PickHelper(tc.section, tc.available))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package sla
+
+import (
+	"sort"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/slaKind"
+)
+
+// States of SLA target
+const (
+	Met      = "met"
+	Breached = "breached"
+	Pending  = "pending"
+)
+
+// Targets checked for every ticket
+var Kinds = []string{slaKind.FirstResponse, slaKind.Resolution}
+
+// Policy of section. Default policy with empty section is used if
+// section has no own one. Nil if there is no policy
+func PolicyFor(policies []*models.SlaPolicy, section string) *models.SlaPolicy {
+	var fallback *models.SlaPolicy
+	for _, policy := range policies {
+		if policy.Section == section {
+			return policy
+		}
+		if policy.Section == "" {
+			fallback = policy
+		}
+	}
+
+	return fallback
+}
+
+// State of ticket target and time it is due. Target not reached in time
+// is breached even before it is reached
+func Check(policy *models.SlaPolicy, timeline *models.TicketTimeline, kind string, now time.Time) (string, time.Time) {
+	due := timeline.CreatedAt.Add(policy.Limit(kind))
+	if done := timeline.DoneAt(kind); done != nil {
+		if done.After(due) {
+			return Breached, due
+		}
+		return Met, due
+	}
+	if now.After(due) {
+		return Breached, due
+	}
+
+	return Pending, due
+}
+
+// Compliance of one target
+type Stats struct {
+	Met      int `json:"met"`
+	Breached int `json:"breached"`
+	Pending  int `json:"pending"`
+	// Percent of met targets among not pending ones, 100 if all are pending
+	Compliance float64 `json:"compliance"`
+}
+
+func (s *Stats) add(state string) {
+	switch state {
+	case Met:
+		s.Met++
+	case Breached:
+		s.Breached++
+	default:
+		s.Pending++
+	}
+
+	s.Compliance = 100
+	if finished := s.Met + s.Breached; finished > 0 {
+		s.Compliance = float64(s.Met) * 100 / float64(finished)
+	}
+}
+
+// Compliance of tickets of one section
+type SectionReport struct {
+	Section       string `json:"section"`
+	PolicyId      int    `json:"policy_id,omitempty"`
+	Tickets       int    `json:"tickets"`
+	FirstResponse Stats  `json:"first_response"`
+	Resolution    Stats  `json:"resolution"`
+	// Mean time to first response of answered tickets
+	AvgFirstResponseMinutes float64 `json:"avg_first_response_minutes"`
+
+	responded    int
+	responseTime time.Duration
+}
+
+func (sr *SectionReport) add(policy *models.SlaPolicy, timeline *models.TicketTimeline, now time.Time) {
+	sr.Tickets++
+	state, _ := Check(policy, timeline, slaKind.FirstResponse, now)
+	sr.FirstResponse.add(state)
+	state, _ = Check(policy, timeline, slaKind.Resolution, now)
+	sr.Resolution.add(state)
+
+	if timeline.FirstResponseAt != nil {
+		sr.responded++
+		sr.responseTime += timeline.FirstResponseAt.Sub(timeline.CreatedAt)
+		sr.AvgFirstResponseMinutes = sr.responseTime.Minutes() / float64(sr.responded)
+	}
+}
+
+// Compliance report by sections and in total. Tickets of sections
+// without policy are not counted
+type Report struct {
+	Sections []*SectionReport `json:"sections"`
+	Total    *SectionReport   `json:"total"`
+}
+
+// Build compliance report of tickets
+func BuildReport(policies []*models.SlaPolicy, timelines []*models.TicketTimeline, now time.Time) *Report {
+	report := &Report{
+		Sections: make([]*SectionReport, 0),
+		Total:    &SectionReport{},
+	}
+	sections := make(map[string]*SectionReport)
+	for _, timeline := range timelines {
+		policy := PolicyFor(policies, timeline.Section)
+		if policy == nil {
+			continue
+		}
+
+		section, ok := sections[timeline.Section]
+		if !ok {
+			section = &SectionReport{Section: timeline.Section, PolicyId: policy.ID}
+			sections[timeline.Section] = section
+			report.Sections = append(report.Sections, section)
+		}
+		section.add(policy, timeline, now)
+		report.Total.add(policy, timeline, now)
+	}
+	sort.Slice(report.Sections, func(i, j int) bool {
+		return report.Sections[i].Section < report.Sections[j].Section
+	})
+
+	return report
+}
@@ -0,0 +1,91 @@
+package sla_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/slaKind"
+	"github.com/inhumanLightBackend/app/sla"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyFor(t *testing.T) {
+	fallback := &models.SlaPolicy{ID: 1, Section: ""}
+	billing := &models.SlaPolicy{ID: 2, Section: "Billing"}
+
+	assert.Equal(t, billing, sla.PolicyFor([]*models.SlaPolicy{fallback, billing}, "Billing"))
+	assert.Equal(t, fallback, sla.PolicyFor([]*models.SlaPolicy{fallback, billing}, "Other"))
+	assert.Nil(t, sla.PolicyFor([]*models.SlaPolicy{billing}, "Other"))
+}
+
+func TestCheck(t *testing.T) {
+	created := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		t := created.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+	policy := &models.SlaPolicy{FirstResponseMinutes: 60, ResolutionMinutes: 240}
+
+	testCases := []struct {
+		name     string
+		timeline *models.TicketTimeline
+		kind     string
+		now      time.Time
+		state    string
+	}{
+		{name: "responded in time", timeline: &models.TicketTimeline{FirstResponseAt: at(30)}, kind: slaKind.FirstResponse, now: *at(300), state: sla.Met},
+		{name: "responded late", timeline: &models.TicketTimeline{FirstResponseAt: at(90)}, kind: slaKind.FirstResponse, now: *at(90), state: sla.Breached},
+		{name: "waiting for response", timeline: &models.TicketTimeline{}, kind: slaKind.FirstResponse, now: *at(59), state: sla.Pending},
+		{name: "response overdue", timeline: &models.TicketTimeline{}, kind: slaKind.FirstResponse, now: *at(61), state: sla.Breached},
+		{name: "resolved without response", timeline: &models.TicketTimeline{ResolvedAt: at(45)}, kind: slaKind.FirstResponse, now: *at(300), state: sla.Met},
+		{name: "resolved in time", timeline: &models.TicketTimeline{FirstResponseAt: at(90), ResolvedAt: at(200)}, kind: slaKind.Resolution, now: *at(300), state: sla.Met},
+		{name: "resolution overdue", timeline: &models.TicketTimeline{FirstResponseAt: at(30)}, kind: slaKind.Resolution, now: *at(241), state: sla.Breached},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.timeline.CreatedAt = created
+			state, due := sla.Check(policy, tc.timeline, tc.kind, tc.now)
+			assert.Equal(t, tc.state, state)
+			assert.Equal(t, created.Add(policy.Limit(tc.kind)), due)
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	now := time.Now().UTC()
+	created := now.Add(-10 * time.Hour)
+	at := func(minutes int) *time.Time {
+		t := created.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+	policies := []*models.SlaPolicy{
+		{ID: 1, Section: "", FirstResponseMinutes: 60, ResolutionMinutes: 240},
+		{ID: 2, Section: "Billing", FirstResponseMinutes: 30, ResolutionMinutes: 120},
+	}
+	timelines := []*models.TicketTimeline{
+		{TicketId: 1, Section: "Billing", CreatedAt: created, FirstResponseAt: at(20), ResolvedAt: at(100)},
+		{TicketId: 2, Section: "Billing", CreatedAt: created, FirstResponseAt: at(40)},
+		{TicketId: 3, Section: "Other", CreatedAt: now, FirstResponseAt: nil},
+	}
+
+	report := sla.BuildReport(policies, timelines, now)
+	assert.Len(t, report.Sections, 2)
+
+	billing := report.Sections[0]
+	assert.Equal(t, "Billing", billing.Section)
+	assert.Equal(t, 2, billing.PolicyId)
+	assert.Equal(t, 2, billing.Tickets)
+	assert.Equal(t, sla.Stats{Met: 1, Breached: 1, Compliance: 50}, billing.FirstResponse)
+	assert.Equal(t, sla.Stats{Met: 1, Breached: 1, Compliance: 50}, billing.Resolution)
+	assert.Equal(t, float64(30), billing.AvgFirstResponseMinutes)
+
+	other := report.Sections[1]
+	assert.Equal(t, "Other", other.Section)
+	assert.Equal(t, 1, other.PolicyId)
+	assert.Equal(t, sla.Stats{Pending: 1, Compliance: 100}, other.FirstResponse)
+
+	assert.Equal(t, 3, report.Total.Tickets)
+	assert.Equal(t, 1, report.Total.FirstResponse.Pending)
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidKey
+var ErrInvalidKey = errors.New("Invalid storage key")
+
+// Local keeps files on disk under root directory
+type Local struct {
+	root string
+}
+
+// Create local storage. Root directory is created if not exists
+func NewLocal(root string) (*Local, error) {
+	if err := os.MkdirAll(root, 0750); err != nil {
+		return nil, err
+	}
+
+	return &Local{
+		root: root,
+	}, nil
+}
+
+// Save content under key
+func (l *Local) Save(ctx context.Context, key string, r io.Reader) (int64, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(p)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Open content by key
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+// Delete content by key
+func (l *Local) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Resolve key to path inside root
+func (l *Local) path(key string) (string, error) {
+	p := filepath.Join(l.root, filepath.FromSlash(key))
+	if key == "" || !strings.HasPrefix(p, filepath.Clean(l.root)+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+
+	return p, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// Memory keeps files in memory. Used in tests and as default storage
+type Memory struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// Create memory storage
+func NewMemory() *Memory {
+	return &Memory{
+		files: make(map[string][]byte),
+	}
+}
+
+// Save content under key
+func (m *Memory) Save(ctx context.Context, key string, r io.Reader) (int64, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	m.files[key] = data
+	m.mu.Unlock()
+
+	return int64(len(data)), nil
+}
+
+// Open content by key
+func (m *Memory) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	data, ok := m.files[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete content by key
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	delete(m.files, key)
+	m.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"time"
+)
+
+// ErrNotFound
+var ErrNotFound = errors.New("File not found")
+
+// Storage of uploaded files content. Key is a relative slash separated path
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Generate new unique key. Files are grouped by upload date
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return path.Join(time.Now().UTC().Format("2006/01/02"), hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,50 @@
+package storage_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resources")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := storage.NewLocal(dir)
+	assert.NoError(t, err)
+	testStorage(t, s)
+
+	_, err = s.Save(context.Background(), "../outside", strings.NewReader("data"))
+	assert.EqualError(t, err, storage.ErrInvalidKey.Error())
+}
+
+func TestMemory(t *testing.T) {
+	testStorage(t, storage.NewMemory())
+}
+
+func testStorage(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	key, err := storage.NewKey()
+	assert.NoError(t, err)
+
+	n, err := s.Save(ctx, key, strings.NewReader("file content"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), n)
+
+	r, err := s.Open(ctx, key)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "file content", string(data))
+
+	assert.NoError(t, s.Delete(ctx, key))
+	_, err = s.Open(ctx, key)
+	assert.EqualError(t, err, storage.ErrNotFound.Error())
+}
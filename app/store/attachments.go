@@ -0,0 +1,26 @@
+package store
+
+import "github.com/inhumanLightBackend/app/models"
+
+// Fill attachments of ticket messages from resources attached to ticket
+func AttachToMessages(resources ResourceRepository, ticketId uint, messages []*models.TicketMessage) error {
+	attached, err := resources.FindByTicket(ticketId)
+	if err != nil {
+		return err
+	}
+
+	byMessage := make(map[uint][]*models.Resource)
+	for _, res := range attached {
+		if res.MessageId != 0 {
+			byMessage[res.MessageId] = append(byMessage[res.MessageId], res)
+		}
+	}
+	for _, message := range messages {
+		message.Attachments = byMessage[message.ID]
+		if message.Attachments == nil {
+			message.Attachments = make([]*models.Resource, 0)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor
+var ErrInvalidCursor = errors.New("Invalid cursor")
+
+// Position in sorted list. Value of sort field and id of the last item
+type Cursor struct {
+	Value string `json:"v"`
+	Id    int    `json:"id"`
+}
+
+// Encode cursor to opaque string
+func EncodeCursor(value string, id int) string {
+	data, _ := json.Marshal(&Cursor{Value: value, Id: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode cursor from string produced by EncodeCursor
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
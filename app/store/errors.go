@@ -7,4 +7,26 @@ var (
 	ErrRecordNotFound = errors.New("Record not found")
 	// ErrProccessingStatusNotFound
 	ErrProccessingStatusNotFound = errors.New("Proccessing status not found")
+	// ErrInsufficientFunds
+	ErrInsufficientFunds = errors.New("Insufficient funds")
+	// ErrEmailTaken
+	ErrEmailTaken = errors.New("Email already taken")
+	// ErrUsernameTaken
+	ErrUsernameTaken = errors.New("Username already taken")
+	// ErrDeletionPending
+	ErrDeletionPending = errors.New("Account deletion already requested")
+	// ErrContactExists
+	ErrContactExists = errors.New("Contact already exists")
+	// ErrSectionExists
+	ErrSectionExists = errors.New("Section already exists")
+	// ErrSlaPolicyExists
+	ErrSlaPolicyExists = errors.New("SLA policy for section already exists")
+	// ErrBreachRegistered
+	ErrBreachRegistered = errors.New("SLA breach already registered")
+	// ErrTicketAssigned
+	ErrTicketAssigned = errors.New("Ticket already assigned")
+	// ErrResourceAttached
+	ErrResourceAttached = errors.New("File is attached to ticket")
+	// ErrStatusChanged
+	ErrStatusChanged = errors.New("Ticket status was changed by someone else")
 )
\ No newline at end of file
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+)
+
+// Filter for audit log. Zero values are ignored. BeforeId selects entries
+// older than entry with this id, so pages can be taken by last id seen
+type AuditFilter struct {
+	Action   string
+	Actor    int
+	Target   string
+	From     time.Time
+	To       time.Time
+	BeforeId int
+	Limit    int
+	Offset   int
+}
+
+// User sort fields
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortEmail     = "email"
+	UserSortLogin     = "login"
+	UserSortId        = "id"
+)
+
+// Filter for user directory. Zero values are ignored.
+// Cursor is taken from previous page, see NextCursor
+type UserFilter struct {
+	Role        string
+	Active      *bool
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Search      string
+	SortBy      string
+	Desc        bool
+	Cursor      string
+	Limit       int
+}
+
+// Value of user field used for sorting and cursor
+func (f *UserFilter) SortValue(u *models.User) string {
+	switch f.SortBy {
+	case UserSortEmail:
+		return u.Email
+	case UserSortLogin:
+		return u.Login
+	case UserSortId:
+		return ""
+	default:
+		return u.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// Cursor pointing after the last user of the page.
+// Empty if page is not full, so there is nothing to load
+func (f *UserFilter) NextCursor(users []*models.User) string {
+	if f.Limit == 0 || len(users) < f.Limit {
+		return ""
+	}
+
+	last := users[len(users)-1]
+	return EncodeCursor(f.SortValue(last), last.ID)
+}
+
+// Ticket sort fields
+const (
+	TicketSortCreatedAt    = "created_at"
+	TicketSortLastActivity = "last_activity"
+)
+
+// Filter for ticket queue. Zero values are ignored. Helper -1 selects
+// not assigned tickets, Helpers limits queue to tickets assigned to one
+// of them. Cursor is taken from previous page, see NextCursor
+type TicketFilter struct {
+	Status      []string
+	Section     string
+	Helper      *int
+	Helpers     []int
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	SortBy      string
+	Desc        bool
+	Cursor      string
+	Limit       int
+}
+
+// Value of ticket field used for sorting and cursor
+func (f *TicketFilter) SortValue(t *models.Ticket) time.Time {
+	if f.SortBy == TicketSortLastActivity {
+		return t.LastActivityAt
+	}
+
+	return t.Created_at
+}
+
+// Cursor pointing after the last ticket of the page.
+// Empty if page is not full, so there is nothing to load
+func (f *TicketFilter) NextCursor(tickets []*models.Ticket) string {
+	if f.Limit == 0 || len(tickets) < f.Limit {
+		return ""
+	}
+
+	last := tickets[len(tickets)-1]
+	return EncodeCursor(f.SortValue(last).UTC().Format(time.RFC3339Nano), int(last.ID))
+}
+
+// Filter for ticket search. Only tickets created by From or assigned to
+// one of Helpers are searched unless All is set. Helper -1 is not assigned
+type SearchFilter struct {
+	Query   string
+	From    int
+	Helpers []int
+	All     bool
+	Limit   int
+}
+
+// Check if ticket is searched by filter
+func (f *SearchFilter) Visible(t *models.Ticket) bool {
+	if f.All || int(t.From) == f.From {
+		return true
+	}
+	for _, id := range f.Helpers {
+		if t.Helper == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -8,4 +8,19 @@ type Store interface {
 	Balance(ctx context.Context) BalanceRepository
 	Tickets(ctx context.Context) TicketRepository
 	Notifications(ctx context.Context) NotificationRepository
+	Audit(ctx context.Context) AuditRepository
+	Sessions(ctx context.Context) SessionRepository
+	Deletions(ctx context.Context) AccountDeletionRepository
+	Contacts(ctx context.Context) ContactRepository
+	Resources(ctx context.Context) ResourceRepository
+	TicketHistory(ctx context.Context) TicketHistoryRepository
+	TicketMutes(ctx context.Context) TicketMuteRepository
+	Sla(ctx context.Context) SlaRepository
+	Sections(ctx context.Context) SectionRepository
+	CannedResponses(ctx context.Context) CannedResponseRepository
+	// Run fn in transaction. Repositories of store passed to fn share it
+	WithTx(ctx context.Context, fn func(Store) error) error
+	// Run fn holding lock with given name, unless other instance holds it.
+	// Reports whether fn was run
+	TryLock(ctx context.Context, name string, fn func() error) (bool, error)
 }
\ No newline at end of file
@@ -1,13 +1,21 @@
 package store
 
-import "github.com/inhumanLightBackend/app/models"
+import (
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+)
 
 // UserRepository
 type UserRepository interface {
 	Create(*models.User) error
 	FindByEmail(string) (*models.User, error)
+	FindByLogin(string) (*models.User, error)
 	FindById(int) (*models.User, error)
+	FindByToken(string) (*models.User, error)
 	Update(*models.User) error
+	List(*UserFilter) ([]*models.User, error)
+	Count(*UserFilter) (int, error)
 }
 
 // BalanceRepository
@@ -19,13 +27,24 @@ type BalanceRepository interface {
 	Remove(uint, float32) (*models.Balance, error)
 }
 
-// TicketRepository
+// TicketRepository. Accept takes only not assigned ticket, Assign sets
+// helper regardless of current one. Stale returns tickets waiting on
+// customer with last message written before given time
 type TicketRepository interface {
 	Create(*models.Ticket) error
 	Accept(uint, *models.User) error
+	Assign(uint, *models.User) error
+	Unassign(uint) error
 	Find(uint) (*models.Ticket, error)
 	FindAll(uint) ([]*models.Ticket, error)
-	ChangeStatus(uint, string) error
+	ChangeStatus(*models.TicketStatusChange) error
+	Reply(*models.TicketMessage, *models.TicketStatusChange) error
+	StatusHistory(uint) ([]*models.TicketStatusChange, error)
+	List(*TicketFilter) ([]*models.Ticket, error)
+	CountByStatus(*TicketFilter) (map[string]int, error)
+	Search(*SearchFilter) ([]*models.SearchHit, error)
+	Stale(before time.Time) ([]*models.StaleTicket, error)
+	Remind(uint, time.Time) error
 	TicketMessagesRepository
 }
 
@@ -38,5 +57,104 @@ type TicketMessagesRepository interface {
 type NotificationRepository interface {
 	Create(*models.Notification) error
 	FindById(uint) ([]*models.Notification, error)
+	FindAll(uint) ([]*models.Notification, error)
 	Check([]int, uint) error
-}
\ No newline at end of file
+}
+
+// AuditRepository. Append only, entries can not be changed or removed
+type AuditRepository interface {
+	Create(*models.AuditLog) error
+	Find(*AuditFilter) ([]*models.AuditLog, error)
+	Count(*AuditFilter) (int, error)
+}
+
+// SessionRepository. RevokeAll revokes every user session except given one
+type SessionRepository interface {
+	Create(*models.Session) error
+	Find(string) (*models.Session, error)
+	FindByUser(int) ([]*models.Session, error)
+	Revoke(string) error
+	RevokeAll(int, string) error
+}
+
+// AccountDeletionRepository. FindDue returns not completed requests with purge time passed
+type AccountDeletionRepository interface {
+	Create(*models.AccountDeletion) error
+	Find(int) (*models.AccountDeletion, error)
+	Cancel(int) error
+	FindDue(time.Time) ([]*models.AccountDeletion, error)
+	Complete(int) error
+}
+
+// ContactRepository. CountAttempt counts attempt to enter verification
+// code of contact and returns number of attempts with current code
+type ContactRepository interface {
+	Create(*models.Contact) error
+	Find(int) (*models.Contact, error)
+	FindByUser(int) ([]*models.Contact, error)
+	Update(*models.Contact) error
+	CountAttempt(int) (int, error)
+	Delete(int) error
+}
+
+// ResourceRepository. Metadata of uploaded files, content is kept in storage.
+// Delete returns ErrResourceAttached for files linked to a ticket
+type ResourceRepository interface {
+	Create(*models.Resource) error
+	Find(int) (*models.Resource, error)
+	FindByOwner(int) ([]*models.Resource, error)
+	FindByTicket(uint) ([]*models.Resource, error)
+	Attach(owner int, ticketId uint, messageId uint, ids []int) error
+	Delete(int) error
+}
+
+// SlaRepository. Policies are unique by section. CreateBreach returns
+// ErrBreachRegistered if target of ticket is already breached
+type SlaRepository interface {
+	CreatePolicy(*models.SlaPolicy) error
+	UpdatePolicy(*models.SlaPolicy) error
+	DeletePolicy(int) error
+	FindPolicy(int) (*models.SlaPolicy, error)
+	Policies() ([]*models.SlaPolicy, error)
+	Timelines(from, to time.Time) ([]*models.TicketTimeline, error)
+	CreateBreach(*models.SlaBreach) error
+	Breaches(from, to time.Time) ([]*models.SlaBreach, error)
+}
+
+// SectionRepository. Sections are unique by name. LastHelper is changed
+// only by SetLastHelper. FindForUpdate locks section until end of
+// transaction, so round robin turns are taken one by one
+type SectionRepository interface {
+	Create(*models.Section) error
+	Update(*models.Section) error
+	Delete(int) error
+	Find(int) (*models.Section, error)
+	FindForUpdate(int) (*models.Section, error)
+	FindByName(string) (*models.Section, error)
+	List() ([]*models.Section, error)
+	SetLastHelper(id int, helperId int) error
+}
+
+// CannedResponseRepository. FindVisible returns shared responses and
+// personal responses of user
+type CannedResponseRepository interface {
+	Create(*models.CannedResponse) error
+	Update(*models.CannedResponse) error
+	Delete(int) error
+	Find(int) (*models.CannedResponse, error)
+	FindVisible(userId int) ([]*models.CannedResponse, error)
+	Used(int) error
+}
+
+// TicketMuteRepository. Tickets user does not want to be notified about
+type TicketMuteRepository interface {
+	Mute(userId int, ticketId uint) error
+	Unmute(userId int, ticketId uint) error
+	IsMuted(userId int, ticketId uint) (bool, error)
+}
+
+// TicketHistoryRepository. Changes of ticket helper
+type TicketHistoryRepository interface {
+	Create(*models.TicketHistory) error
+	FindByTicket(uint) ([]*models.TicketHistory, error)
+}
@@ -0,0 +1,112 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Account deletion repository
+type AccountDeletionRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create deletion request. Only one request per user is allowed
+func (repo *AccountDeletionRepository) Create(deletion *models.AccountDeletion) error {
+	deletion.BeforeCreate()
+
+	res, err := repo.store.db.ExecContext(
+		repo.ctx,
+		`insert into account_deletions (user_id, requested_at, purge_at) values ($1, $2, $3) 
+		on conflict (user_id) do nothing`,
+		deletion.UserId,
+		deletion.RequestedAt,
+		deletion.PurgeAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return store.ErrDeletionPending
+	}
+
+	return nil
+}
+
+// Find deletion request of user
+func (repo *AccountDeletionRepository) Find(userId int) (*models.AccountDeletion, error) {
+	deletion := &models.AccountDeletion{}
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select user_id, requested_at, purge_at, completed_at from account_deletions where user_id = $1",
+		userId,
+	).Scan(&deletion.UserId, &deletion.RequestedAt, &deletion.PurgeAt, &deletion.CompletedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return deletion, nil
+}
+
+// Cancel not completed deletion request
+func (repo *AccountDeletionRepository) Cancel(userId int) error {
+	res, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"delete from account_deletions where user_id = $1 and completed_at is null",
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Find not completed requests with purge time before now
+func (repo *AccountDeletionRepository) FindDue(now time.Time) ([]*models.AccountDeletion, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`select user_id, requested_at, purge_at, completed_at from account_deletions 
+		where completed_at is null and purge_at <= $1 order by purge_at`,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletions := make([]*models.AccountDeletion, 0)
+	for rows.Next() {
+		deletion := &models.AccountDeletion{}
+		if err := rows.Scan(&deletion.UserId, &deletion.RequestedAt, &deletion.PurgeAt, &deletion.CompletedAt); err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, deletion)
+	}
+
+	return deletions, rows.Err()
+}
+
+// Mark deletion request as completed
+func (repo *AccountDeletionRepository) Complete(userId int) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update account_deletions set completed_at = $2 where user_id = $1",
+		userId,
+		time.Now().UTC(),
+	)
+
+	return err
+}
@@ -0,0 +1,112 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Audit log repository
+type AuditRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Append new entry to the audit log
+func (repo *AuditRepository) Create(entry *models.AuditLog) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	entry.BeforeCreate()
+
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return err
+	}
+
+	return repo.store.db.QueryRowContext(
+		repo.ctx,
+		"insert into audit_log (action, actor, target, ip, details, created_at) values ($1, $2, $3, $4, $5, $6) returning id",
+		entry.Action,
+		entry.Actor,
+		entry.Target,
+		entry.IP,
+		details,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+// Find entries by filter. Newest entries first
+func (repo *AuditRepository) Find(filter *store.AuditFilter) ([]*models.AuditLog, error) {
+	cond := auditConditions(filter)
+	query := "select id, action, actor, target, ip, details, created_at from audit_log" + cond.where() + " order by id desc"
+	if filter.Limit > 0 {
+		query += " limit " + cond.arg(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += " offset " + cond.arg(filter.Offset)
+	}
+
+	rows, err := repo.store.db.QueryContext(repo.ctx, query, cond.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*models.AuditLog, 0)
+	for rows.Next() {
+		entry := &models.AuditLog{}
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.Action, &entry.Actor, &entry.Target,
+			&entry.IP, &details, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Count entries by filter. Limit and offset are ignored
+func (repo *AuditRepository) Count(filter *store.AuditFilter) (int, error) {
+	cond := auditConditions(filter)
+	count := 0
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select count(*) from audit_log"+cond.where(),
+		cond.args...,
+	).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func auditConditions(filter *store.AuditFilter) *conditions {
+	cond := &conditions{}
+	if filter.Action != "" {
+		cond.add("action = ?", filter.Action)
+	}
+	if filter.Actor != 0 {
+		cond.add("actor = ?", filter.Actor)
+	}
+	if filter.Target != "" {
+		cond.add("target = ?", filter.Target)
+	}
+	if !filter.From.IsZero() {
+		cond.add("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		cond.add("created_at < ?", filter.To)
+	}
+	if filter.BeforeId != 0 {
+		cond.add("id < ?", filter.BeforeId)
+	}
+
+	return cond
+}
@@ -2,8 +2,11 @@ package sqlstore
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
 )
 
 // Balance Repository 
@@ -32,20 +35,99 @@ func (repo *BalanceRepository) CreateBalance(userId uint) error {
 
 // Add value to the balance
 func (repo *BalanceRepository) Add(userId uint, value float32) (*models.Balance, error) {
-	return nil, nil
+	return repo.change(userId, value, "Add to balance")
 }
 
 // Remove valud from the balance
 func (repo *BalanceRepository) Remove(userId uint, value float32) (*models.Balance, error) {
-	return nil, nil
+	return repo.change(userId, -value, "Remove from balance")
 }
 
 // Return user balance
 func (repo *BalanceRepository) LookForBalance(userId uint) (*models.Balance, error) {
-	return nil, nil
+	balance := &models.Balance{}
+
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		`select id, transaction_value, balance_now, from_market, transaction_at, additional_info, user_id 
+		from balance where user_id = $1 order by id desc limit 1`,
+		userId,
+	).Scan(&balance.ID, &balance.Transaction, &balance.BalanceNow, &balance.From,
+		&balance.Date, &balance.AddInfo, &balance.User); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return balance, nil
 }
 
 // Return all trunsactions
 func (repo *BalanceRepository) AllTransactions(userId uint) ([]models.Balance, error) {
-	return nil, nil
-}
\ No newline at end of file
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`select id, transaction_value, balance_now, from_market, transaction_at, additional_info, user_id 
+		from balance where user_id = $1 order by id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Balance, 0)
+	for rows.Next() {
+		balance := models.Balance{}
+		if err := rows.Scan(&balance.ID, &balance.Transaction, &balance.BalanceNow, &balance.From,
+			&balance.Date, &balance.AddInfo, &balance.User); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, balance)
+	}
+
+	return transactions, rows.Err()
+}
+
+// Write new transaction on top of the last user balance.
+// Balance can not become negative. Changes of one user are serialized
+// by advisory lock held until transaction ends
+func (repo *BalanceRepository) change(userId uint, value float32, info string) (*models.Balance, error) {
+	balance := &models.Balance{
+		Transaction: value,
+		From:        "Service",
+		Date:        time.Now().UTC(),
+		AddInfo:     info,
+		User:        userId,
+	}
+
+	if err := repo.store.WithTx(repo.ctx, func(tx store.Store) error {
+		db := tx.(*Store).db
+		if _, err := db.ExecContext(repo.ctx, "select pg_advisory_xact_lock($1, $2)", lockBalance, userId); err != nil {
+			return err
+		}
+
+		return db.QueryRowContext(
+			repo.ctx,
+			`insert into balance (transaction_value, balance_now, from_market, transaction_at, additional_info, user_id)
+		select $2, last.balance_now + $2, $3, $4, $5, $1 from (
+			select coalesce((select balance_now from balance where user_id = $1 order by id desc limit 1), 0) as balance_now
+		) last where last.balance_now + $2 >= 0
+		returning id, balance_now`,
+			balance.User,
+			balance.Transaction,
+			balance.From,
+			balance.Date,
+			balance.AddInfo,
+		).Scan(&balance.ID, &balance.BalanceNow)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrInsufficientFunds
+		}
+
+		return nil, err
+	}
+
+	return balance, nil
+}
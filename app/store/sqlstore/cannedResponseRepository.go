@@ -0,0 +1,143 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Canned response columns in order of scanCannedResponse
+const cannedColumns = "id, owner, title, body, status, usage_count, created_at, updated_at"
+
+// Canned response repository
+type CannedResponseRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create new response
+func (repo *CannedResponseRepository) Create(response *models.CannedResponse) error {
+	if err := response.Validate(); err != nil {
+		return err
+	}
+
+	response.BeforeCreate()
+
+	return repo.store.db.QueryRowContext(
+		repo.ctx,
+		`insert into canned_responses (owner, title, body, status, usage_count, created_at, updated_at) 
+		values ($1, $2, $3, $4, $5, $6, $7) returning id`,
+		response.Owner,
+		response.Title,
+		response.Body,
+		response.Status,
+		response.UsageCount,
+		response.CreatedAt,
+		response.UpdatedAt,
+	).Scan(&response.ID)
+}
+
+// Update title, body and status of response
+func (repo *CannedResponseRepository) Update(response *models.CannedResponse) error {
+	if err := response.Validate(); err != nil {
+		return err
+	}
+
+	response.UpdatedAt = time.Now().UTC()
+	result, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update canned_responses set title = $2, body = $3, status = $4, updated_at = $5 where id = $1",
+		response.ID,
+		response.Title,
+		response.Body,
+		response.Status,
+		response.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete response by id
+func (repo *CannedResponseRepository) Delete(id int) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"delete from canned_responses where id = $1",
+		id,
+	)
+
+	return err
+}
+
+// Find response by id
+func (repo *CannedResponseRepository) Find(id int) (*models.CannedResponse, error) {
+	response, err := scanCannedResponse(repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select "+cannedColumns+" from canned_responses where id = $1",
+		id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return response, err
+}
+
+// Shared and personal responses of user, most used first
+func (repo *CannedResponseRepository) FindVisible(userId int) ([]*models.CannedResponse, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		"select "+cannedColumns+" from canned_responses where owner = 0 or owner = $1 order by usage_count desc, id",
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := make([]*models.CannedResponse, 0)
+	for rows.Next() {
+		response, err := scanCannedResponse(rows)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, rows.Err()
+}
+
+// Count one more usage of response
+func (repo *CannedResponseRepository) Used(id int) error {
+	result, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update canned_responses set usage_count = usage_count + 1 where id = $1",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func scanCannedResponse(row scanner) (*models.CannedResponse, error) {
+	response := &models.CannedResponse{}
+	if err := row.Scan(&response.ID, &response.Owner, &response.Title, &response.Body, &response.Status,
+		&response.UsageCount, &response.CreatedAt, &response.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,39 @@
+package sqlstore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Escape special characters of LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Where clause builder with numbered placeholders
+type conditions struct {
+	parts []string
+	args  []interface{}
+}
+
+// Add condition. Each '?' in expression replaced with next placeholder
+func (c *conditions) add(expr string, args ...interface{}) {
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		expr = strings.Replace(expr, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.parts = append(c.parts, expr)
+}
+
+// Add argument without condition and return placeholder for it
+func (c *conditions) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+// Build where clause. Returns empty string if there is no conditions
+func (c *conditions) where() string {
+	if len(c.parts) == 0 {
+		return ""
+	}
+
+	return " where " + strings.Join(c.parts, " and ")
+}
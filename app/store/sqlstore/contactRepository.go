@@ -0,0 +1,146 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/lib/pq"
+)
+
+// Contact repository
+type ContactRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create new contact
+func (repo *ContactRepository) Create(contact *models.Contact) error {
+	if err := contact.Validate(); err != nil {
+		return err
+	}
+
+	if err := contact.BeforeCreate(); err != nil {
+		return err
+	}
+
+	err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		`insert into contacts (user_id, kind, value, verified, enabled, verification_code, code_expires_at, created_at) 
+		values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`,
+		contact.UserId,
+		contact.Kind,
+		contact.Value,
+		contact.Verified,
+		contact.Enabled,
+		contact.VerificationCode,
+		contact.CodeExpiresAt,
+		contact.CreatedAt,
+	).Scan(&contact.ID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return store.ErrContactExists
+	}
+
+	return err
+}
+
+// Find contact by id
+func (repo *ContactRepository) Find(id int) (*models.Contact, error) {
+	contact := &models.Contact{}
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		`select id, user_id, kind, value, verified, enabled, coalesce(verification_code, ''), 
+		coalesce(code_expires_at, created_at), code_attempts, created_at from contacts where id = $1`,
+		id,
+	).Scan(&contact.ID, &contact.UserId, &contact.Kind, &contact.Value, &contact.Verified, &contact.Enabled,
+		&contact.VerificationCode, &contact.CodeExpiresAt, &contact.CodeAttempts, &contact.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+// Find all user contacts
+func (repo *ContactRepository) FindByUser(userId int) ([]*models.Contact, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`select id, user_id, kind, value, verified, enabled, coalesce(verification_code, ''), 
+		coalesce(code_expires_at, created_at), code_attempts, created_at from contacts where user_id = $1 order by id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := make([]*models.Contact, 0)
+	for rows.Next() {
+		contact := &models.Contact{}
+		if err := rows.Scan(&contact.ID, &contact.UserId, &contact.Kind, &contact.Value, &contact.Verified,
+			&contact.Enabled, &contact.VerificationCode, &contact.CodeExpiresAt, &contact.CodeAttempts, &contact.CreatedAt); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
+
+// Update contact status and verification code
+func (repo *ContactRepository) Update(contact *models.Contact) error {
+	res, err := repo.store.db.ExecContext(
+		repo.ctx,
+		`update contacts set verified = $2, enabled = $3, verification_code = $4, code_expires_at = $5, 
+		code_attempts = $6 where id = $1`,
+		contact.ID,
+		contact.Verified,
+		contact.Enabled,
+		contact.VerificationCode,
+		contact.CodeExpiresAt,
+		contact.CodeAttempts,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Count attempt to enter verification code. Counter is increased before
+// code is compared, so parallel attempts are counted too
+func (repo *ContactRepository) CountAttempt(id int) (int, error) {
+	var attempts int
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"update contacts set code_attempts = code_attempts + 1 where id = $1 returning code_attempts",
+		id,
+	).Scan(&attempts); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, store.ErrRecordNotFound
+		}
+
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// Delete contact by id
+func (repo *ContactRepository) Delete(id int) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"delete from contacts where id = $1",
+		id,
+	)
+
+	return err
+}
@@ -61,6 +61,31 @@ func (repo *NotificationRepository)	FindById(userId uint) ([]*models.Notificatio
 	return notifications, nil
 }
 
+// Find all user notifications including checked
+func (repo *NotificationRepository) FindAll(userId uint) ([]*models.Notification, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		"select * from notifications where for_user = $1 order by created_at",
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		notification := &models.Notification{}
+		if err := rows.Scan(&notification.ID, &notification.Message, &notification.Date,
+			&notification.Status, &notification.For, &notification.Checked); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
 func (repo *NotificationRepository)	Check(indexes []int, userId uint) error {
 	sIndexes := make([]string, 0)
 	for _, n := range indexes {
@@ -0,0 +1,159 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/lib/pq"
+)
+
+// Resource repository
+type ResourceRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create new resource
+func (repo *ResourceRepository) Create(res *models.Resource) error {
+	if err := res.Validate(); err != nil {
+		return err
+	}
+
+	res.BeforeCreate()
+
+	return repo.store.db.QueryRowContext(
+		repo.ctx,
+		`insert into resources (owner, path, filename, mime_type, size, ticket_id, message_id, created_at) 
+		values ($1, $2, $3, $4, $5, nullif($6, 0), nullif($7, 0), $8) returning id`,
+		res.Owner,
+		res.Path,
+		res.Filename,
+		res.MimeType,
+		res.Size,
+		res.TicketId,
+		res.MessageId,
+		res.CreatedAt,
+	).Scan(&res.ID)
+}
+
+// Find resource by id
+func (repo *ResourceRepository) Find(id int) (*models.Resource, error) {
+	res := &models.Resource{}
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		`select id, owner, path, filename, mime_type, size, coalesce(ticket_id, 0), coalesce(message_id, 0), created_at 
+		from resources where id = $1`,
+		id,
+	).Scan(&res.ID, &res.Owner, &res.Path, &res.Filename, &res.MimeType, &res.Size,
+		&res.TicketId, &res.MessageId, &res.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Find all resources uploaded by user
+func (repo *ResourceRepository) FindByOwner(owner int) ([]*models.Resource, error) {
+	return repo.findAll(
+		`select id, owner, path, filename, mime_type, size, coalesce(ticket_id, 0), coalesce(message_id, 0), created_at 
+		from resources where owner = $1 order by id`,
+		owner,
+	)
+}
+
+// Find all resources attached to ticket
+func (repo *ResourceRepository) FindByTicket(ticketId uint) ([]*models.Resource, error) {
+	return repo.findAll(
+		`select id, owner, path, filename, mime_type, size, coalesce(ticket_id, 0), coalesce(message_id, 0), created_at 
+		from resources where ticket_id = $1 order by id`,
+		ticketId,
+	)
+}
+
+// Attach uploaded resources of owner to ticket and, if message id is
+// not zero, to its message. Resource already attached to message or other
+// ticket can not be attached. Nothing is changed if any of resources
+// can not be attached
+func (repo *ResourceRepository) Attach(owner int, ticketId uint, messageId uint, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result, err := repo.store.db.ExecContext(
+		repo.ctx,
+		`with candidates as (
+			select id from resources 
+			where id = any($1) and owner = $2 and message_id is null and (ticket_id is null or ticket_id = $3) 
+			for update
+		) 
+		update resources set ticket_id = $3, message_id = nullif($4, 0) 
+		where id in (select id from candidates) and (select count(*) from candidates) = $5`,
+		pq.Array(ids),
+		owner,
+		ticketId,
+		messageId,
+		len(ids),
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != int64(len(ids)) {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete resource by id unless it is linked to a ticket
+func (repo *ResourceRepository) Delete(id int) error {
+	result, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"delete from resources where id = $1 and ticket_id is null",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if _, err := repo.Find(id); err != nil {
+			return err
+		}
+		return store.ErrResourceAttached
+	}
+
+	return nil
+}
+
+func (repo *ResourceRepository) findAll(query string, args ...interface{}) ([]*models.Resource, error) {
+	rows, err := repo.store.db.QueryContext(repo.ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resources := make([]*models.Resource, 0)
+	for rows.Next() {
+		res := &models.Resource{}
+		if err := rows.Scan(&res.ID, &res.Owner, &res.Path, &res.Filename, &res.MimeType, &res.Size,
+			&res.TicketId, &res.MessageId, &res.CreatedAt); err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+
+	return resources, rows.Err()
+}
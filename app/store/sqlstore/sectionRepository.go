@@ -0,0 +1,180 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/lib/pq"
+)
+
+// Section columns in order of scanSection
+const sectionColumns = "id, name, description, priority, routing, helpers, last_helper, created_at"
+
+// Section repository
+type SectionRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create new section
+func (repo *SectionRepository) Create(section *models.Section) error {
+	if err := section.Validate(); err != nil {
+		return err
+	}
+
+	section.BeforeCreate()
+
+	err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		`insert into sections (name, description, priority, routing, helpers, last_helper, created_at) 
+		values ($1, $2, $3, $4, $5, $6, $7) returning id`,
+		section.Name,
+		section.Description,
+		section.Priority,
+		section.Routing,
+		pq.Array(section.Helpers),
+		section.LastHelper,
+		section.CreatedAt,
+	).Scan(&section.ID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return store.ErrSectionExists
+	}
+
+	return err
+}
+
+// Update section fields except last helper
+func (repo *SectionRepository) Update(section *models.Section) error {
+	if err := section.Validate(); err != nil {
+		return err
+	}
+
+	result, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update sections set name = $2, description = $3, priority = $4, routing = $5, helpers = $6 where id = $1",
+		section.ID,
+		section.Name,
+		section.Description,
+		section.Priority,
+		section.Routing,
+		pq.Array(section.Helpers),
+	)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return store.ErrSectionExists
+	}
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete section by id. Tickets of section keep its name
+func (repo *SectionRepository) Delete(id int) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"delete from sections where id = $1",
+		id,
+	)
+
+	return err
+}
+
+// Find section by id
+func (repo *SectionRepository) Find(id int) (*models.Section, error) {
+	return repo.findBy("id", id)
+}
+
+// Find section by id and lock its row. Lock is held until end of
+// transaction, outside of WithTx it is released at once
+func (repo *SectionRepository) FindForUpdate(id int) (*models.Section, error) {
+	section, err := scanSection(repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select "+sectionColumns+" from sections where id = $1 for update",
+		id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return section, err
+}
+
+// Find section by name
+func (repo *SectionRepository) FindByName(name string) (*models.Section, error) {
+	return repo.findBy("name", name)
+}
+
+// All sections ordered by name
+func (repo *SectionRepository) List() ([]*models.Section, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		"select "+sectionColumns+" from sections order by name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sections := make([]*models.Section, 0)
+	for rows.Next() {
+		section, err := scanSection(rows)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+
+	return sections, rows.Err()
+}
+
+// Remember helper who took the last round robin ticket
+func (repo *SectionRepository) SetLastHelper(id int, helperId int) error {
+	result, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update sections set last_helper = $2 where id = $1",
+		id,
+		helperId,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repo *SectionRepository) findBy(column string, value interface{}) (*models.Section, error) {
+	section, err := scanSection(repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select "+sectionColumns+" from sections where "+column+" = $1",
+		value,
+	))
+	if err == sql.ErrNoRows {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return section, err
+}
+
+func scanSection(row scanner) (*models.Section, error) {
+	section := &models.Section{}
+	helpers := make(pq.Int64Array, 0)
+	if err := row.Scan(&section.ID, &section.Name, &section.Description, &section.Priority,
+		&section.Routing, &helpers, &section.LastHelper, &section.CreatedAt); err != nil {
+		return nil, err
+	}
+	section.Helpers = make([]int, 0, len(helpers))
+	for _, id := range helpers {
+		section.Helpers = append(section.Helpers, int(id))
+	}
+
+	return section, nil
+}
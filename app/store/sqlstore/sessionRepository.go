@@ -0,0 +1,104 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Session repository
+type SessionRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create new session
+func (repo *SessionRepository) Create(session *models.Session) error {
+	if err := session.BeforeCreate(); err != nil {
+		return err
+	}
+
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"insert into sessions (id, user_id, created_at, expires_at, revoked, ip, user_agent) values ($1, $2, $3, $4, $5, $6, $7)",
+		session.ID,
+		session.UserId,
+		session.CreatedAt,
+		session.ExpiresAt,
+		session.Revoked,
+		session.IP,
+		session.UserAgent,
+	)
+
+	return err
+}
+
+// Find session by id
+func (repo *SessionRepository) Find(id string) (*models.Session, error) {
+	session := &models.Session{}
+
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select id, user_id, created_at, expires_at, revoked, ip, user_agent from sessions where id = $1",
+		id,
+	).Scan(&session.ID, &session.UserId, &session.CreatedAt, &session.ExpiresAt,
+		&session.Revoked, &session.IP, &session.UserAgent); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Find all user sessions
+func (repo *SessionRepository) FindByUser(userId int) ([]*models.Session, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		"select id, user_id, created_at, expires_at, revoked, ip, user_agent from sessions where user_id = $1 order by created_at desc",
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*models.Session, 0)
+	for rows.Next() {
+		session := &models.Session{}
+		if err := rows.Scan(&session.ID, &session.UserId, &session.CreatedAt, &session.ExpiresAt,
+			&session.Revoked, &session.IP, &session.UserAgent); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Revoke session by id
+func (repo *SessionRepository) Revoke(id string) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update sessions set revoked = true where id = $1",
+		id,
+	)
+
+	return err
+}
+
+// Revoke all user sessions except given one
+func (repo *SessionRepository) RevokeAll(userId int, except string) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update sessions set revoked = true where user_id = $1 and id <> $2",
+		userId,
+		except,
+	)
+
+	return err
+}
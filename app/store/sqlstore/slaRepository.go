@@ -0,0 +1,213 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/lib/pq"
+)
+
+// SLA repository
+type SlaRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create new policy
+func (repo *SlaRepository) CreatePolicy(policy *models.SlaPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	policy.BeforeCreate()
+
+	err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		`insert into sla_policies (section, first_response_minutes, resolution_minutes, created_at) 
+		values ($1, $2, $3, $4) returning id`,
+		policy.Section,
+		policy.FirstResponseMinutes,
+		policy.ResolutionMinutes,
+		policy.CreatedAt,
+	).Scan(&policy.ID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return store.ErrSlaPolicyExists
+	}
+
+	return err
+}
+
+// Update policy section and limits
+func (repo *SlaRepository) UpdatePolicy(policy *models.SlaPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	result, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update sla_policies set section = $2, first_response_minutes = $3, resolution_minutes = $4 where id = $1",
+		policy.ID,
+		policy.Section,
+		policy.FirstResponseMinutes,
+		policy.ResolutionMinutes,
+	)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return store.ErrSlaPolicyExists
+	}
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete policy by id. Breaches of policy are kept
+func (repo *SlaRepository) DeletePolicy(id int) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"delete from sla_policies where id = $1",
+		id,
+	)
+
+	return err
+}
+
+// Find policy by id
+func (repo *SlaRepository) FindPolicy(id int) (*models.SlaPolicy, error) {
+	policy := &models.SlaPolicy{}
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select id, section, first_response_minutes, resolution_minutes, created_at from sla_policies where id = $1",
+		id,
+	).Scan(&policy.ID, &policy.Section, &policy.FirstResponseMinutes, &policy.ResolutionMinutes, &policy.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// All policies ordered by section
+func (repo *SlaRepository) Policies() ([]*models.SlaPolicy, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		"select id, section, first_response_minutes, resolution_minutes, created_at from sla_policies order by section",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make([]*models.SlaPolicy, 0)
+	for rows.Next() {
+		policy := &models.SlaPolicy{}
+		if err := rows.Scan(&policy.ID, &policy.Section, &policy.FirstResponseMinutes,
+			&policy.ResolutionMinutes, &policy.CreatedAt); err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// Timelines of tickets created in period. Zero bound is not applied
+func (repo *SlaRepository) Timelines(from, to time.Time) ([]*models.TicketTimeline, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`select t.id, t.section, t.created_at, 
+			(select min(m.reply_at) from ticket_messages m where m.ticket_id = t.id and m.who <> t.from_user), 
+			(select min(h.created_at) from ticket_status_history h where h.ticket_id = t.id and h.to_status = any($3)) 
+		from tickets t 
+		where ($1::timestamp is null or t.created_at >= $1) and ($2::timestamp is null or t.created_at < $2) 
+		order by t.id`,
+		nullTime(from),
+		nullTime(to),
+		pq.Array([]string{ticketStatus.Resolved, ticketStatus.Closed}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timelines := make([]*models.TicketTimeline, 0)
+	for rows.Next() {
+		timeline := &models.TicketTimeline{}
+		var responded, resolved pq.NullTime
+		if err := rows.Scan(&timeline.TicketId, &timeline.Section, &timeline.CreatedAt, &responded, &resolved); err != nil {
+			return nil, err
+		}
+		if responded.Valid {
+			timeline.FirstResponseAt = &responded.Time
+		}
+		if resolved.Valid {
+			timeline.ResolvedAt = &resolved.Time
+		}
+		timelines = append(timelines, timeline)
+	}
+
+	return timelines, rows.Err()
+}
+
+// Register breach of ticket target
+func (repo *SlaRepository) CreateBreach(breach *models.SlaBreach) error {
+	breach.BeforeCreate()
+
+	err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		`insert into sla_breaches (ticket_id, policy_id, kind, due_at, breached_at) values ($1, $2, $3, $4, $5) 
+		on conflict (ticket_id, kind) do nothing returning id`,
+		breach.TicketId,
+		breach.PolicyId,
+		breach.Kind,
+		breach.DueAt,
+		breach.BreachedAt,
+	).Scan(&breach.ID)
+	if err == sql.ErrNoRows {
+		return store.ErrBreachRegistered
+	}
+
+	return err
+}
+
+// Breaches registered in period. Zero bound is not applied
+func (repo *SlaRepository) Breaches(from, to time.Time) ([]*models.SlaBreach, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`select id, ticket_id, policy_id, kind, due_at, breached_at from sla_breaches 
+		where ($1::timestamp is null or breached_at >= $1) and ($2::timestamp is null or breached_at < $2) 
+		order by id`,
+		nullTime(from),
+		nullTime(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breaches := make([]*models.SlaBreach, 0)
+	for rows.Next() {
+		breach := &models.SlaBreach{}
+		if err := rows.Scan(&breach.ID, &breach.TicketId, &breach.PolicyId, &breach.Kind,
+			&breach.DueAt, &breach.BreachedAt); err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, breach)
+	}
+
+	return breaches, rows.Err()
+}
+
+func nullTime(t time.Time) pq.NullTime {
+	return pq.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -3,72 +3,203 @@ package sqlstore
 import (
 	"context"
 	"database/sql"
+	"hash/fnv"
 
 	"github.com/inhumanLightBackend/app/store"
 	_ "github.com/lib/pq" //
 )
 
-// Store struct
+// Common methods of *sql.DB and *sql.Tx used by repositories
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Namespaces of advisory locks, first key of two-key locks
+const (
+	lockBalance = 1
+	lockJob     = 2
+)
+
+// Store struct. Repositories run queries on db, which is
+// the connection pool or transaction inside WithTx. Repositories are
+// built on every call, so each one uses context of its caller
 type Store struct {
-	db                     *sql.DB
-	userRepository         *UserRepository
-	balanceRepository      *BalanceRepository
-	ticketRepository       *TicketRepository
-	notificationRepositroy *NotificationRepository
+	conn *sql.DB
+	db   querier
 }
 
 // Create new store
 func New(db *sql.DB) *Store {
 	return &Store{
-		db: db,
+		conn: db,
+		db:   db,
+	}
+}
+
+// Run fn in transaction. Repositories of the store passed to fn share
+// one transaction, which is committed if fn returns nil and rolled back otherwise.
+// Nested call joins the outer transaction
+func (s *Store) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	if _, ok := s.db.(*sql.Tx); ok {
+		return fn(s)
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&Store{conn: s.conn, db: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Run fn holding session advisory lock named by name. The lock is taken
+// on dedicated connection, so it is released by the same connection
+func (s *Store) TryLock(ctx context.Context, name string, fn func() error) (bool, error) {
+	conn, err := s.conn.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	key := int32(hash.Sum32())
+
+	locked := false
+	if err := conn.QueryRowContext(ctx, "select pg_try_advisory_lock($1, $2)", lockJob, key).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
 	}
+	defer conn.ExecContext(context.Background(), "select pg_advisory_unlock($1, $2)", lockJob, key)
+
+	return true, fn()
 }
 
 // Return user functionality
 func (store *Store) User(ctx context.Context) store.UserRepository {
 	// Передовать контекст как параметр и класть в репозиторий. А дальше прописать у всех запросов
-	if store.userRepository == nil {
-		store.userRepository = &UserRepository{
-			store: store,
-			ctx:   ctx,
-		}
+	return &UserRepository{
+		store: store,
+		ctx:   ctx,
 	}
-
-	return store.userRepository
 }
 
 // Return Balance transaction history functionality
 func (store *Store) Balance(ctx context.Context) store.BalanceRepository {
-	if store.balanceRepository == nil {
-		store.balanceRepository = &BalanceRepository{
-			store: store,
-			ctx:   ctx,
-		}
+	return &BalanceRepository{
+		store: store,
+		ctx:   ctx,
 	}
-
-	return store.balanceRepository
 }
 
 // Return Ticket history functionality
 func (store *Store) Tickets(ctx context.Context) store.TicketRepository {
-	if store.ticketRepository == nil {
-		store.ticketRepository = &TicketRepository{
-			store: store,
-			ctx:   ctx,
-		}
+	return &TicketRepository{
+		store: store,
+		ctx:   ctx,
 	}
-
-	return store.ticketRepository
 }
 
 // Return Notification functionality
 func (store *Store) Notifications(ctx context.Context) store.NotificationRepository {
-	if store.notificationRepositroy == nil {
-		store.notificationRepositroy = &NotificationRepository{
-			store: store,
-			ctx:   ctx,
-		}
+	return &NotificationRepository{
+		store: store,
+		ctx:   ctx,
 	}
+}
 
-	return store.notificationRepositroy
+// Return Audit log functionality
+func (store *Store) Audit(ctx context.Context) store.AuditRepository {
+	return &AuditRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return Session functionality
+func (store *Store) Sessions(ctx context.Context) store.SessionRepository {
+	return &SessionRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return Account deletion functionality
+func (store *Store) Deletions(ctx context.Context) store.AccountDeletionRepository {
+	return &AccountDeletionRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return Contact functionality
+func (store *Store) Contacts(ctx context.Context) store.ContactRepository {
+	return &ContactRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return Resource functionality
+func (store *Store) Resources(ctx context.Context) store.ResourceRepository {
+	return &ResourceRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return Ticket history functionality
+func (store *Store) TicketHistory(ctx context.Context) store.TicketHistoryRepository {
+	return &TicketHistoryRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return Ticket mute functionality
+func (store *Store) TicketMutes(ctx context.Context) store.TicketMuteRepository {
+	return &TicketMuteRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return SLA functionality
+func (store *Store) Sla(ctx context.Context) store.SlaRepository {
+	return &SlaRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return section functionality
+func (store *Store) Sections(ctx context.Context) store.SectionRepository {
+	return &SectionRepository{
+		store: store,
+		ctx:   ctx,
+	}
+}
+
+// Return canned response functionality
+func (store *Store) CannedResponses(ctx context.Context) store.CannedResponseRepository {
+	return &CannedResponseRepository{
+		store: store,
+		ctx:   ctx,
+	}
 }
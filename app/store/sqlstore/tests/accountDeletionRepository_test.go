@@ -0,0 +1,35 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountDeletionRepository(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("account_deletions")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	assert.NoError(t, s.Deletions(ctx).Create(&models.AccountDeletion{UserId: 1}))
+	assert.EqualError(t, s.Deletions(ctx).Create(&models.AccountDeletion{UserId: 1}), store.ErrDeletionPending.Error())
+
+	due, err := s.Deletions(ctx).FindDue(time.Now().UTC())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(due))
+	due, err = s.Deletions(ctx).FindDue(time.Now().UTC().Add(models.DeletionGracePeriod + time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(due))
+
+	assert.NoError(t, s.Deletions(ctx).Complete(1))
+	deletion, err := s.Deletions(ctx).Find(1)
+	assert.NoError(t, err)
+	assert.NotNil(t, deletion.CompletedAt)
+	assert.Error(t, s.Deletions(ctx).Cancel(1))
+}
@@ -0,0 +1,52 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository_Create(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("audit_log")
+
+	store := sqlstore.New(db)
+	entry := models.NewTestAuditLog(t)
+	assert.NoError(t, store.Audit(context.Background()).Create(entry))
+	assert.NotEmpty(t, entry.ID)
+}
+
+func TestAuditRepository_Find(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("audit_log")
+
+	s := sqlstore.New(db)
+	count := 5
+	for i := 0; i < count; i++ {
+		assert.NoError(t, s.Audit(ctx).Create(models.NewTestAuditLog(t)))
+	}
+	failed := models.NewTestAuditLog(t)
+	failed.Action = auditAction.SignInFailed
+	assert.NoError(t, s.Audit(ctx).Create(failed))
+
+	entries, err := s.Audit(ctx).Find(&store.AuditFilter{Action: auditAction.SignIn, Limit: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "testUser@gmail.com", entries[0].Details["login"])
+
+	// Next page by last id
+	next, err := s.Audit(ctx).Find(&store.AuditFilter{Action: auditAction.SignIn, Limit: 3, BeforeId: entries[2].ID})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(next))
+	assert.Less(t, next[0].ID, entries[2].ID)
+
+	total, err := s.Audit(ctx).Count(&store.AuditFilter{Action: auditAction.SignIn})
+	assert.NoError(t, err)
+	assert.Equal(t, count, total)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/inhumanLightBackend/app/store"
 	"github.com/inhumanLightBackend/app/store/sqlstore"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,4 +15,64 @@ func TestBalanceRepository_Create(t *testing.T) {
 
 	store := sqlstore.New(db)
 	assert.NoError(t, store.Balance(context.Background()).CreateBalance(23))
-}
\ No newline at end of file
+}
+
+func TestBalanceRepository_AddRemove(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("balance")
+
+	s := sqlstore.New(db)
+	var userId uint = 23
+	assert.NoError(t, s.Balance(ctx).CreateBalance(userId))
+
+	balance, err := s.Balance(ctx).Add(userId, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(100), balance.BalanceNow)
+
+	balance, err = s.Balance(ctx).Remove(userId, 40)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(60), balance.BalanceNow)
+
+	_, err = s.Balance(ctx).Remove(userId, 100)
+	assert.Equal(t, store.ErrInsufficientFunds, err)
+
+	transactions, err := s.Balance(ctx).AllTransactions(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(transactions))
+}
+
+func TestBalanceRepository_ConcurrentRemove(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("balance")
+
+	s := sqlstore.New(db)
+	var userId uint = 23
+	assert.NoError(t, s.Balance(ctx).CreateBalance(userId))
+	_, err := s.Balance(ctx).Add(userId, 100)
+	assert.NoError(t, err)
+
+	// Only ten debits fit into balance
+	results := make(chan error, 30)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			_, err := s.Balance(ctx).Remove(userId, 10)
+			results <- err
+		}()
+	}
+	succeeded := 0
+	for i := 0; i < cap(results); i++ {
+		err := <-results
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, store.ErrInsufficientFunds, err)
+	}
+	assert.Equal(t, 10, succeeded)
+
+	balance, err := s.Balance(ctx).LookForBalance(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0), balance.BalanceNow)
+}
@@ -0,0 +1,46 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCannedResponseRepository(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("canned_responses")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+
+	assert.Error(t, s.CannedResponses(ctx).Create(&models.CannedResponse{Title: "Hi"}))
+	shared := &models.CannedResponse{Title: "Hi", Body: "Hi {{user.login}}"}
+	assert.NoError(t, s.CannedResponses(ctx).Create(shared))
+	personal := &models.CannedResponse{Owner: 3, Title: "Bye", Body: "Bye"}
+	assert.NoError(t, s.CannedResponses(ctx).Create(personal))
+	assert.NoError(t, s.CannedResponses(ctx).Create(&models.CannedResponse{Owner: 4, Title: "Bye", Body: "Bye"}))
+
+	assert.NoError(t, s.CannedResponses(ctx).Used(personal.ID))
+	assert.Equal(t, store.ErrRecordNotFound, s.CannedResponses(ctx).Used(personal.ID+10))
+	responses, err := s.CannedResponses(ctx).FindVisible(3)
+	assert.NoError(t, err)
+	assert.Len(t, responses, 2)
+	assert.Equal(t, personal.ID, responses[0].ID)
+	assert.Equal(t, 1, responses[0].UsageCount)
+
+	personal.Body = "See you"
+	assert.NoError(t, s.CannedResponses(ctx).Update(personal))
+	found, err := s.CannedResponses(ctx).Find(personal.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "See you", found.Body)
+	personal.Body = ""
+	assert.Error(t, s.CannedResponses(ctx).Update(personal))
+
+	assert.NoError(t, s.CannedResponses(ctx).Delete(shared.ID))
+	_, err = s.CannedResponses(ctx).Find(shared.ID)
+	assert.Equal(t, store.ErrRecordNotFound, err)
+}
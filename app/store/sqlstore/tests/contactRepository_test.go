@@ -0,0 +1,44 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContactRepository(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("contacts")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	contact := models.NewTestContact(t)
+	assert.NoError(t, s.Contacts(ctx).Create(contact))
+	assert.EqualError(t, s.Contacts(ctx).Create(models.NewTestContact(t)), store.ErrContactExists.Error())
+
+	found, err := s.Contacts(ctx).Find(contact.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, contact.VerificationCode, found.VerificationCode)
+
+	found.CodeAttempts, err = s.Contacts(ctx).CountAttempt(contact.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.CodeAttempts)
+	_, err = s.Contacts(ctx).CountAttempt(contact.ID + 10)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	assert.True(t, found.Verify(contact.VerificationCode))
+	assert.NoError(t, s.Contacts(ctx).Update(found))
+	contacts, err := s.Contacts(ctx).FindByUser(contact.UserId)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(contacts))
+	assert.True(t, contacts[0].Enabled)
+	assert.Equal(t, "", contacts[0].VerificationCode)
+
+	assert.NoError(t, s.Contacts(ctx).Delete(contact.ID))
+	_, err = s.Contacts(ctx).Find(contact.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
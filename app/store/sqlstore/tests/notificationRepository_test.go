@@ -61,4 +61,21 @@ func TestNotificationRepository_Check(t *testing.T) {
 	for _, item := range notifs {
 		assert.True(t, item.Checked)
 	}
-}
\ No newline at end of file
+}
+func TestNotificationRepository_FindAll(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("notifications")
+
+	var userId uint = 3
+	store := sqlstore.New(db)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, store.Notifications(ctx).Create(models.NewTestNotification(t)))
+	}
+	notifs, _ := store.Notifications(ctx).FindById(userId)
+	assert.NoError(t, store.Notifications(ctx).Check([]int{notifs[0].ID}, userId))
+
+	notifs, err := store.Notifications(ctx).FindAll(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(notifs))
+}
@@ -0,0 +1,71 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceRepository(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("resources")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	res := models.NewTestResource(t)
+	res.TicketId = 5
+	assert.NoError(t, s.Resources(ctx).Create(res))
+
+	found, err := s.Resources(ctx).Find(res.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, res.Path, found.Path)
+	assert.Equal(t, uint(5), found.TicketId)
+	assert.Equal(t, uint(0), found.MessageId)
+
+	other := models.NewTestResource(t)
+	other.Path = "2020/04/28/fedcba9876543210"
+	assert.NoError(t, s.Resources(ctx).Create(other))
+	resources, err := s.Resources(ctx).FindByOwner(res.Owner)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resources))
+	resources, err = s.Resources(ctx).FindByTicket(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resources))
+
+	assert.Equal(t, store.ErrResourceAttached, s.Resources(ctx).Delete(res.ID))
+	assert.NoError(t, s.Resources(ctx).Delete(other.ID))
+	_, err = s.Resources(ctx).Find(other.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestResourceRepository_Attach(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("resources")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	res := models.NewTestResource(t)
+	assert.NoError(t, s.Resources(ctx).Create(res))
+	other := models.NewTestResource(t)
+	other.Path = "2020/04/28/fedcba9876543210"
+	other.Owner = res.Owner + 1
+	assert.NoError(t, s.Resources(ctx).Create(other))
+
+	err := s.Resources(ctx).Attach(res.Owner, 5, 7, []int{res.ID, other.ID})
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	found, _ := s.Resources(ctx).Find(res.ID)
+	assert.Equal(t, uint(0), found.TicketId)
+
+	assert.NoError(t, s.Resources(ctx).Attach(res.Owner, 5, 7, []int{res.ID}))
+	found, _ = s.Resources(ctx).Find(res.ID)
+	assert.Equal(t, uint(5), found.TicketId)
+	assert.Equal(t, uint(7), found.MessageId)
+
+	err = s.Resources(ctx).Attach(res.Owner, 5, 8, []int{res.ID})
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Resources(ctx).Attach(res.Owner, 5, 8, nil))
+}
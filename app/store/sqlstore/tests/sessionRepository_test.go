@@ -0,0 +1,62 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRepository_Create(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("sessions")
+
+	store := sqlstore.New(db)
+	session := models.NewTestSession(t)
+	assert.NoError(t, store.Sessions(context.Background()).Create(session))
+	assert.NotEmpty(t, session.ID)
+}
+
+func TestSessionRepository_Find(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("sessions")
+
+	store := sqlstore.New(db)
+	session := models.NewTestSession(t)
+	assert.NoError(t, store.Sessions(ctx).Create(session))
+
+	session1, err := store.Sessions(ctx).Find(session.ID)
+	assert.NoError(t, err)
+	assert.True(t, session1.IsActive())
+
+	assert.NoError(t, store.Sessions(ctx).Revoke(session.ID))
+	session1, err = store.Sessions(ctx).Find(session.ID)
+	assert.NoError(t, err)
+	assert.False(t, session1.IsActive())
+}
+
+func TestSessionRepository_RevokeAll(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("sessions")
+
+	store := sqlstore.New(db)
+	count := 3
+	sessions := make([]*models.Session, 0)
+	for i := 0; i < count; i++ {
+		session := models.NewTestSession(t)
+		assert.NoError(t, store.Sessions(ctx).Create(session))
+		sessions = append(sessions, session)
+	}
+
+	assert.NoError(t, store.Sessions(ctx).RevokeAll(sessions[0].UserId, sessions[0].ID))
+	userSessions, err := store.Sessions(ctx).FindByUser(sessions[0].UserId)
+	assert.NoError(t, err)
+	assert.Equal(t, count, len(userSessions))
+	for _, item := range userSessions {
+		assert.Equal(t, item.ID != sessions[0].ID, item.Revoked)
+	}
+}
@@ -0,0 +1,37 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/ticketAction"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicketHistoryRepository(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("ticket_history")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	for _, action := range []string{ticketAction.Accept, ticketAction.Unassign} {
+		assert.NoError(t, s.TicketHistory(ctx).Create(&models.TicketHistory{
+			TicketId:   1,
+			Actor:      2,
+			Action:     action,
+			FromHelper: -1,
+			ToHelper:   2,
+		}))
+	}
+
+	entries, err := s.TicketHistory(ctx).FindByTicket(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, ticketAction.Unassign, entries[1].Action)
+
+	entries, err = s.TicketHistory(ctx).FindByTicket(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+}
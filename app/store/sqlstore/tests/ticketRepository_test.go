@@ -3,9 +3,11 @@ package sqlstore_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
 	"github.com/inhumanLightBackend/app/store/sqlstore"
 	"github.com/stretchr/testify/assert"
 )
@@ -67,23 +69,78 @@ func TestTicketRepository_Accept(t *testing.T) {
 	assert.NotNil(t, ticket1)
 
 	assert.NotEqual(t, ticket1.Helper, -1)
-	assert.Equal(t, ticket1.Status, ticketStatus.InProcess)
+	// Status is changed by handlers through status transitions
+	assert.Equal(t, ticket1.Status, ticketStatus.Opened)
 }
 
-func TestTicketRepository_ChangeStatus(t *testing.T) {
+func TestTicketRepository_AcceptAssigned(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("tickets")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	assert.NoError(t, s.Tickets(ctx).Accept(ticket.ID, &models.User{ID: 2}))
+
+	// Taken ticket is not overwritten
+	assert.Equal(t, store.ErrTicketAssigned, s.Tickets(ctx).Accept(ticket.ID, &models.User{ID: 7}))
+	assert.Equal(t, store.ErrRecordNotFound, s.Tickets(ctx).Accept(ticket.ID+1, &models.User{ID: 7}))
+	found, _ := s.Tickets(ctx).Find(ticket.ID)
+	assert.Equal(t, 2, found.Helper)
+
+	assert.NoError(t, s.Tickets(ctx).Assign(ticket.ID, &models.User{ID: 7}))
+	found, _ = s.Tickets(ctx).Find(ticket.ID)
+	assert.Equal(t, 7, found.Helper)
+}
+
+func TestTicketRepository_Unassign(t *testing.T) {
 	db, cleaner := sqlstore.TestDb(t, databaseUrl)
 	ctx := context.Background()
 	defer cleaner("tickets")
 
-	store := sqlstore.New(db)
+	s := sqlstore.New(db)
 	ticket := models.NewTestTicket(t)
-	assert.NoError(t, store.Tickets(ctx).Create(ticket))
-	assert.NoError(t, store.Tickets(ctx).ChangeStatus(ticket.ID, ticketStatus.Closed))
-	ticket1, err := store.Tickets(ctx).Find(ticket.ID)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	assert.NoError(t, s.Tickets(ctx).Accept(ticket.ID, &models.User{ID: 2}))
+	assert.NoError(t, s.Tickets(ctx).Unassign(ticket.ID))
+	ticket, err := s.Tickets(ctx).Find(ticket.ID)
 	assert.NoError(t, err)
-	assert.NotNil(t, ticket1)
+	assert.Equal(t, -1, ticket.Helper)
+	assert.Equal(t, ticketStatus.Opened, ticket.Status)
+	assert.EqualError(t, s.Tickets(ctx).Unassign(ticket.ID+1), store.ErrRecordNotFound.Error())
+}
 
+func TestTicketRepository_ChangeStatus(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("tickets", "ticket_status_history")
+
+	s := sqlstore.New(db)
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	change := &models.TicketStatusChange{TicketId: ticket.ID, To: ticketStatus.Closed, Actor: 33, Comment: "Solved"}
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(change))
+	assert.Equal(t, ticketStatus.Opened, change.From)
+	ticket1, err := s.Tickets(ctx).Find(ticket.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, ticket1)
 	assert.Equal(t, ticket1.Status, ticketStatus.Closed)
+
+	err = s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     ticketStatus.InProcess,
+		To:       ticketStatus.Resolved,
+	})
+	assert.EqualError(t, err, store.ErrStatusChanged.Error())
+	err = s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{TicketId: ticket.ID, To: "lost"})
+	assert.EqualError(t, err, store.ErrProccessingStatusNotFound.Error())
+
+	changes, err := s.Tickets(ctx).StatusHistory(ticket.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "Solved", changes[0].Comment)
 }
 
 func TestTicketRepository_AddMessage(t *testing.T) {
@@ -116,4 +173,190 @@ func TestTicketRepository_TakeMessages(t *testing.T) {
 	assert.Equal(t, len(messages), messagesCount)
 }
 
+func TestTicketRepository_List(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	ctx := context.Background()
+	defer cleaner("tickets", "ticket_messages")
+
+	s := sqlstore.New(db)
+	ids := make([]uint, 0)
+	for _, section := range []string{"billing", "api", "billing"} {
+		ticket := models.NewTestTicket(t)
+		ticket.Section = section
+		assert.NoError(t, s.Tickets(ctx).Create(ticket))
+		ids = append(ids, ticket.ID)
+	}
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{TicketId: ids[1], To: ticketStatus.Closed}))
+	message := models.NewTestTicketMessage(t)
+	message.TicketId = ids[0]
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
 
+	filter := &store.TicketFilter{SortBy: store.TicketSortLastActivity, Desc: true, Limit: 2}
+	tickets, err := s.Tickets(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tickets))
+	assert.Equal(t, ids[0], tickets[0].ID)
+
+	filter.Cursor = filter.NextCursor(tickets)
+	tickets, err = s.Tickets(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tickets))
+	assert.Equal(t, ids[2], tickets[0].ID)
+
+	filter = &store.TicketFilter{Status: []string{ticketStatus.Opened}, Section: "billing"}
+	tickets, err = s.Tickets(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tickets))
+
+	counts, err := s.Tickets(ctx).CountByStatus(&store.TicketFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{ticketStatus.Opened: 2, ticketStatus.Closed: 1}, counts)
+}
+
+// Messages are indexed for search, extra column must not break reading them
+func TestTicketRepository_TakeMessagesIndexed(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("tickets", "ticket_messages")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	message := models.NewTestTicketMessage(t)
+	message.TicketId = ticket.ID
+	message.Message = "Indexed message"
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+
+	hits, err := s.Tickets(ctx).Search(&store.SearchFilter{Query: "indexed", All: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(hits))
+
+	messages, err := s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, message.ID, messages[0].ID)
+	assert.Equal(t, "Indexed message", messages[0].Message)
+}
+
+func TestTicketRepository_Search(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("tickets", "ticket_messages")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	ticket.Title = "Login fails"
+	ticket.Description = "Can not login after password change"
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	other := models.NewTestTicket(t)
+	other.From = 34
+	assert.NoError(t, s.Tickets(ctx).Create(other))
+	message := models.NewTestTicketMessage(t)
+	message.TicketId = ticket.ID
+	message.Message = "Reset password from login page"
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+
+	hits, err := s.Tickets(ctx).Search(&store.SearchFilter{Query: "LOGIN password", All: true, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(hits))
+	assert.Contains(t, hits[0].Snippet, "<mark>")
+
+	hits, err = s.Tickets(ctx).Search(&store.SearchFilter{Query: "login", From: 34, Helpers: []int{5}, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hits))
+
+	hits, err = s.Tickets(ctx).Search(&store.SearchFilter{Query: "login", From: 34, Helpers: []int{-1}, Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(hits))
+
+	// Snippet is html, text of ticket is escaped
+	message = models.NewTestTicketMessage(t)
+	message.TicketId = ticket.ID
+	message.Message = "<script>alert('xss')</script> printer"
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+	hits, err = s.Tickets(ctx).Search(&store.SearchFilter{Query: "printer", All: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(hits))
+	assert.NotContains(t, hits[0].Snippet, "<script>")
+	assert.Contains(t, hits[0].Snippet, "&lt;script&gt;")
+	assert.Contains(t, hits[0].Snippet, "<mark>printer</mark>")
+}
+
+func TestTicketRepository_Reply(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("tickets", "ticket_messages", "ticket_status_history")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+
+	message := models.NewTestTicketMessage(t)
+	message.TicketId = ticket.ID
+	assert.NoError(t, s.Tickets(ctx).Reply(message, &models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     ticketStatus.Opened,
+		To:       ticketStatus.InProcess,
+	}))
+	messages, _ := s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.Equal(t, 1, len(messages))
+	found, _ := s.Tickets(ctx).Find(ticket.ID)
+	assert.Equal(t, ticketStatus.InProcess, found.Status)
+
+	message = models.NewTestTicketMessage(t)
+	message.TicketId = ticket.ID
+	assert.Equal(t, store.ErrStatusChanged, s.Tickets(ctx).Reply(message, &models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     ticketStatus.Opened,
+		To:       ticketStatus.Closed,
+	}))
+	messages, _ = s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.Equal(t, 1, len(messages))
+}
+
+func TestTicketRepository_Stale(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("tickets", "ticket_messages", "ticket_status_history")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	message := models.NewTestTicketMessage(t)
+	message.TicketId = ticket.ID
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+
+	// Ticket is not waiting on customer
+	tickets, err := s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, tickets)
+
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{
+		TicketId: ticket.ID,
+		To:       ticketStatus.WaitingForCustomer,
+	}))
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(-time.Hour))
+	assert.Empty(t, tickets)
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.Len(t, tickets, 1)
+	assert.Equal(t, ticket.ID, tickets[0].TicketId)
+	assert.Equal(t, ticket.From, tickets[0].Customer)
+	assert.False(t, tickets[0].Reminded())
+
+	assert.NoError(t, s.Tickets(ctx).Remind(ticket.ID, time.Now().UTC()))
+	assert.Equal(t, store.ErrRecordNotFound, s.Tickets(ctx).Remind(ticket.ID+1, time.Now().UTC()))
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.True(t, tickets[0].Reminded())
+
+	// Last message is written by customer
+	reply := models.NewTestTicketMessage(t)
+	reply.TicketId = ticket.ID
+	reply.Who = ticket.From
+	assert.NoError(t, s.Tickets(ctx).AddMessage(reply))
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.Empty(t, tickets)
+}
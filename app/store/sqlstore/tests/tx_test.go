@@ -0,0 +1,79 @@
+package sqlstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_WithTx(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("users", "balance")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	user := models.NewTestUser(t)
+	errAbort := errors.New("abort")
+	err := s.WithTx(ctx, func(tx store.Store) error {
+		assert.NoError(t, tx.User(ctx).Create(user))
+		assert.NoError(t, tx.Balance(ctx).CreateBalance(uint(user.ID)))
+		return errAbort
+	})
+	assert.Equal(t, errAbort, err)
+	_, err = s.User(ctx).FindByEmail(user.Email)
+	assert.Error(t, err)
+
+	user = models.NewTestUser(t)
+	assert.NoError(t, s.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.User(ctx).Create(user); err != nil {
+			return err
+		}
+		return tx.Balance(ctx).CreateBalance(uint(user.ID))
+	}))
+	_, err = s.User(ctx).FindByEmail(user.Email)
+	assert.NoError(t, err)
+	_, err = s.Balance(ctx).LookForBalance(uint(user.ID))
+	assert.NoError(t, err)
+}
+
+func TestStore_TryLock(t *testing.T) {
+	db, _ := sqlstore.TestDb(t, databaseUrl)
+
+	s := sqlstore.New(db)
+	other := sqlstore.New(db)
+	ctx := context.Background()
+
+	ran, err := s.TryLock(ctx, "job", func() error {
+		locked, err := other.TryLock(ctx, "job", func() error { return nil })
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	ran, err = other.TryLock(ctx, "job", func() error { return nil })
+	assert.NoError(t, err)
+	assert.True(t, ran)
+}
+
+func TestStore_RepositoryContext(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("users")
+
+	s := sqlstore.New(db)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.User(canceled).FindById(1)
+	assert.Error(t, err)
+
+	user := models.NewTestUser(t)
+	assert.NoError(t, s.User(context.Background()).Create(user))
+	_, err = s.User(context.Background()).FindById(user.ID)
+	assert.NoError(t, err)
+}
@@ -2,9 +2,11 @@ package sqlstore_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
 	"github.com/inhumanLightBackend/app/store/sqlstore"
 	"github.com/stretchr/testify/assert"
 )
@@ -65,4 +67,79 @@ func TestUserRepository_Update(t *testing.T) {
 	assert.NotNil(t, user1)
 	assert.Equal(t, user1.Login, newLogin)
 	assert.Equal(t, user1.Contacts, newContacts)
-}
\ No newline at end of file
+}
+
+func TestUserRepository_FindByToken(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("users")
+
+	store := sqlstore.New(db)
+	user := models.NewTestUser(t)
+	assert.NoError(t, store.User(context.Background()).Create(user))
+	user1, err := store.User(context.Background()).FindByToken(user.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, user1.ID)
+
+	_, err = store.User(context.Background()).FindByToken("unknown")
+	assert.Error(t, err)
+}
+
+func TestUserRepository_List(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("users")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	for i, email := range []string{"c@gmail.com", "a@gmail.com", "b_@yandex.ru"} {
+		user := models.NewTestUser(t)
+		user.Email = email
+		user.Login = fmt.Sprintf("user%d", i)
+		assert.NoError(t, s.User(ctx).Create(user))
+	}
+
+	filter := &store.UserFilter{SortBy: store.UserSortEmail, Desc: true, Limit: 2}
+	users, err := s.User(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, "c@gmail.com", users[0].Email)
+
+	filter.Cursor = filter.NextCursor(users)
+	users, err = s.User(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "a@gmail.com", users[0].Email)
+
+	filter = &store.UserFilter{Limit: 1}
+	users, err = s.User(ctx).List(filter)
+	assert.NoError(t, err)
+	filter.Cursor = filter.NextCursor(users)
+	users, err = s.User(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, "a@gmail.com", users[0].Email)
+
+	count, err := s.User(ctx).Count(&store.UserFilter{Search: "_"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestUserRepository_FindByLogin(t *testing.T) {
+	db, cleaner := sqlstore.TestDb(t, databaseUrl)
+	defer cleaner("users")
+
+	s := sqlstore.New(db)
+	ctx := context.Background()
+	user := models.NewTestUser(t)
+	assert.NoError(t, s.User(ctx).Create(user))
+
+	u, err := s.User(ctx).FindByLogin("USERNMAE")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, u.ID)
+	u, err = s.User(ctx).FindByLogin(user.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, u.ID)
+
+	other := models.NewTestUser(t)
+	other.Email = "other@gmail.com"
+	other.Login = "usernmae"
+	assert.EqualError(t, s.User(ctx).Create(other), store.ErrUsernameTaken.Error())
+}
@@ -0,0 +1,56 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/inhumanLightBackend/app/models"
+)
+
+// Ticket history repository
+type TicketHistoryRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Create new history entry
+func (repo *TicketHistoryRepository) Create(entry *models.TicketHistory) error {
+	entry.BeforeCreate()
+
+	return repo.store.db.QueryRowContext(
+		repo.ctx,
+		`insert into ticket_history (ticket_id, actor, action, from_helper, to_helper, created_at) 
+		values ($1, $2, $3, $4, $5, $6) returning id`,
+		entry.TicketId,
+		entry.Actor,
+		entry.Action,
+		entry.FromHelper,
+		entry.ToHelper,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+// Find history of ticket in order of changes
+func (repo *TicketHistoryRepository) FindByTicket(ticketId uint) ([]*models.TicketHistory, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`select id, ticket_id, actor, action, from_helper, to_helper, created_at 
+		from ticket_history where ticket_id = $1 order by id`,
+		ticketId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*models.TicketHistory, 0)
+	for rows.Next() {
+		entry := &models.TicketHistory{}
+		if err := rows.Scan(&entry.ID, &entry.TicketId, &entry.Actor, &entry.Action,
+			&entry.FromHelper, &entry.ToHelper, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,47 @@
+package sqlstore
+
+import "context"
+
+// Ticket mute repository
+type TicketMuteRepository struct {
+	store *Store
+	ctx   context.Context
+}
+
+// Mute ticket for user. Muting muted ticket does nothing
+func (repo *TicketMuteRepository) Mute(userId int, ticketId uint) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		`insert into ticket_mutes (user_id, ticket_id, created_at) values ($1, $2, now() at time zone 'utc') 
+		on conflict (user_id, ticket_id) do nothing`,
+		userId,
+		ticketId,
+	)
+
+	return err
+}
+
+// Unmute ticket for user
+func (repo *TicketMuteRepository) Unmute(userId int, ticketId uint) error {
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"delete from ticket_mutes where user_id = $1 and ticket_id = $2",
+		userId,
+		ticketId,
+	)
+
+	return err
+}
+
+// Check if user muted ticket
+func (repo *TicketMuteRepository) IsMuted(userId int, ticketId uint) (bool, error) {
+	var muted bool
+	err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select exists (select 1 from ticket_mutes where user_id = $1 and ticket_id = $2)",
+		userId,
+		ticketId,
+	).Scan(&muted)
+
+	return muted, err
+}
@@ -3,12 +3,19 @@ package sqlstore
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/models/ticketStatus"
 	"github.com/inhumanLightBackend/app/store"
+	"github.com/lib/pq"
 )
 
+// Ticket columns in order of scanTicket
+const ticketColumns = "id, title, description, section, from_user, helper, created_at, status, last_activity_at, priority"
+
 // Ticket repository
 type TicketRepository struct {
 	store *Store
@@ -21,8 +28,8 @@ func (repo *TicketRepository) Create(ticket *models.Ticket) error {
 
 	return repo.store.db.QueryRowContext(
 		repo.ctx,
-		`insert into tickets (title, description, section, from_user, helper, created_at, status) 
-		values ($1, $2, $3, $4, $5, $6, $7) returning id`,
+		`insert into tickets (title, description, section, from_user, helper, created_at, status, last_activity_at, priority) 
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id`,
 		ticket.Title,
 		ticket.Description,
 		ticket.Section,
@@ -30,36 +37,82 @@ func (repo *TicketRepository) Create(ticket *models.Ticket) error {
 		ticket.Helper,
 		ticket.Created_at,
 		ticket.Status,
+		ticket.LastActivityAt,
+		ticket.Priority,
 	).Scan(&ticket.ID)
 }
 
-// Accept ticket by admin
+// Accept not assigned ticket by helper. Returns ErrTicketAssigned
+// if ticket is already taken
 func (repo *TicketRepository) Accept(ticketId uint, helper *models.User) error {
-	_, err := repo.store.db.ExecContext(
+	res, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update tickets set helper = $2, last_activity_at = $3 where id = $1 and helper = -1",
+		ticketId,
+		helper.ID,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if _, err := repo.Find(ticketId); err != nil {
+			return err
+		}
+		return store.ErrTicketAssigned
+	}
+
+	return nil
+}
+
+// Set helper of ticket regardless of current one
+func (repo *TicketRepository) Assign(ticketId uint, helper *models.User) error {
+	res, err := repo.store.db.ExecContext(
 		repo.ctx,
-		"update tickets set helper = $2, status = $3 where id = $1",
+		"update tickets set helper = $2, last_activity_at = $3 where id = $1",
 		ticketId,
 		helper.ID,
-		ticketStatus.InProcess,
+		time.Now().UTC(),
 	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
 
+// Return ticket to the queue of not assigned tickets
+func (repo *TicketRepository) Unassign(ticketId uint) error {
+	res, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update tickets set helper = -1, last_activity_at = $2 where id = $1",
+		ticketId,
+		time.Now().UTC(),
+	)
 	if err != nil {
 		return err
 	}
 
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return store.ErrRecordNotFound
+	}
+
 	return nil
 }
 
 // Find ticket by ticket id
 func (repo *TicketRepository) Find(ticketId uint) (*models.Ticket, error) {
-	ticket := &models.Ticket{}
-
-	if err := repo.store.db.QueryRowContext(
+	ticket, err := scanTicket(repo.store.db.QueryRowContext(
 		repo.ctx,
-		"select id, title, description, section, from_user, helper, created_at, status from tickets where id = $1",
+		"select "+ticketColumns+" from tickets where id = $1",
 		ticketId,
-	).Scan(&ticket.ID, &ticket.Title, &ticket.Description, &ticket.Section, 
-	&ticket.From, &ticket.Helper, &ticket.Created_at, &ticket.Status); err != nil {
+	))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRecordNotFound
 		}
@@ -74,7 +127,7 @@ func (repo *TicketRepository) Find(ticketId uint) (*models.Ticket, error) {
 func (repo *TicketRepository) FindAll(userId uint) ([]*models.Ticket, error) {
 	rows, err := repo.store.db.QueryContext(
 		repo.ctx,
-		"SELECT * FROM tickets where from_user = $1",
+		"select "+ticketColumns+" from tickets where from_user = $1",
 		userId,
 	)
 	if err != nil {
@@ -85,10 +138,8 @@ func (repo *TicketRepository) FindAll(userId uint) ([]*models.Ticket, error) {
 	tickets := make([]*models.Ticket, 0)
 
 	for rows.Next() {
-		ticket := &models.Ticket{}
-		if err := rows.Scan(&ticket.ID, &ticket.Title, &ticket.Description, 
-		&ticket.Section, &ticket.From, &ticket.Helper, 
-		&ticket.Created_at, &ticket.Status); err != nil {
+		ticket, err := scanTicket(rows)
+		if err != nil {
 			return nil, err
 		}
 		tickets = append(tickets, ticket)
@@ -97,49 +148,183 @@ func (repo *TicketRepository) FindAll(userId uint) ([]*models.Ticket, error) {
 	return tickets, nil
 }
 
-// Change ticket proccessing status
-func (repo *TicketRepository) ChangeStatus(ticketId uint, status string) error {
-	switch status {
-	case ticketStatus.Opened: 
-	case ticketStatus.InProcess: 
-	case ticketStatus.Closed:
-	default:
+// Change ticket proccessing status and write it to status history.
+// If From is set, status is changed only if ticket is still in From status.
+// Otherwise From is filled with previous status
+func (repo *TicketRepository) ChangeStatus(change *models.TicketStatusChange) error {
+	if !ticketStatus.IsValid(change.To) {
 		return store.ErrProccessingStatusNotFound
 	}
 
-	_, err := repo.store.db.ExecContext(
+	change.BeforeCreate()
+
+	return repo.store.WithTx(repo.ctx, func(tx store.Store) error {
+		db := tx.(*Store).db
+		if err := db.QueryRowContext(
+			repo.ctx,
+			`with old as (select id, status from tickets where id = $1 for update) 
+			update tickets t set status = $2, last_activity_at = $3 from old 
+			where t.id = old.id and ($4 = '' or old.status = $4) returning old.status`,
+			change.TicketId,
+			change.To,
+			change.CreatedAt,
+			change.From,
+		).Scan(&change.From); err != nil {
+			if err == sql.ErrNoRows {
+				if change.From != "" {
+					return store.ErrStatusChanged
+				}
+				return store.ErrRecordNotFound
+			}
+
+			return err
+		}
+
+		return db.QueryRowContext(
+			repo.ctx,
+			`insert into ticket_status_history (ticket_id, from_status, to_status, actor, comment, created_at) 
+			values ($1, $2, $3, $4, $5, $6) returning id`,
+			change.TicketId,
+			change.From,
+			change.To,
+			change.Actor,
+			change.Comment,
+			change.CreatedAt,
+		).Scan(&change.ID)
+	})
+}
+
+// Add message and change ticket status in one transaction.
+// Status is not changed if change is nil
+func (repo *TicketRepository) Reply(message *models.TicketMessage, change *models.TicketStatusChange) error {
+	return repo.store.WithTx(repo.ctx, func(tx store.Store) error {
+		if err := tx.Tickets(repo.ctx).AddMessage(message); err != nil {
+			return err
+		}
+		if change == nil {
+			return nil
+		}
+
+		return tx.Tickets(repo.ctx).ChangeStatus(change)
+	})
+}
+
+// Find tickets waiting on customer with last message written by someone
+// else before given time. Oldest messages go first
+func (repo *TicketRepository) Stale(before time.Time) ([]*models.StaleTicket, error) {
+	rows, err := repo.store.db.QueryContext(
 		repo.ctx,
-		"update tickets set status = $2 where id = $1",
-		ticketId,
-		status,
+		`select t.id, t.title, t.from_user, t.helper, t.status, m.reply_at, t.reminded_at from tickets t 
+		join lateral (select who, reply_at from ticket_messages where ticket_id = t.id order by reply_at desc, id desc limit 1) m on true 
+		where t.status = any($1) and m.who <> t.from_user and m.reply_at < $2 
+		order by m.reply_at, t.id`,
+		pq.Array(ticketStatus.AwaitingCustomer),
+		before,
 	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := make([]*models.StaleTicket, 0)
+	for rows.Next() {
+		ticket := &models.StaleTicket{}
+		var reminded pq.NullTime
+		if err := rows.Scan(
+			&ticket.TicketId,
+			&ticket.Title,
+			&ticket.Customer,
+			&ticket.Helper,
+			&ticket.Status,
+			&ticket.LastMessageAt,
+			&reminded,
+		); err != nil {
+			return nil, err
+		}
+		if reminded.Valid {
+			ticket.RemindedAt = &reminded.Time
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, rows.Err()
+}
 
+// Save time customer was reminded about ticket
+func (repo *TicketRepository) Remind(ticketId uint, at time.Time) error {
+	res, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update tickets set reminded_at = $2 where id = $1",
+		ticketId,
+		at,
+	)
 	if err != nil {
 		return err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return store.ErrRecordNotFound
+	}
 
 	return nil
 }
 
+// Take status changes of ticket in order of changes
+func (repo *TicketRepository) StatusHistory(ticketId uint) ([]*models.TicketStatusChange, error) {
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`select id, ticket_id, from_status, to_status, actor, comment, created_at 
+		from ticket_status_history where ticket_id = $1 order by id`,
+		ticketId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]*models.TicketStatusChange, 0)
+	for rows.Next() {
+		change := &models.TicketStatusChange{}
+		if err := rows.Scan(&change.ID, &change.TicketId, &change.From, &change.To,
+			&change.Actor, &change.Comment, &change.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
 // Add new message to the ticket
 func (repo *TicketRepository) AddMessage(tm *models.TicketMessage) error {
 	tm.BeforeCreate()
 
-	return repo.store.db.QueryRowContext(
+	if err := repo.store.db.QueryRowContext(
 		repo.ctx,
 		"insert into ticket_messages (who, ticket_id, message_text, reply_at) values ($1, $2, $3, $4) returning id" ,
 		tm.Who,
 		tm.TicketId,
 		tm.Message,
 		tm.Date,
-	).Scan(&tm.ID)
+	).Scan(&tm.ID); err != nil {
+		return err
+	}
+
+	_, err := repo.store.db.ExecContext(
+		repo.ctx,
+		"update tickets set last_activity_at = $2 where id = $1",
+		tm.TicketId,
+		tm.Date,
+	)
+
+	return err
 }
 
 // Take all messages by the ticket id
 func (repo *TicketRepository) TakeMessages(ticketId uint) ([]*models.TicketMessage, error) {
 	rows, err :=repo.store.db.QueryContext(
 		repo.ctx,
-		"select * from ticket_messages where ticket_id = $1",
+		"select id, who, ticket_id, message_text, reply_at from ticket_messages where ticket_id = $1 order by id",
 		ticketId,
 	)
 	if err != nil {
@@ -156,6 +341,181 @@ func (repo *TicketRepository) TakeMessages(ticketId uint) ([]*models.TicketMessa
 
 		messages = append(messages, message)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, store.AttachToMessages(repo.store.Resources(repo.ctx), ticketId, messages)
+}
+
+// Find tickets of all users by filter. Cursor pagination
+func (repo *TicketRepository) List(filter *store.TicketFilter) ([]*models.Ticket, error) {
+	cond := ticketConditions(filter)
+	if len(filter.Status) > 0 {
+		cond.add("status = any(?)", pq.Array(filter.Status))
+	}
+
+	column := "created_at"
+	if filter.SortBy == store.TicketSortLastActivity {
+		column = "last_activity_at"
+	}
+	order, cmp := "asc", ">"
+	if filter.Desc {
+		order, cmp = "desc", "<"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := store.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, store.ErrInvalidCursor
+		}
+		cond.add(fmt.Sprintf("(%s, id) %s (?, ?)", column, cmp), value, cursor.Id)
+	}
+
+	query := "select " + ticketColumns + " from tickets" + cond.where() +
+		fmt.Sprintf(" order by %s %s, id %s", column, order, order)
+	if filter.Limit > 0 {
+		query += " limit " + cond.arg(filter.Limit)
+	}
+
+	rows, err := repo.store.db.QueryContext(repo.ctx, query, cond.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := make([]*models.Ticket, 0)
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
 
-	return messages, nil
-}
\ No newline at end of file
+	return tickets, rows.Err()
+}
+
+// Count tickets per status. Status filter, cursor and limit are ignored
+func (repo *TicketRepository) CountByStatus(filter *store.TicketFilter) (map[string]int, error) {
+	cond := ticketConditions(filter)
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		"select status, count(*) from tickets"+cond.where()+" group by status",
+		cond.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		status, count := "", 0
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// Full text search over tickets and messages, best matches first.
+// Words of query must all be found in ticket or message
+func (repo *TicketRepository) Search(filter *store.SearchFilter) ([]*models.SearchHit, error) {
+	helpers := filter.Helpers
+	if helpers == nil {
+		helpers = make([]int, 0)
+	}
+
+	rows, err := repo.store.db.QueryContext(
+		repo.ctx,
+		`with q as (select plainto_tsquery('simple', $1) as query) 
+		select * from (
+			select t.id, 0, t.title, t.section, t.status, 
+				ts_headline('simple', `+escapeHtml("t.title || ' ' || t.description")+`, q.query, $5), 
+				ts_rank(t.search, q.query), t.created_at 
+			from tickets t, q 
+			where t.search @@ q.query and ($2 or t.from_user = $3 or t.helper = any($4)) 
+			union all 
+			select t.id, m.id, t.title, t.section, t.status, 
+				ts_headline('simple', `+escapeHtml("m.message_text")+`, q.query, $5), 
+				ts_rank(m.search, q.query), m.reply_at 
+			from ticket_messages m join tickets t on t.id = m.ticket_id, q 
+			where m.search @@ q.query and ($2 or t.from_user = $3 or t.helper = any($4)) 
+		) hits order by 7 desc, 8 desc limit nullif($6, 0)`,
+		filter.Query,
+		filter.All,
+		filter.From,
+		pq.Array(helpers),
+		"StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5",
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := make([]*models.SearchHit, 0)
+	for rows.Next() {
+		hit := &models.SearchHit{}
+		if err := rows.Scan(&hit.TicketId, &hit.MessageId, &hit.Title, &hit.Section, &hit.Status,
+			&hit.Snippet, &hit.Rank, &hit.Date); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
+
+// Sql expression escaping html of text expression. Text is escaped before
+// highlighting, so only <mark> of snippet is markup
+func escapeHtml(expr string) string {
+	for _, pair := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&#34;"}, {"'", "&#39;"}} {
+		expr = fmt.Sprintf("replace(%s, '%s', '%s')", expr, strings.ReplaceAll(pair[0], "'", "''"), pair[1])
+	}
+
+	return expr
+}
+
+func ticketConditions(filter *store.TicketFilter) *conditions {
+	cond := &conditions{}
+	if filter.Section != "" {
+		cond.add("section = ?", filter.Section)
+	}
+	if filter.Helper != nil {
+		cond.add("helper = ?", *filter.Helper)
+	}
+	if filter.Helpers != nil {
+		cond.add("helper = any(?)", pq.Array(filter.Helpers))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		cond.add("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		cond.add("created_at < ?", filter.CreatedTo)
+	}
+
+	return cond
+}
+
+// Row of ticketColumns
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTicket(row scanner) (*models.Ticket, error) {
+	ticket := &models.Ticket{}
+	if err := row.Scan(&ticket.ID, &ticket.Title, &ticket.Description, &ticket.Section, &ticket.From,
+		&ticket.Helper, &ticket.Created_at, &ticket.Status, &ticket.LastActivityAt, &ticket.Priority); err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
@@ -3,9 +3,12 @@ package sqlstore
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/store"
+	"github.com/lib/pq"
 )
 
 // User rerpository
@@ -24,7 +27,7 @@ func (repo *UserRepository) Create(newUser *models.User) error {
 		return err
 	}
 	
-	return repo.store.db.QueryRowContext(
+	return userConstraintError(repo.store.db.QueryRowContext(
 		repo.ctx,
 		"insert into users (username, email, encrypted_password, created_at, token, contacts, role, is_active) values ($1, $2, $3, $4, $5, $6, $7, $8) returning id",
 		newUser.Login,
@@ -35,7 +38,7 @@ func (repo *UserRepository) Create(newUser *models.User) error {
 		newUser.Contacts,
 		newUser.Role,
 		newUser.IsActive,
-	).Scan(&newUser.ID)
+	).Scan(&newUser.ID))
 }
 
 // Find user by email
@@ -58,6 +61,26 @@ func (repo *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
+// Find user by email or username. Username is case insensitive
+func (repo *UserRepository) FindByLogin(login string) (*models.User, error) {
+	user := &models.User{}
+
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select * from users where email = $1 or lower(username) = lower($1)",
+		login,
+	).Scan(&user.ID, &user.Login, &user.Email, &user.EncryptedPassword, &user.CreatedAt,
+		&user.Token, &user.Contacts, &user.Role, &user.IsActive); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // Find user by id
 func (repo *UserRepository) FindById(id int) (*models.User, error) {
 	user := &models.User{}
@@ -78,6 +101,26 @@ func (repo *UserRepository) FindById(id int) (*models.User, error) {
 	return user, nil
 }
 
+// Find user by api token
+func (repo *UserRepository) FindByToken(token string) (*models.User, error) {
+	user := &models.User{}
+
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select * from users where token = $1",
+		token,
+	).Scan(&user.ID, &user.Login, &user.Email, &user.EncryptedPassword, &user.CreatedAt,
+		&user.Token, &user.Contacts, &user.Role, &user.IsActive); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // Update user info by new model
 func (repo *UserRepository) Update(user *models.User) error {
 	_, err := repo.store.db.ExecContext(
@@ -102,8 +145,131 @@ func (repo *UserRepository) Update(user *models.User) error {
 			return store.ErrRecordNotFound
 		}
 
-		return err
+		return userConstraintError(err)
 	}
 
 	return nil
 }
+
+
+// Find users by filter. Cursor pagination
+func (repo *UserRepository) List(filter *store.UserFilter) ([]*models.User, error) {
+	cond := userConditions(filter)
+	column := userSortColumn(filter.SortBy)
+	order, cmp := "asc", ">"
+	if filter.Desc {
+		order, cmp = "desc", "<"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := store.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if column == "id" {
+			cond.add("id "+cmp+" ?", cursor.Id)
+		} else {
+			var value interface{} = cursor.Value
+			if column == "created_at" {
+				if value, err = time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+					return nil, store.ErrInvalidCursor
+				}
+			}
+			cond.add(fmt.Sprintf("(%s, id) %s (?, ?)", column, cmp), value, cursor.Id)
+		}
+	}
+
+	query := `select id, username, email, encrypted_password, created_at, token, contacts, role, is_active 
+		from users` + cond.where()
+	if column == "id" {
+		query += " order by id " + order
+	} else {
+		query += fmt.Sprintf(" order by %s %s, id %s", column, order, order)
+	}
+	if filter.Limit > 0 {
+		query += " limit " + cond.arg(filter.Limit)
+	}
+
+	rows, err := repo.store.db.QueryContext(repo.ctx, query, cond.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(&user.ID, &user.Login, &user.Email, &user.EncryptedPassword, &user.CreatedAt,
+			&user.Token, &user.Contacts, &user.Role, &user.IsActive); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// Count users by filter. Cursor and limit are ignored
+func (repo *UserRepository) Count(filter *store.UserFilter) (int, error) {
+	cond := userConditions(filter)
+	count := 0
+	if err := repo.store.db.QueryRowContext(
+		repo.ctx,
+		"select count(*) from users"+cond.where(),
+		cond.args...,
+	).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func userConditions(filter *store.UserFilter) *conditions {
+	cond := &conditions{}
+	if filter.Role != "" {
+		cond.add("role = ?", filter.Role)
+	}
+	if filter.Active != nil {
+		cond.add("is_active = ?", *filter.Active)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		cond.add("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		cond.add("created_at < ?", filter.CreatedTo)
+	}
+	if filter.Search != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Search) + "%"
+		cond.add("(email ilike ? or username ilike ?)", pattern, pattern)
+	}
+
+	return cond
+}
+
+func userSortColumn(sortBy string) string {
+	switch sortBy {
+	case store.UserSortEmail:
+		return "email"
+	case store.UserSortLogin:
+		return "username"
+	case store.UserSortId:
+		return "id"
+	default:
+		return "created_at"
+	}
+}
+
+// Map unique constraint violations of users table to store errors
+func userConstraintError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		switch pqErr.Constraint {
+		case "users_username_lower_idx":
+			return store.ErrUsernameTaken
+		case "users_email_key":
+			return store.ErrEmailTaken
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,80 @@
+package teststore
+
+import (
+	"context"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeAccountDeletionRepository struct {
+	store     *Store
+	ctx       context.Context
+	deletions map[int]*models.AccountDeletion
+}
+
+func (repo *FakeAccountDeletionRepository) Create(deletion *models.AccountDeletion) error {
+	if _, ok := repo.deletions[deletion.UserId]; ok {
+		return store.ErrDeletionPending
+	}
+	deletion.BeforeCreate()
+	repo.deletions[deletion.UserId] = deletion
+
+	return nil
+}
+
+func (repo *FakeAccountDeletionRepository) Find(userId int) (*models.AccountDeletion, error) {
+	deletion, ok := repo.deletions[userId]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return deletion, nil
+}
+
+func (repo *FakeAccountDeletionRepository) Cancel(userId int) error {
+	deletion, ok := repo.deletions[userId]
+	if !ok || deletion.CompletedAt != nil {
+		return store.ErrRecordNotFound
+	}
+	delete(repo.deletions, userId)
+
+	return nil
+}
+
+func (repo *FakeAccountDeletionRepository) FindDue(now time.Time) ([]*models.AccountDeletion, error) {
+	deletions := make([]*models.AccountDeletion, 0)
+	for _, item := range repo.deletions {
+		if item.CompletedAt == nil && !item.PurgeAt.After(now) {
+			deletions = append(deletions, item)
+		}
+	}
+
+	return deletions, nil
+}
+
+func (repo *FakeAccountDeletionRepository) Complete(userId int) error {
+	deletion, ok := repo.deletions[userId]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	now := time.Now().UTC()
+	deletion.CompletedAt = &now
+
+	return nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeAccountDeletionRepository) snapshot() func() {
+	saved := *repo
+	saved.deletions = make(map[int]*models.AccountDeletion, len(repo.deletions))
+	for k, v := range repo.deletions {
+		item := *v
+		saved.deletions[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -0,0 +1,90 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeAuditRepository struct {
+	store   *Store
+	ctx     context.Context
+	entries map[int]*models.AuditLog
+}
+
+func (repo *FakeAuditRepository) Create(entry *models.AuditLog) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	entry.BeforeCreate()
+
+	entry.ID = len(repo.entries) + 1
+	repo.entries[entry.ID] = entry
+
+	return nil
+}
+
+func (repo *FakeAuditRepository) Find(filter *store.AuditFilter) ([]*models.AuditLog, error) {
+	entries := repo.filter(filter)
+	if filter.Offset > 0 {
+		if filter.Offset >= len(entries) {
+			return make([]*models.AuditLog, 0), nil
+		}
+		entries = entries[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(entries) {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, nil
+}
+
+func (repo *FakeAuditRepository) Count(filter *store.AuditFilter) (int, error) {
+	return len(repo.filter(filter)), nil
+}
+
+func (repo *FakeAuditRepository) filter(filter *store.AuditFilter) []*models.AuditLog {
+	entries := make([]*models.AuditLog, 0)
+	for _, item := range repo.entries {
+		if filter.Action != "" && item.Action != filter.Action {
+			continue
+		}
+		if filter.Actor != 0 && item.Actor != filter.Actor {
+			continue
+		}
+		if filter.Target != "" && item.Target != filter.Target {
+			continue
+		}
+		if !filter.From.IsZero() && item.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !item.CreatedAt.Before(filter.To) {
+			continue
+		}
+		if filter.BeforeId != 0 && item.ID >= filter.BeforeId {
+			continue
+		}
+		entries = append(entries, item)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+
+	return entries
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeAuditRepository) snapshot() func() {
+	saved := *repo
+	saved.entries = make(map[int]*models.AuditLog, len(repo.entries))
+	for k, v := range repo.entries {
+		item := *v
+		saved.entries[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -2,8 +2,10 @@ package teststore
 
 import (
 	"context"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
 )
 
 type FakeBalanceRepository struct {
@@ -13,21 +15,81 @@ type FakeBalanceRepository struct {
 }
 
 func (repo *FakeBalanceRepository) CreateBalance(userId uint) error {
+	balance := models.CreateBalance()
+	balance.User = userId
+	balance.ID = uint(len(repo.balances) + 1)
+	repo.balances[int(balance.ID)] = balance
+
 	return nil
 }
 
 func (repo *FakeBalanceRepository) Add(userId uint, value float32) (*models.Balance, error) {
-	return nil, nil
+	return repo.change(userId, value, "Add to balance")
 }
 
 func (repo *FakeBalanceRepository) Remove(userId uint, value float32) (*models.Balance, error) {
-	return nil, nil
+	return repo.change(userId, -value, "Remove from balance")
 }
 
 func (repo *FakeBalanceRepository) LookForBalance(userId uint) (*models.Balance, error) {
-	return nil, nil
+	var last *models.Balance
+	for _, item := range repo.balances {
+		if item.User == userId && (last == nil || item.ID > last.ID) {
+			last = item
+		}
+	}
+	if last == nil {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return last, nil
 }
 
 func (repo *FakeBalanceRepository) AllTransactions(userId uint) ([]models.Balance, error) {
-	return nil, nil
+	transactions := make([]models.Balance, 0)
+	for i := 1; i <= len(repo.balances); i++ {
+		item, ok := repo.balances[i]
+		if ok && item.User == userId {
+			transactions = append(transactions, *item)
+		}
+	}
+
+	return transactions, nil
+}
+
+func (repo *FakeBalanceRepository) change(userId uint, value float32, info string) (*models.Balance, error) {
+	var current float32
+	if last, err := repo.LookForBalance(userId); err == nil {
+		current = last.BalanceNow
+	}
+	if current+value < 0 {
+		return nil, store.ErrInsufficientFunds
+	}
+
+	balance := &models.Balance{
+		ID:          uint(len(repo.balances) + 1),
+		Transaction: value,
+		BalanceNow:  current + value,
+		From:        "Service",
+		Date:        time.Now().UTC(),
+		AddInfo:     info,
+		User:        userId,
+	}
+	repo.balances[int(balance.ID)] = balance
+
+	return balance, nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeBalanceRepository) snapshot() func() {
+	saved := *repo
+	saved.balances = make(map[int]*models.Balance, len(repo.balances))
+	for k, v := range repo.balances {
+		item := *v
+		saved.balances[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
 }
@@ -0,0 +1,104 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeCannedResponseRepository struct {
+	store     *Store
+	ctx       context.Context
+	responses map[int]*models.CannedResponse
+	lastId    int
+}
+
+func (repo *FakeCannedResponseRepository) Create(response *models.CannedResponse) error {
+	if err := response.Validate(); err != nil {
+		return err
+	}
+
+	response.BeforeCreate()
+	repo.lastId++
+	response.ID = repo.lastId
+	repo.responses[response.ID] = response
+
+	return nil
+}
+
+func (repo *FakeCannedResponseRepository) Update(response *models.CannedResponse) error {
+	if err := response.Validate(); err != nil {
+		return err
+	}
+	current, ok := repo.responses[response.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	response.UpdatedAt = time.Now().UTC()
+	current.Title = response.Title
+	current.Body = response.Body
+	current.Status = response.Status
+	current.UpdatedAt = response.UpdatedAt
+
+	return nil
+}
+
+func (repo *FakeCannedResponseRepository) Delete(id int) error {
+	delete(repo.responses, id)
+
+	return nil
+}
+
+func (repo *FakeCannedResponseRepository) Find(id int) (*models.CannedResponse, error) {
+	response, ok := repo.responses[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return response, nil
+}
+
+func (repo *FakeCannedResponseRepository) FindVisible(userId int) ([]*models.CannedResponse, error) {
+	responses := make([]*models.CannedResponse, 0)
+	for _, item := range repo.responses {
+		if item.Shared() || item.Owner == userId {
+			responses = append(responses, item)
+		}
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].UsageCount != responses[j].UsageCount {
+			return responses[i].UsageCount > responses[j].UsageCount
+		}
+		return responses[i].ID < responses[j].ID
+	})
+
+	return responses, nil
+}
+
+func (repo *FakeCannedResponseRepository) Used(id int) error {
+	response, ok := repo.responses[id]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	response.UsageCount++
+
+	return nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeCannedResponseRepository) snapshot() func() {
+	saved := *repo
+	saved.responses = make(map[int]*models.CannedResponse, len(repo.responses))
+	for k, v := range repo.responses {
+		item := *v
+		saved.responses[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -0,0 +1,100 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeContactRepository struct {
+	store    *Store
+	ctx      context.Context
+	contacts map[int]*models.Contact
+	lastId   int
+}
+
+func (repo *FakeContactRepository) Create(contact *models.Contact) error {
+	if err := contact.Validate(); err != nil {
+		return err
+	}
+
+	for _, item := range repo.contacts {
+		if item.UserId == contact.UserId && item.Kind == contact.Kind && item.Value == contact.Value {
+			return store.ErrContactExists
+		}
+	}
+
+	if err := contact.BeforeCreate(); err != nil {
+		return err
+	}
+
+	repo.lastId++
+	contact.ID = repo.lastId
+	repo.contacts[contact.ID] = contact
+
+	return nil
+}
+
+func (repo *FakeContactRepository) Find(id int) (*models.Contact, error) {
+	contact, ok := repo.contacts[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return contact, nil
+}
+
+func (repo *FakeContactRepository) FindByUser(userId int) ([]*models.Contact, error) {
+	contacts := make([]*models.Contact, 0)
+	for _, item := range repo.contacts {
+		if item.UserId == userId {
+			contacts = append(contacts, item)
+		}
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].ID < contacts[j].ID
+	})
+
+	return contacts, nil
+}
+
+func (repo *FakeContactRepository) Update(contact *models.Contact) error {
+	if _, ok := repo.contacts[contact.ID]; !ok {
+		return store.ErrRecordNotFound
+	}
+	repo.contacts[contact.ID] = contact
+
+	return nil
+}
+
+func (repo *FakeContactRepository) CountAttempt(id int) (int, error) {
+	contact, ok := repo.contacts[id]
+	if !ok {
+		return 0, store.ErrRecordNotFound
+	}
+	contact.CodeAttempts++
+
+	return contact.CodeAttempts, nil
+}
+
+func (repo *FakeContactRepository) Delete(id int) error {
+	delete(repo.contacts, id)
+
+	return nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeContactRepository) snapshot() func() {
+	saved := *repo
+	saved.contacts = make(map[int]*models.Contact, len(repo.contacts))
+	for k, v := range repo.contacts {
+		item := *v
+		saved.contacts[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -31,6 +31,17 @@ func (repo *FakeNotificationRepository) FindById(userId uint) ([]*models.Notific
 	return notifications, nil
 }
 
+func (repo *FakeNotificationRepository) FindAll(userId uint) ([]*models.Notification, error) {
+	notifications := make([]*models.Notification, 0)
+	for _, item := range repo.notifications {
+		if item.For == int(userId) {
+			notifications = append(notifications, item)
+		}
+	}
+
+	return notifications, nil
+}
+
 func (repo *FakeNotificationRepository) Check(notifications []int, userId uint) error {
 	for _, n := range notifications {
 		for _, item := range repo.notifications {
@@ -42,3 +53,17 @@ func (repo *FakeNotificationRepository) Check(notifications []int, userId uint)
 
 	return nil
 }
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeNotificationRepository) snapshot() func() {
+	saved := *repo
+	saved.notifications = make(map[int]*models.Notification, len(repo.notifications))
+	for k, v := range repo.notifications {
+		item := *v
+		saved.notifications[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -0,0 +1,108 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeResourceRepository struct {
+	store     *Store
+	ctx       context.Context
+	resources map[int]*models.Resource
+	lastId    int
+}
+
+func (repo *FakeResourceRepository) Create(res *models.Resource) error {
+	if err := res.Validate(); err != nil {
+		return err
+	}
+
+	res.BeforeCreate()
+	repo.lastId++
+	res.ID = repo.lastId
+	repo.resources[res.ID] = res
+
+	return nil
+}
+
+func (repo *FakeResourceRepository) Find(id int) (*models.Resource, error) {
+	res, ok := repo.resources[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return res, nil
+}
+
+func (repo *FakeResourceRepository) FindByOwner(owner int) ([]*models.Resource, error) {
+	return repo.filter(func(res *models.Resource) bool {
+		return res.Owner == owner
+	}), nil
+}
+
+func (repo *FakeResourceRepository) FindByTicket(ticketId uint) ([]*models.Resource, error) {
+	return repo.filter(func(res *models.Resource) bool {
+		return res.TicketId == ticketId
+	}), nil
+}
+
+func (repo *FakeResourceRepository) Attach(owner int, ticketId uint, messageId uint, ids []int) error {
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		res, ok := repo.resources[id]
+		if !ok || seen[id] || res.Owner != owner || res.MessageId != 0 || (res.TicketId != 0 && res.TicketId != ticketId) {
+			return store.ErrRecordNotFound
+		}
+		seen[id] = true
+	}
+	for _, id := range ids {
+		repo.resources[id].TicketId = ticketId
+		repo.resources[id].MessageId = messageId
+	}
+
+	return nil
+}
+
+func (repo *FakeResourceRepository) Delete(id int) error {
+	res, ok := repo.resources[id]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if res.TicketId != 0 {
+		return store.ErrResourceAttached
+	}
+	delete(repo.resources, id)
+
+	return nil
+}
+
+func (repo *FakeResourceRepository) filter(fn func(*models.Resource) bool) []*models.Resource {
+	resources := make([]*models.Resource, 0)
+	for _, item := range repo.resources {
+		if fn(item) {
+			resources = append(resources, item)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID < resources[j].ID
+	})
+
+	return resources
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeResourceRepository) snapshot() func() {
+	saved := *repo
+	saved.resources = make(map[int]*models.Resource, len(repo.resources))
+	for k, v := range repo.resources {
+		item := *v
+		saved.resources[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -0,0 +1,129 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeSectionRepository struct {
+	store    *Store
+	ctx      context.Context
+	sections map[int]*models.Section
+	lastId   int
+}
+
+func (repo *FakeSectionRepository) Create(section *models.Section) error {
+	if err := section.Validate(); err != nil {
+		return err
+	}
+	if repo.nameTaken(section) {
+		return store.ErrSectionExists
+	}
+
+	section.BeforeCreate()
+	repo.lastId++
+	section.ID = repo.lastId
+	repo.sections[section.ID] = section
+
+	return nil
+}
+
+func (repo *FakeSectionRepository) Update(section *models.Section) error {
+	if err := section.Validate(); err != nil {
+		return err
+	}
+	current, ok := repo.sections[section.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if repo.nameTaken(section) {
+		return store.ErrSectionExists
+	}
+	current.Name = section.Name
+	current.Description = section.Description
+	current.Priority = section.Priority
+	current.Routing = section.Routing
+	current.Helpers = append([]int{}, section.Helpers...)
+
+	return nil
+}
+
+func (repo *FakeSectionRepository) Delete(id int) error {
+	delete(repo.sections, id)
+
+	return nil
+}
+
+func (repo *FakeSectionRepository) Find(id int) (*models.Section, error) {
+	section, ok := repo.sections[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return section, nil
+}
+
+// Fake store has no concurrent transactions, nothing to lock
+func (repo *FakeSectionRepository) FindForUpdate(id int) (*models.Section, error) {
+	return repo.Find(id)
+}
+
+func (repo *FakeSectionRepository) FindByName(name string) (*models.Section, error) {
+	for _, item := range repo.sections {
+		if item.Name == name {
+			return item, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
+func (repo *FakeSectionRepository) List() ([]*models.Section, error) {
+	sections := make([]*models.Section, 0, len(repo.sections))
+	for _, item := range repo.sections {
+		sections = append(sections, item)
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].Name < sections[j].Name
+	})
+
+	return sections, nil
+}
+
+func (repo *FakeSectionRepository) SetLastHelper(id int, helperId int) error {
+	section, ok := repo.sections[id]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	section.LastHelper = helperId
+
+	return nil
+}
+
+func (repo *FakeSectionRepository) nameTaken(section *models.Section) bool {
+	for _, item := range repo.sections {
+		if item.Name == section.Name && item.ID != section.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeSectionRepository) snapshot() func() {
+	saved := *repo
+	saved.sections = make(map[int]*models.Section, len(repo.sections))
+	for k, v := range repo.sections {
+		item := *v
+		item.Helpers = append([]int{}, v.Helpers...)
+		saved.sections[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
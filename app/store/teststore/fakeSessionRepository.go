@@ -0,0 +1,75 @@
+package teststore
+
+import (
+	"context"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeSessionRepository struct {
+	store    *Store
+	ctx      context.Context
+	sessions map[string]*models.Session
+}
+
+func (repo *FakeSessionRepository) Create(session *models.Session) error {
+	if err := session.BeforeCreate(); err != nil {
+		return err
+	}
+	repo.sessions[session.ID] = session
+
+	return nil
+}
+
+func (repo *FakeSessionRepository) Find(id string) (*models.Session, error) {
+	session, ok := repo.sessions[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return session, nil
+}
+
+func (repo *FakeSessionRepository) FindByUser(userId int) ([]*models.Session, error) {
+	sessions := make([]*models.Session, 0)
+	for _, item := range repo.sessions {
+		if item.UserId == userId {
+			sessions = append(sessions, item)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (repo *FakeSessionRepository) Revoke(id string) error {
+	if session, ok := repo.sessions[id]; ok {
+		session.Revoked = true
+	}
+
+	return nil
+}
+
+func (repo *FakeSessionRepository) RevokeAll(userId int, except string) error {
+	for _, item := range repo.sessions {
+		if item.UserId == userId && item.ID != except {
+			item.Revoked = true
+		}
+	}
+
+	return nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeSessionRepository) snapshot() func() {
+	saved := *repo
+	saved.sessions = make(map[string]*models.Session, len(repo.sessions))
+	for k, v := range repo.sessions {
+		item := *v
+		saved.sessions[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -0,0 +1,180 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
+)
+
+type FakeSlaRepository struct {
+	store        *Store
+	ctx          context.Context
+	policies     map[int]*models.SlaPolicy
+	breaches     map[int]*models.SlaBreach
+	lastPolicyId int
+	lastBreachId int
+}
+
+func (repo *FakeSlaRepository) CreatePolicy(policy *models.SlaPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if repo.sectionTaken(policy) {
+		return store.ErrSlaPolicyExists
+	}
+
+	policy.BeforeCreate()
+	repo.lastPolicyId++
+	policy.ID = repo.lastPolicyId
+	repo.policies[policy.ID] = policy
+
+	return nil
+}
+
+func (repo *FakeSlaRepository) UpdatePolicy(policy *models.SlaPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	current, ok := repo.policies[policy.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if repo.sectionTaken(policy) {
+		return store.ErrSlaPolicyExists
+	}
+	current.Section = policy.Section
+	current.FirstResponseMinutes = policy.FirstResponseMinutes
+	current.ResolutionMinutes = policy.ResolutionMinutes
+
+	return nil
+}
+
+func (repo *FakeSlaRepository) DeletePolicy(id int) error {
+	delete(repo.policies, id)
+
+	return nil
+}
+
+func (repo *FakeSlaRepository) FindPolicy(id int) (*models.SlaPolicy, error) {
+	policy, ok := repo.policies[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return policy, nil
+}
+
+func (repo *FakeSlaRepository) Policies() ([]*models.SlaPolicy, error) {
+	policies := make([]*models.SlaPolicy, 0)
+	for _, item := range repo.policies {
+		policies = append(policies, item)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Section < policies[j].Section
+	})
+
+	return policies, nil
+}
+
+func (repo *FakeSlaRepository) Timelines(from, to time.Time) ([]*models.TicketTimeline, error) {
+	tickets := repo.store.Tickets(repo.ctx).(*FakeTicketRepository)
+	timelines := make([]*models.TicketTimeline, 0)
+	for _, ticket := range tickets.tickets {
+		if (!from.IsZero() && ticket.Created_at.Before(from)) || (!to.IsZero() && !ticket.Created_at.Before(to)) {
+			continue
+		}
+
+		timeline := &models.TicketTimeline{
+			TicketId:  ticket.ID,
+			Section:   ticket.Section,
+			CreatedAt: ticket.Created_at,
+		}
+		for _, message := range tickets.ticketMessages {
+			if message.TicketId == ticket.ID && message.Who != ticket.From {
+				timeline.FirstResponseAt = earliest(timeline.FirstResponseAt, message.Date)
+			}
+		}
+		for _, change := range tickets.statusHistory {
+			if change.TicketId == ticket.ID && (change.To == ticketStatus.Resolved || change.To == ticketStatus.Closed) {
+				timeline.ResolvedAt = earliest(timeline.ResolvedAt, change.CreatedAt)
+			}
+		}
+		timelines = append(timelines, timeline)
+	}
+	sort.Slice(timelines, func(i, j int) bool {
+		return timelines[i].TicketId < timelines[j].TicketId
+	})
+
+	return timelines, nil
+}
+
+func (repo *FakeSlaRepository) CreateBreach(breach *models.SlaBreach) error {
+	for _, item := range repo.breaches {
+		if item.TicketId == breach.TicketId && item.Kind == breach.Kind {
+			return store.ErrBreachRegistered
+		}
+	}
+
+	breach.BeforeCreate()
+	repo.lastBreachId++
+	breach.ID = repo.lastBreachId
+	repo.breaches[breach.ID] = breach
+
+	return nil
+}
+
+func (repo *FakeSlaRepository) Breaches(from, to time.Time) ([]*models.SlaBreach, error) {
+	breaches := make([]*models.SlaBreach, 0)
+	for _, item := range repo.breaches {
+		if (!from.IsZero() && item.BreachedAt.Before(from)) || (!to.IsZero() && !item.BreachedAt.Before(to)) {
+			continue
+		}
+		breaches = append(breaches, item)
+	}
+	sort.Slice(breaches, func(i, j int) bool {
+		return breaches[i].ID < breaches[j].ID
+	})
+
+	return breaches, nil
+}
+
+func (repo *FakeSlaRepository) sectionTaken(policy *models.SlaPolicy) bool {
+	for _, item := range repo.policies {
+		if item.Section == policy.Section && item.ID != policy.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func earliest(current *time.Time, t time.Time) *time.Time {
+	if current == nil || t.Before(*current) {
+		return &t
+	}
+
+	return current
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeSlaRepository) snapshot() func() {
+	saved := *repo
+	saved.policies = make(map[int]*models.SlaPolicy, len(repo.policies))
+	for k, v := range repo.policies {
+		item := *v
+		saved.policies[k] = &item
+	}
+	saved.breaches = make(map[int]*models.SlaBreach, len(repo.breaches))
+	for k, v := range repo.breaches {
+		item := *v
+		saved.breaches[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
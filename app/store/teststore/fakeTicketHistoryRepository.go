@@ -0,0 +1,52 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/inhumanLightBackend/app/models"
+)
+
+type FakeTicketHistoryRepository struct {
+	store   *Store
+	ctx     context.Context
+	entries map[int]*models.TicketHistory
+	lastId  int
+}
+
+func (repo *FakeTicketHistoryRepository) Create(entry *models.TicketHistory) error {
+	entry.BeforeCreate()
+	repo.lastId++
+	entry.ID = repo.lastId
+	repo.entries[entry.ID] = entry
+
+	return nil
+}
+
+func (repo *FakeTicketHistoryRepository) FindByTicket(ticketId uint) ([]*models.TicketHistory, error) {
+	entries := make([]*models.TicketHistory, 0)
+	for _, item := range repo.entries {
+		if item.TicketId == ticketId {
+			entries = append(entries, item)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries, nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeTicketHistoryRepository) snapshot() func() {
+	saved := *repo
+	saved.entries = make(map[int]*models.TicketHistory, len(repo.entries))
+	for k, v := range repo.entries {
+		item := *v
+		saved.entries[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -0,0 +1,43 @@
+package teststore
+
+import "context"
+
+type ticketMute struct {
+	userId   int
+	ticketId uint
+}
+
+type FakeTicketMuteRepository struct {
+	store *Store
+	ctx   context.Context
+	mutes map[ticketMute]bool
+}
+
+func (repo *FakeTicketMuteRepository) Mute(userId int, ticketId uint) error {
+	repo.mutes[ticketMute{userId, ticketId}] = true
+
+	return nil
+}
+
+func (repo *FakeTicketMuteRepository) Unmute(userId int, ticketId uint) error {
+	delete(repo.mutes, ticketMute{userId, ticketId})
+
+	return nil
+}
+
+func (repo *FakeTicketMuteRepository) IsMuted(userId int, ticketId uint) (bool, error) {
+	return repo.mutes[ticketMute{userId, ticketId}], nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeTicketMuteRepository) snapshot() func() {
+	saved := *repo
+	saved.mutes = make(map[ticketMute]bool, len(repo.mutes))
+	for k, v := range repo.mutes {
+		saved.mutes[k] = v
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -2,7 +2,10 @@ package teststore
 
 import (
 	"context"
-	"errors"
+	"html"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/models/ticketStatus"
@@ -14,6 +17,8 @@ type FakeTicketRepository struct {
 	ctx            context.Context
 	tickets        map[int]*models.Ticket
 	ticketMessages map[int]*models.TicketMessage
+	statusHistory  map[int]*models.TicketStatusChange
+	reminders      map[uint]time.Time
 }
 
 func (repo *FakeTicketRepository) Create(ticket *models.Ticket) error {
@@ -27,7 +32,35 @@ func (repo *FakeTicketRepository) Create(ticket *models.Ticket) error {
 }
 
 func (repo *FakeTicketRepository) Accept(ticketId uint, helper *models.User) error {
-	repo.tickets[int(ticketId)].Helper = helper.ID
+	ticket, ok := repo.tickets[int(ticketId)]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	if ticket.Helper != -1 {
+		return store.ErrTicketAssigned
+	}
+
+	return repo.Assign(ticketId, helper)
+}
+
+func (repo *FakeTicketRepository) Assign(ticketId uint, helper *models.User) error {
+	ticket, ok := repo.tickets[int(ticketId)]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	ticket.Helper = helper.ID
+	ticket.LastActivityAt = time.Now().UTC()
+
+	return nil
+}
+
+func (repo *FakeTicketRepository) Unassign(ticketId uint) error {
+	ticket, ok := repo.tickets[int(ticketId)]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+	ticket.Helper = -1
+	ticket.LastActivityAt = time.Now().UTC()
 
 	return nil
 }
@@ -35,7 +68,7 @@ func (repo *FakeTicketRepository) Accept(ticketId uint, helper *models.User) err
 func (repo *FakeTicketRepository) Find(ticketId uint) (*models.Ticket, error) {
 	ticket, ok := repo.tickets[int(ticketId)]
 	if !ok {
-		return nil, errors.New("Not found")
+		return nil, store.ErrRecordNotFound
 	}
 	return ticket, nil
 }
@@ -51,31 +84,113 @@ func (repo *FakeTicketRepository) FindAll(userId uint) ([]*models.Ticket, error)
 	return tickets, nil
 }
 
-func (repo *FakeTicketRepository) ChangeStatus(ticketId uint, status string) error {
-	switch status {
-	case ticketStatus.Opened:
-	case ticketStatus.InProcess:
-	case ticketStatus.Closed:
-	default:
+func (repo *FakeTicketRepository) ChangeStatus(change *models.TicketStatusChange) error {
+	if !ticketStatus.IsValid(change.To) {
 		return store.ErrProccessingStatusNotFound
 	}
 
-	el, ok := repo.tickets[int(ticketId)]
+	el, ok := repo.tickets[int(change.TicketId)]
 	if !ok {
-		return errors.New("Not found")
+		return store.ErrRecordNotFound
+	}
+	if change.From != "" && change.From != el.Status {
+		return store.ErrStatusChanged
+	}
+
+	change.BeforeCreate()
+	change.From = el.Status
+	el.Status = change.To
+	el.LastActivityAt = change.CreatedAt
+	change.ID = len(repo.statusHistory) + 1
+	repo.statusHistory[change.ID] = change
+
+	return nil
+}
+
+func (repo *FakeTicketRepository) Reply(message *models.TicketMessage, change *models.TicketStatusChange) error {
+	return repo.store.WithTx(repo.ctx, func(tx store.Store) error {
+		if err := tx.Tickets(repo.ctx).AddMessage(message); err != nil {
+			return err
+		}
+		if change == nil {
+			return nil
+		}
+
+		return tx.Tickets(repo.ctx).ChangeStatus(change)
+	})
+}
+
+func (repo *FakeTicketRepository) Stale(before time.Time) ([]*models.StaleTicket, error) {
+	last := make(map[uint]*models.TicketMessage)
+	for _, message := range repo.ticketMessages {
+		if item, ok := last[message.TicketId]; !ok || message.Date.After(item.Date) ||
+			(message.Date.Equal(item.Date) && message.ID > item.ID) {
+			last[message.TicketId] = message
+		}
+	}
+
+	tickets := make([]*models.StaleTicket, 0)
+	for _, ticket := range repo.tickets {
+		message, ok := last[ticket.ID]
+		if !ok || message.Who == ticket.From || !message.Date.Before(before) || !awaitingCustomer(ticket.Status) {
+			continue
+		}
+
+		stale := &models.StaleTicket{
+			TicketId:      ticket.ID,
+			Title:         ticket.Title,
+			Customer:      ticket.From,
+			Helper:        ticket.Helper,
+			Status:        ticket.Status,
+			LastMessageAt: message.Date,
+		}
+		if reminded, ok := repo.reminders[ticket.ID]; ok {
+			stale.RemindedAt = &reminded
+		}
+		tickets = append(tickets, stale)
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		if !tickets[i].LastMessageAt.Equal(tickets[j].LastMessageAt) {
+			return tickets[i].LastMessageAt.Before(tickets[j].LastMessageAt)
+		}
+		return tickets[i].TicketId < tickets[j].TicketId
+	})
+
+	return tickets, nil
+}
+
+func (repo *FakeTicketRepository) Remind(ticketId uint, at time.Time) error {
+	if _, ok := repo.tickets[int(ticketId)]; !ok {
+		return store.ErrRecordNotFound
 	}
-	el.Status = status
-	repo.tickets[int(ticketId)] = el
+	repo.reminders[ticketId] = at
 
 	return nil
 }
 
+func (repo *FakeTicketRepository) StatusHistory(ticketId uint) ([]*models.TicketStatusChange, error) {
+	changes := make([]*models.TicketStatusChange, 0)
+	for _, item := range repo.statusHistory {
+		if item.TicketId == ticketId {
+			changes = append(changes, item)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ID < changes[j].ID
+	})
+
+	return changes, nil
+}
+
 func (repo *FakeTicketRepository) AddMessage(tm *models.TicketMessage) error {
 	tm.BeforeCreate()
 
 	nextId := len(repo.ticketMessages) + 1
 	tm.ID = uint(nextId)
 	repo.ticketMessages[nextId] = tm
+	if ticket, ok := repo.tickets[int(tm.TicketId)]; ok {
+		ticket.LastActivityAt = tm.Date
+	}
 
 	return nil
 }
@@ -87,6 +202,248 @@ func (repo *FakeTicketRepository) TakeMessages(ticketId uint) ([]*models.TicketM
 			messages = append(messages, item)
 		}
 	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+
+	return messages, store.AttachToMessages(repo.store.Resources(repo.ctx), ticketId, messages)
+}
+
+func (repo *FakeTicketRepository) List(filter *store.TicketFilter) ([]*models.Ticket, error) {
+	statuses := make(map[string]bool)
+	for _, status := range filter.Status {
+		statuses[status] = true
+	}
+
+	tickets := make([]*models.Ticket, 0)
+	for _, item := range repo.filter(filter) {
+		if len(statuses) == 0 || statuses[item.Status] {
+			tickets = append(tickets, item)
+		}
+	}
+	less := func(a, b *models.Ticket) bool {
+		va, vb := filter.SortValue(a), filter.SortValue(b)
+		if !va.Equal(vb) {
+			return va.Before(vb)
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		if filter.Desc {
+			return less(tickets[j], tickets[i])
+		}
+		return less(tickets[i], tickets[j])
+	})
+
+	if filter.Cursor != "" {
+		cursor, err := store.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, store.ErrInvalidCursor
+		}
+
+		last := &models.Ticket{ID: uint(cursor.Id), Created_at: value, LastActivityAt: value}
+		for i, item := range tickets {
+			if filter.Desc && less(item, last) || !filter.Desc && less(last, item) {
+				tickets = tickets[i:]
+				break
+			}
+			if i == len(tickets)-1 {
+				tickets = tickets[:0]
+			}
+		}
+	}
+	if filter.Limit > 0 && filter.Limit < len(tickets) {
+		tickets = tickets[:filter.Limit]
+	}
+
+	return tickets, nil
+}
+
+func (repo *FakeTicketRepository) CountByStatus(filter *store.TicketFilter) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, item := range repo.filter(filter) {
+		counts[item.Status]++
+	}
+
+	return counts, nil
+}
+
+func (repo *FakeTicketRepository) filter(filter *store.TicketFilter) []*models.Ticket {
+	tickets := make([]*models.Ticket, 0)
+	for _, item := range repo.tickets {
+		if filter.Section != "" && item.Section != filter.Section {
+			continue
+		}
+		if filter.Helper != nil && item.Helper != *filter.Helper {
+			continue
+		}
+		if filter.Helpers != nil && !containsHelper(filter.Helpers, item.Helper) {
+			continue
+		}
+		if !filter.CreatedFrom.IsZero() && item.Created_at.Before(filter.CreatedFrom) {
+			continue
+		}
+		if !filter.CreatedTo.IsZero() && !item.Created_at.Before(filter.CreatedTo) {
+			continue
+		}
+		tickets = append(tickets, item)
+	}
+
+	return tickets
+}
+
+// Substring search instead of full text search. Text matches if it
+// contains every word of query ignoring case, rank is count of occurrences
+func (repo *FakeTicketRepository) Search(filter *store.SearchFilter) ([]*models.SearchHit, error) {
+	words := strings.Fields(strings.ToLower(filter.Query))
+	hits := make([]*models.SearchHit, 0)
+	if len(words) == 0 {
+		return hits, nil
+	}
+
+	for _, ticket := range repo.tickets {
+		if !filter.Visible(ticket) {
+			continue
+		}
+
+		if snippet, rank := match(ticket.Title+" "+ticket.Description, words); rank > 0 {
+			hits = append(hits, &models.SearchHit{
+				TicketId: ticket.ID,
+				Title:    ticket.Title,
+				Section:  ticket.Section,
+				Status:   ticket.Status,
+				Snippet:  snippet,
+				Rank:     rank,
+				Date:     ticket.Created_at,
+			})
+		}
+		for _, message := range repo.ticketMessages {
+			if message.TicketId != ticket.ID {
+				continue
+			}
+			if snippet, rank := match(message.Message, words); rank > 0 {
+				hits = append(hits, &models.SearchHit{
+					TicketId:  ticket.ID,
+					MessageId: message.ID,
+					Title:     ticket.Title,
+					Section:   ticket.Section,
+					Status:    ticket.Status,
+					Snippet:   snippet,
+					Rank:      rank,
+					Date:      message.Date,
+				})
+			}
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Date.After(hits[j].Date)
+	})
+	if filter.Limit > 0 && len(hits) > filter.Limit {
+		hits = hits[:filter.Limit]
+	}
+
+	return hits, nil
+}
+
+// Escaped text with occurrences of words wrapped in <mark></mark> and count of
+// occurrences. Count is 0 if any word is missing
+func match(text string, words []string) (string, float64) {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Case changed byte length, positions would not match
+		lower = text
+	}
+	marked := make([]bool, len(text))
+	count := 0
+	for _, word := range words {
+		found := false
+		for start := 0; ; {
+			i := strings.Index(lower[start:], word)
+			if i == -1 {
+				break
+			}
+			found = true
+			count++
+			for k := start + i; k < start+i+len(word); k++ {
+				marked[k] = true
+			}
+			start += i + len(word)
+		}
+		if !found {
+			return "", 0
+		}
+	}
+
+	// Runs of marked and not marked text are escaped separately
+	snippet := &strings.Builder{}
+	for start := 0; start < len(text); {
+		end := start + 1
+		for end < len(text) && marked[end] == marked[start] {
+			end++
+		}
+		part := html.EscapeString(text[start:end])
+		if marked[start] {
+			part = "<mark>" + part + "</mark>"
+		}
+		snippet.WriteString(part)
+		start = end
+	}
+
+	return snippet.String(), float64(count)
+}
+
+func awaitingCustomer(status string) bool {
+	for _, item := range ticketStatus.AwaitingCustomer {
+		if item == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeTicketRepository) snapshot() func() {
+	saved := *repo
+	saved.tickets = make(map[int]*models.Ticket, len(repo.tickets))
+	for k, v := range repo.tickets {
+		item := *v
+		saved.tickets[k] = &item
+	}
+	saved.ticketMessages = make(map[int]*models.TicketMessage, len(repo.ticketMessages))
+	for k, v := range repo.ticketMessages {
+		item := *v
+		saved.ticketMessages[k] = &item
+	}
+	saved.statusHistory = make(map[int]*models.TicketStatusChange, len(repo.statusHistory))
+	for k, v := range repo.statusHistory {
+		item := *v
+		saved.statusHistory[k] = &item
+	}
+	saved.reminders = make(map[uint]time.Time, len(repo.reminders))
+	for k, v := range repo.reminders {
+		saved.reminders[k] = v
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
+
+func containsHelper(helpers []int, helper int) bool {
+	for _, id := range helpers {
+		if id == helper {
+			return true
+		}
+	}
 
-	return messages, nil
+	return false
 }
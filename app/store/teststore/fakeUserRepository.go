@@ -2,6 +2,8 @@ package teststore
 
 import (
 	"context"
+	"sort"
+	"strings"
 
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/store"
@@ -18,6 +20,10 @@ func (repo *FakeUserRepository) Create(newUser *models.User) error {
 		return err
 	}
 
+	if err := repo.checkUnique(newUser); err != nil {
+		return err
+	}
+
 	if err := newUser.BeforeCreate(); err != nil {
 		return err
 	}
@@ -37,6 +43,16 @@ func (repo *FakeUserRepository) FindByEmail(email string) (*models.User, error)
 
 	return nil, store.ErrRecordNotFound
 }
+func (repo *FakeUserRepository) FindByLogin(login string) (*models.User, error) {
+	for _, u := range repo.users {
+		if u.Email == login || strings.EqualFold(u.Login, login) {
+			return u, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
 func (repo *FakeUserRepository) FindById(id int) (*models.User, error) {
 	user, ok := repo.users[id]
 	if !ok {
@@ -45,12 +61,127 @@ func (repo *FakeUserRepository) FindById(id int) (*models.User, error) {
 
 	return user, nil
 }
+
+func (repo *FakeUserRepository) FindByToken(token string) (*models.User, error) {
+	for _, u := range repo.users {
+		if u.Token == token {
+			return u, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
 func (repo *FakeUserRepository) Update(user *models.User) error {
 	_, err := repo.FindById(user.ID)
 	if err != nil {
 		return err
 	}
+	if err := repo.checkUnique(user); err != nil {
+		return err
+	}
 	repo.users[user.ID] = user
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (repo *FakeUserRepository) List(filter *store.UserFilter) ([]*models.User, error) {
+	users := repo.filter(filter)
+	less := func(a, b *models.User) bool {
+		va, vb := filter.SortValue(a), filter.SortValue(b)
+		if filter.SortBy == store.UserSortCreatedAt || filter.SortBy == "" {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		} else if va != vb {
+			return va < vb
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if filter.Desc {
+			return less(users[j], users[i])
+		}
+		return less(users[i], users[j])
+	})
+
+	if filter.Cursor != "" {
+		cursor, err := store.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, u := range users {
+			if filter.SortValue(u) == cursor.Value && u.ID == cursor.Id {
+				users = users[i+1:]
+				break
+			}
+		}
+	}
+	if filter.Limit > 0 && filter.Limit < len(users) {
+		users = users[:filter.Limit]
+	}
+
+	return users, nil
+}
+
+func (repo *FakeUserRepository) Count(filter *store.UserFilter) (int, error) {
+	return len(repo.filter(filter)), nil
+}
+
+func (repo *FakeUserRepository) filter(filter *store.UserFilter) []*models.User {
+	search := strings.ToLower(filter.Search)
+	users := make([]*models.User, 0)
+	for _, u := range repo.users {
+		if filter.Role != "" && u.Role != filter.Role {
+			continue
+		}
+		if filter.Active != nil && u.IsActive != *filter.Active {
+			continue
+		}
+		if !filter.CreatedFrom.IsZero() && u.CreatedAt.Before(filter.CreatedFrom) {
+			continue
+		}
+		if !filter.CreatedTo.IsZero() && !u.CreatedAt.Before(filter.CreatedTo) {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(u.Email), search) &&
+			!strings.Contains(strings.ToLower(u.Login), search) {
+			continue
+		}
+		users = append(users, u)
+	}
+
+	return users
+}
+
+func (repo *FakeUserRepository) checkUnique(user *models.User) error {
+	for _, u := range repo.users {
+		if u.ID == user.ID {
+			continue
+		}
+		if u.Email == user.Email {
+			return store.ErrEmailTaken
+		}
+		if strings.EqualFold(u.Login, user.Login) {
+			return store.ErrUsernameTaken
+		}
+	}
+
+	return nil
+}
+
+// Copy state of repository. Returned func restores it
+func (repo *FakeUserRepository) snapshot() func() {
+	saved := *repo
+	saved.users = make(map[int]*models.User, len(repo.users))
+	for k, v := range repo.users {
+		item := *v
+		saved.users[k] = &item
+	}
+
+	return func() {
+		*repo = saved
+	}
+}
@@ -2,22 +2,56 @@ package teststore
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/store"
 )
 
 type Store struct {
-	userRepository         *FakeUserRepository
-	balanceRepository      *FakeBalanceRepository
-	ticketRepository       *FakeTicketRepository
-	notificationRepository *FakeNotificationRepository
+	userRepository          *FakeUserRepository
+	balanceRepository       *FakeBalanceRepository
+	ticketRepository        *FakeTicketRepository
+	notificationRepository  *FakeNotificationRepository
+	auditRepository         *FakeAuditRepository
+	sessionRepository       *FakeSessionRepository
+	deletionRepository      *FakeAccountDeletionRepository
+	contactRepository       *FakeContactRepository
+	resourceRepository      *FakeResourceRepository
+	ticketHistoryRepository *FakeTicketHistoryRepository
+	ticketMuteRepository    *FakeTicketMuteRepository
+	slaRepository           *FakeSlaRepository
+	sectionRepository       *FakeSectionRepository
+	cannedRepository        *FakeCannedResponseRepository
+	locksMu                 sync.Mutex
+	locks                   map[string]bool
 }
 
 func New() *Store {
 	return &Store{}
 }
 
+// All repositories of the store, created if needed
+func (s *Store) repositories(ctx context.Context) []snapshotter {
+	return []snapshotter{
+		s.User(ctx).(*FakeUserRepository),
+		s.Balance(ctx).(*FakeBalanceRepository),
+		s.Tickets(ctx).(*FakeTicketRepository),
+		s.Notifications(ctx).(*FakeNotificationRepository),
+		s.Audit(ctx).(*FakeAuditRepository),
+		s.Sessions(ctx).(*FakeSessionRepository),
+		s.Deletions(ctx).(*FakeAccountDeletionRepository),
+		s.Contacts(ctx).(*FakeContactRepository),
+		s.Resources(ctx).(*FakeResourceRepository),
+		s.TicketHistory(ctx).(*FakeTicketHistoryRepository),
+		s.TicketMutes(ctx).(*FakeTicketMuteRepository),
+		s.Sla(ctx).(*FakeSlaRepository),
+		s.Sections(ctx).(*FakeSectionRepository),
+		s.CannedResponses(ctx).(*FakeCannedResponseRepository),
+	}
+}
+
 func (s *Store) User(ctx context.Context) store.UserRepository {
 	if s.userRepository != nil {
 		return s.userRepository
@@ -56,6 +90,8 @@ func (s *Store) Tickets(ctx context.Context) store.TicketRepository {
 		ctx:            ctx,
 		tickets:        make(map[int]*models.Ticket),
 		ticketMessages: make(map[int]*models.TicketMessage),
+		statusHistory:  make(map[int]*models.TicketStatusChange),
+		reminders:      make(map[uint]time.Time),
 	}
 
 	return s.ticketRepository
@@ -74,3 +110,144 @@ func (s *Store) Notifications(ctx context.Context) store.NotificationRepository
 
 	return s.notificationRepository
 }
+
+func (s *Store) Audit(ctx context.Context) store.AuditRepository {
+	if s.auditRepository != nil {
+		return s.auditRepository
+	}
+
+	s.auditRepository = &FakeAuditRepository{
+		store:   s,
+		ctx:     ctx,
+		entries: make(map[int]*models.AuditLog),
+	}
+
+	return s.auditRepository
+}
+
+func (s *Store) Sessions(ctx context.Context) store.SessionRepository {
+	if s.sessionRepository != nil {
+		return s.sessionRepository
+	}
+
+	s.sessionRepository = &FakeSessionRepository{
+		store:    s,
+		ctx:      ctx,
+		sessions: make(map[string]*models.Session),
+	}
+
+	return s.sessionRepository
+}
+
+func (s *Store) Deletions(ctx context.Context) store.AccountDeletionRepository {
+	if s.deletionRepository != nil {
+		return s.deletionRepository
+	}
+
+	s.deletionRepository = &FakeAccountDeletionRepository{
+		store:     s,
+		ctx:       ctx,
+		deletions: make(map[int]*models.AccountDeletion),
+	}
+
+	return s.deletionRepository
+}
+
+func (s *Store) Contacts(ctx context.Context) store.ContactRepository {
+	if s.contactRepository != nil {
+		return s.contactRepository
+	}
+
+	s.contactRepository = &FakeContactRepository{
+		store:    s,
+		ctx:      ctx,
+		contacts: make(map[int]*models.Contact),
+	}
+
+	return s.contactRepository
+}
+
+func (s *Store) Resources(ctx context.Context) store.ResourceRepository {
+	if s.resourceRepository != nil {
+		return s.resourceRepository
+	}
+
+	s.resourceRepository = &FakeResourceRepository{
+		store:     s,
+		ctx:       ctx,
+		resources: make(map[int]*models.Resource),
+	}
+
+	return s.resourceRepository
+}
+
+func (s *Store) TicketHistory(ctx context.Context) store.TicketHistoryRepository {
+	if s.ticketHistoryRepository != nil {
+		return s.ticketHistoryRepository
+	}
+
+	s.ticketHistoryRepository = &FakeTicketHistoryRepository{
+		store:   s,
+		ctx:     ctx,
+		entries: make(map[int]*models.TicketHistory),
+	}
+
+	return s.ticketHistoryRepository
+}
+
+func (s *Store) TicketMutes(ctx context.Context) store.TicketMuteRepository {
+	if s.ticketMuteRepository != nil {
+		return s.ticketMuteRepository
+	}
+
+	s.ticketMuteRepository = &FakeTicketMuteRepository{
+		store: s,
+		ctx:   ctx,
+		mutes: make(map[ticketMute]bool),
+	}
+
+	return s.ticketMuteRepository
+}
+
+func (s *Store) Sla(ctx context.Context) store.SlaRepository {
+	if s.slaRepository != nil {
+		return s.slaRepository
+	}
+
+	s.slaRepository = &FakeSlaRepository{
+		store:    s,
+		ctx:      ctx,
+		policies: make(map[int]*models.SlaPolicy),
+		breaches: make(map[int]*models.SlaBreach),
+	}
+
+	return s.slaRepository
+}
+
+func (s *Store) Sections(ctx context.Context) store.SectionRepository {
+	if s.sectionRepository != nil {
+		return s.sectionRepository
+	}
+
+	s.sectionRepository = &FakeSectionRepository{
+		store:    s,
+		ctx:      ctx,
+		sections: make(map[int]*models.Section),
+	}
+
+	return s.sectionRepository
+}
+
+func (s *Store) CannedResponses(ctx context.Context) store.CannedResponseRepository {
+	if s.cannedRepository != nil {
+		return s.cannedRepository
+	}
+
+	s.cannedRepository = &FakeCannedResponseRepository{
+		store:     s,
+		ctx:       ctx,
+		responses: make(map[int]*models.CannedResponse),
+	}
+
+	return s.cannedRepository
+}
@@ -0,0 +1,27 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeAccountDeletionRepository(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	assert.NoError(t, s.Deletions(ctx).Create(&models.AccountDeletion{UserId: 1}))
+	assert.EqualError(t, s.Deletions(ctx).Create(&models.AccountDeletion{UserId: 1}), store.ErrDeletionPending.Error())
+
+	due, err := s.Deletions(ctx).FindDue(time.Now().UTC().Add(models.DeletionGracePeriod + time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(due))
+
+	assert.NoError(t, s.Deletions(ctx).Cancel(1))
+	_, err = s.Deletions(ctx).Find(1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,47 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/auditAction"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeAuditRepository_Create(t *testing.T) {
+	s := teststore.New()
+	entry := models.NewTestAuditLog(t)
+	assert.NoError(t, s.Audit(context.Background()).Create(entry))
+	assert.NotEmpty(t, entry.ID)
+
+	assert.Error(t, s.Audit(context.Background()).Create(&models.AuditLog{}))
+}
+
+func TestFakeAuditRepository_Find(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	count := 5
+	for i := 0; i < count; i++ {
+		s.Audit(ctx).Create(models.NewTestAuditLog(t))
+	}
+	failed := models.NewTestAuditLog(t)
+	failed.Action = auditAction.SignInFailed
+	s.Audit(ctx).Create(failed)
+
+	entries, err := s.Audit(ctx).Find(&store.AuditFilter{Action: auditAction.SignIn, Limit: 3, Offset: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, 4, entries[0].ID)
+
+	entries, err = s.Audit(ctx).Find(&store.AuditFilter{Action: auditAction.SignIn, BeforeId: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, 2, entries[0].ID)
+
+	total, err := s.Audit(ctx).Count(&store.AuditFilter{Action: auditAction.SignIn})
+	assert.NoError(t, err)
+	assert.Equal(t, count, total)
+}
@@ -0,0 +1,36 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeBalanceRepository_AddRemove(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	var userId uint = 3
+	assert.NoError(t, s.Balance(ctx).CreateBalance(userId))
+
+	balance, err := s.Balance(ctx).Add(userId, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(100), balance.BalanceNow)
+
+	balance, err = s.Balance(ctx).Remove(userId, 40)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(60), balance.BalanceNow)
+
+	_, err = s.Balance(ctx).Remove(userId, 100)
+	assert.Equal(t, store.ErrInsufficientFunds, err)
+
+	last, err := s.Balance(ctx).LookForBalance(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(60), last.BalanceNow)
+
+	transactions, err := s.Balance(ctx).AllTransactions(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(transactions))
+}
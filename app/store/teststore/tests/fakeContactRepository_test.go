@@ -0,0 +1,37 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeContactRepository(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	contact := models.NewTestContact(t)
+	assert.NoError(t, s.Contacts(ctx).Create(contact))
+	assert.EqualError(t, s.Contacts(ctx).Create(models.NewTestContact(t)), store.ErrContactExists.Error())
+
+	attempts, err := s.Contacts(ctx).CountAttempt(contact.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	_, err = s.Contacts(ctx).CountAttempt(contact.ID + 10)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	contact.CodeAttempts = attempts
+	contact.Verify(contact.VerificationCode)
+	assert.NoError(t, s.Contacts(ctx).Update(contact))
+	contacts, err := s.Contacts(ctx).FindByUser(contact.UserId)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(contacts))
+	assert.True(t, contacts[0].Verified)
+
+	assert.NoError(t, s.Contacts(ctx).Delete(contact.ID))
+	_, err = s.Contacts(ctx).Find(contact.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,73 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/ticketPriority"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeSectionRepository(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	assert.Error(t, s.Sections(ctx).Create(&models.Section{Name: "Bugs"}))
+	section := models.NewTestSection(t)
+	section.Helpers = []int{2, 3}
+	assert.NoError(t, s.Sections(ctx).Create(section))
+	assert.NotZero(t, section.ID)
+	assert.Equal(t, store.ErrSectionExists, s.Sections(ctx).Create(models.NewTestSection(t)))
+	other := models.NewTestSection(t)
+	other.Name = "Bugs"
+	assert.NoError(t, s.Sections(ctx).Create(other))
+
+	found, err := s.Sections(ctx).FindByName(section.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, found.Helpers)
+	_, err = s.Sections(ctx).FindByName("Billing")
+	assert.Equal(t, store.ErrRecordNotFound, err)
+
+	update := models.NewTestSection(t)
+	update.ID = section.ID
+	update.Priority = ticketPriority.Urgent
+	update.Helpers = []int{3}
+	assert.NoError(t, s.Sections(ctx).Update(update))
+	update.Name = "Bugs"
+	assert.Equal(t, store.ErrSectionExists, s.Sections(ctx).Update(update))
+	update.ID = other.ID + 10
+	update.Name = "Billing"
+	assert.Equal(t, store.ErrRecordNotFound, s.Sections(ctx).Update(update))
+
+	assert.NoError(t, s.Sections(ctx).SetLastHelper(section.ID, 3))
+	found, err = s.Sections(ctx).Find(section.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ticketPriority.Urgent, found.Priority)
+	assert.Equal(t, []int{3}, found.Helpers)
+	assert.Equal(t, 3, found.LastHelper)
+
+	assert.NoError(t, s.WithTx(ctx, func(tx store.Store) error {
+		locked, err := tx.Sections(ctx).FindForUpdate(section.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, 3, locked.LastHelper)
+		return tx.Sections(ctx).SetLastHelper(section.ID, 4)
+	}))
+	found, _ = s.Sections(ctx).Find(section.ID)
+	assert.Equal(t, 4, found.LastHelper)
+	_, err = s.Sections(ctx).FindForUpdate(other.ID + 10)
+	assert.Equal(t, store.ErrRecordNotFound, err)
+
+	sections, err := s.Sections(ctx).List()
+	assert.NoError(t, err)
+	assert.Len(t, sections, 2)
+	assert.Equal(t, "Bugs", sections[0].Name)
+
+	assert.NoError(t, s.Sections(ctx).Delete(other.ID))
+	_, err = s.Sections(ctx).Find(other.ID)
+	assert.Equal(t, store.ErrRecordNotFound, err)
+}
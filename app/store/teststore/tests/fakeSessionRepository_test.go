@@ -0,0 +1,52 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeSessionRepository_Create(t *testing.T) {
+	store := teststore.New()
+	session := models.NewTestSession(t)
+	assert.NoError(t, store.Sessions(context.Background()).Create(session))
+	assert.NotEmpty(t, session.ID)
+	assert.True(t, session.IsActive())
+}
+
+func TestFakeSessionRepository_Find(t *testing.T) {
+	store := teststore.New()
+	ctx := context.Background()
+	session := models.NewTestSession(t)
+	assert.NoError(t, store.Sessions(ctx).Create(session))
+
+	session1, err := store.Sessions(ctx).Find(session.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, session1)
+
+	_, err = store.Sessions(ctx).Find("unknown")
+	assert.Error(t, err)
+}
+
+func TestFakeSessionRepository_RevokeAll(t *testing.T) {
+	store := teststore.New()
+	ctx := context.Background()
+	count := 3
+	sessions := make([]*models.Session, 0)
+	for i := 0; i < count; i++ {
+		session := models.NewTestSession(t)
+		assert.NoError(t, store.Sessions(ctx).Create(session))
+		sessions = append(sessions, session)
+	}
+
+	assert.NoError(t, store.Sessions(ctx).RevokeAll(sessions[0].UserId, sessions[0].ID))
+	userSessions, err := store.Sessions(ctx).FindByUser(sessions[0].UserId)
+	assert.NoError(t, err)
+	assert.Equal(t, count, len(userSessions))
+	for _, item := range userSessions {
+		assert.Equal(t, item.ID != sessions[0].ID, item.Revoked)
+	}
+}
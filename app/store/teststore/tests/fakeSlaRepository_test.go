@@ -0,0 +1,95 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/slaKind"
+	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeSlaRepository_Policies(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	assert.Error(t, s.Sla(ctx).CreatePolicy(&models.SlaPolicy{Section: "Billing"}))
+	assert.Error(t, s.Sla(ctx).CreatePolicy(&models.SlaPolicy{Section: "Billing", FirstResponseMinutes: 60, ResolutionMinutes: 30}))
+
+	fallback := &models.SlaPolicy{FirstResponseMinutes: 60, ResolutionMinutes: 240}
+	assert.NoError(t, s.Sla(ctx).CreatePolicy(fallback))
+	billing := &models.SlaPolicy{Section: "Billing", FirstResponseMinutes: 30, ResolutionMinutes: 120}
+	assert.NoError(t, s.Sla(ctx).CreatePolicy(billing))
+	assert.Equal(t, store.ErrSlaPolicyExists, s.Sla(ctx).CreatePolicy(&models.SlaPolicy{Section: "Billing", FirstResponseMinutes: 1, ResolutionMinutes: 1}))
+
+	assert.Equal(t, store.ErrSlaPolicyExists, s.Sla(ctx).UpdatePolicy(&models.SlaPolicy{ID: billing.ID, FirstResponseMinutes: 1, ResolutionMinutes: 1}))
+	assert.Equal(t, store.ErrRecordNotFound, s.Sla(ctx).UpdatePolicy(&models.SlaPolicy{ID: 10, FirstResponseMinutes: 1, ResolutionMinutes: 1}))
+	assert.NoError(t, s.Sla(ctx).UpdatePolicy(&models.SlaPolicy{ID: billing.ID, Section: "Billing", FirstResponseMinutes: 15, ResolutionMinutes: 60}))
+	policy, err := s.Sla(ctx).FindPolicy(billing.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 15, policy.FirstResponseMinutes)
+
+	policies, err := s.Sla(ctx).Policies()
+	assert.NoError(t, err)
+	assert.Len(t, policies, 2)
+	assert.Equal(t, "", policies[0].Section)
+
+	assert.NoError(t, s.Sla(ctx).DeletePolicy(fallback.ID))
+	_, err = s.Sla(ctx).FindPolicy(fallback.ID)
+	assert.Equal(t, store.ErrRecordNotFound, err)
+}
+
+func TestFakeSlaRepository_Timelines(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	own := models.NewTestTicketMessage(t)
+	own.Who = ticket.From
+	assert.NoError(t, s.Tickets(ctx).AddMessage(own))
+	reply := models.NewTestTicketMessage(t)
+	assert.NoError(t, s.Tickets(ctx).AddMessage(reply))
+	assert.NoError(t, s.Tickets(ctx).AddMessage(models.NewTestTicketMessage(t)))
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{
+		TicketId: ticket.ID,
+		To:       ticketStatus.Resolved,
+		Actor:    43,
+	}))
+	assert.NoError(t, s.Tickets(ctx).Create(models.NewTestTicket(t)))
+
+	timelines, err := s.Sla(ctx).Timelines(time.Now().UTC().Add(-time.Hour), time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, timelines, 2)
+	assert.Equal(t, ticket.Section, timelines[0].Section)
+	assert.Equal(t, reply.Date, *timelines[0].FirstResponseAt)
+	assert.NotNil(t, timelines[0].ResolvedAt)
+	assert.Nil(t, timelines[1].FirstResponseAt)
+	assert.Nil(t, timelines[1].ResolvedAt)
+
+	timelines, err = s.Sla(ctx).Timelines(time.Time{}, time.Now().UTC().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, timelines)
+}
+
+func TestFakeSlaRepository_Breaches(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	breach := &models.SlaBreach{TicketId: 1, PolicyId: 1, Kind: slaKind.FirstResponse, DueAt: time.Now().UTC()}
+	assert.NoError(t, s.Sla(ctx).CreateBreach(breach))
+	assert.NotZero(t, breach.ID)
+	assert.Equal(t, store.ErrBreachRegistered, s.Sla(ctx).CreateBreach(&models.SlaBreach{TicketId: 1, PolicyId: 1, Kind: slaKind.FirstResponse}))
+	assert.NoError(t, s.Sla(ctx).CreateBreach(&models.SlaBreach{TicketId: 1, PolicyId: 1, Kind: slaKind.Resolution}))
+
+	breaches, err := s.Sla(ctx).Breaches(time.Now().UTC().Add(-time.Minute), time.Now().UTC().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, breaches, 2)
+	breaches, err = s.Sla(ctx).Breaches(time.Now().UTC().Add(time.Minute), time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, breaches)
+}
@@ -0,0 +1,28 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTicketMuteRepository(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	assert.NoError(t, s.TicketMutes(ctx).Mute(1, 5))
+	assert.NoError(t, s.TicketMutes(ctx).Mute(1, 5))
+	muted, err := s.TicketMutes(ctx).IsMuted(1, 5)
+	assert.NoError(t, err)
+	assert.True(t, muted)
+	muted, _ = s.TicketMutes(ctx).IsMuted(2, 5)
+	assert.False(t, muted)
+	muted, _ = s.TicketMutes(ctx).IsMuted(1, 6)
+	assert.False(t, muted)
+
+	assert.NoError(t, s.TicketMutes(ctx).Unmute(1, 5))
+	muted, _ = s.TicketMutes(ctx).IsMuted(1, 5)
+	assert.False(t, muted)
+}
@@ -3,9 +3,11 @@ package teststore_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/inhumanLightBackend/app/models"
 	"github.com/inhumanLightBackend/app/models/ticketStatus"
+	"github.com/inhumanLightBackend/app/store"
 	"github.com/inhumanLightBackend/app/store/teststore"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,6 +25,37 @@ func TestFakeTicketRepository_Accept(t *testing.T) {
 	store := teststore.New()
 	assert.NoError(t, store.Tickets(ctx).Create(ticket))
 	assert.NoError(t, store.Tickets(ctx).Accept(ticket.ID, user))
+	assert.Equal(t, user.ID, ticket.Helper)
+	// Status is changed by handlers through status transitions
+	assert.Equal(t, ticketStatus.Opened, ticket.Status)
+	assert.Error(t, store.Tickets(ctx).Accept(5, user))
+}
+
+func TestFakeTicketRepository_AcceptAssigned(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	assert.NoError(t, s.Tickets(ctx).Accept(ticket.ID, &models.User{ID: 2}))
+
+	// Taken ticket is not overwritten
+	assert.Equal(t, store.ErrTicketAssigned, s.Tickets(ctx).Accept(ticket.ID, &models.User{ID: 7}))
+	assert.Equal(t, 2, ticket.Helper)
+	assert.NoError(t, s.Tickets(ctx).Assign(ticket.ID, &models.User{ID: 7}))
+	assert.Equal(t, 7, ticket.Helper)
+	assert.Equal(t, store.ErrRecordNotFound, s.Tickets(ctx).Assign(ticket.ID+1, &models.User{ID: 7}))
+}
+
+func TestFakeTicketRepository_Unassign(t *testing.T) {
+	ticket := models.NewTestTicket(t)
+	ctx := context.Background()
+	store := teststore.New()
+	assert.NoError(t, store.Tickets(ctx).Create(ticket))
+	assert.NoError(t, store.Tickets(ctx).Accept(ticket.ID, &models.User{ID: 2}))
+	assert.NoError(t, store.Tickets(ctx).Unassign(ticket.ID))
+	assert.Equal(t, -1, ticket.Helper)
+	assert.Equal(t, ticketStatus.Opened, ticket.Status)
 }
 
 func TestFakeTicketRepository_Find(t *testing.T) {
@@ -55,7 +88,19 @@ func TestFakeTicketRepository_ChangeStatus(t *testing.T) {
 	ctx := context.Background()
 	store := teststore.New()
 	store.Tickets(ctx).Create(ticket)
-	assert.NoError(t, store.Tickets(ctx).ChangeStatus(ticket.ID, ticketStatus.InProcess))
+	change := &models.TicketStatusChange{TicketId: ticket.ID, To: ticketStatus.InProcess, Actor: 2}
+	assert.NoError(t, store.Tickets(ctx).ChangeStatus(change))
+	assert.Equal(t, ticketStatus.Opened, change.From)
+	assert.Error(t, store.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     ticketStatus.Opened,
+		To:       ticketStatus.Closed,
+	}))
+
+	changes, err := store.Tickets(ctx).StatusHistory(ticket.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, ticketStatus.InProcess, changes[0].To)
 }
 
 func TestFakeTicketRepository_AddMessage(t *testing.T) {
@@ -77,4 +122,146 @@ func TestFakeTicketRepository_TakeMessages(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, messages)
 	assert.Equal(t, messgesCount, len(messages))
-}
\ No newline at end of file
+}
+
+func TestFakeTicketRepository_List(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for _, section := range []string{"billing", "api", "billing"} {
+		ticket := models.NewTestTicket(t)
+		ticket.Section = section
+		assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	}
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{TicketId: 2, To: ticketStatus.Closed}))
+	message := models.NewTestTicketMessage(t)
+	message.TicketId = 1
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+
+	filter := &store.TicketFilter{SortBy: store.TicketSortLastActivity, Desc: true, Limit: 2}
+	tickets, err := s.Tickets(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tickets))
+	assert.Equal(t, uint(1), tickets[0].ID)
+
+	filter.Cursor = filter.NextCursor(tickets)
+	tickets, err = s.Tickets(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tickets))
+	assert.Equal(t, uint(3), tickets[0].ID)
+
+	filter = &store.TicketFilter{Status: []string{ticketStatus.Opened}, Section: "billing"}
+	tickets, err = s.Tickets(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tickets))
+
+	counts, err := s.Tickets(ctx).CountByStatus(&store.TicketFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{ticketStatus.Opened: 2, ticketStatus.Closed: 1}, counts)
+}
+
+func TestFakeTicketRepository_Search(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	ticket.Title = "Login fails"
+	ticket.Description = "Can not login after password change"
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	other := models.NewTestTicket(t)
+	other.From = 34
+	assert.NoError(t, s.Tickets(ctx).Create(other))
+	message := models.NewTestTicketMessage(t)
+	message.Message = "Reset password from login page"
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+
+	hits, err := s.Tickets(ctx).Search(&store.SearchFilter{Query: "LOGIN password", All: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(hits))
+	assert.Equal(t, uint(0), hits[0].MessageId)
+	assert.Equal(t, "<mark>Login</mark> fails Can not <mark>login</mark> after <mark>password</mark> change", hits[0].Snippet)
+	assert.Equal(t, message.ID, hits[1].MessageId)
+
+	hits, err = s.Tickets(ctx).Search(&store.SearchFilter{Query: "login", From: 34, Helpers: []int{5}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hits))
+
+	hits, err = s.Tickets(ctx).Search(&store.SearchFilter{Query: "login", From: 34, Helpers: []int{-1}, Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(hits))
+
+	// Snippet is html, text of ticket is escaped
+	message = models.NewTestTicketMessage(t)
+	message.Message = "<script>alert('xss')</script> printer"
+	assert.NoError(t, s.Tickets(ctx).AddMessage(message))
+	hits, _ = s.Tickets(ctx).Search(&store.SearchFilter{Query: "printer", All: true})
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "&lt;script&gt;alert(&#39;xss&#39;)&lt;/script&gt; <mark>printer</mark>", hits[0].Snippet)
+}
+
+func TestFakeTicketRepository_Reply(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+
+	message := models.NewTestTicketMessage(t)
+	assert.NoError(t, s.Tickets(ctx).Reply(message, &models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     ticketStatus.Opened,
+		To:       ticketStatus.InProcess,
+	}))
+	messages, _ := s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, ticketStatus.InProcess, ticket.Status)
+
+	assert.Equal(t, store.ErrStatusChanged, s.Tickets(ctx).Reply(models.NewTestTicketMessage(t), &models.TicketStatusChange{
+		TicketId: ticket.ID,
+		From:     ticketStatus.Opened,
+		To:       ticketStatus.Closed,
+	}))
+	messages, _ = s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.Equal(t, 1, len(messages))
+
+	assert.NoError(t, s.Tickets(ctx).Reply(models.NewTestTicketMessage(t), nil))
+	messages, _ = s.Tickets(ctx).TakeMessages(ticket.ID)
+	assert.Equal(t, 2, len(messages))
+}
+
+func TestFakeTicketRepository_Stale(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	ticket := models.NewTestTicket(t)
+	assert.NoError(t, s.Tickets(ctx).Create(ticket))
+	assert.NoError(t, s.Tickets(ctx).AddMessage(models.NewTestTicketMessage(t)))
+
+	// Ticket is not waiting on customer
+	tickets, err := s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, tickets)
+
+	assert.NoError(t, s.Tickets(ctx).ChangeStatus(&models.TicketStatusChange{
+		TicketId: ticket.ID,
+		To:       ticketStatus.WaitingForCustomer,
+	}))
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(-time.Hour))
+	assert.Empty(t, tickets)
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.Len(t, tickets, 1)
+	assert.Equal(t, ticket.ID, tickets[0].TicketId)
+	assert.Equal(t, ticket.From, tickets[0].Customer)
+	assert.False(t, tickets[0].Reminded())
+
+	assert.NoError(t, s.Tickets(ctx).Remind(ticket.ID, time.Now().UTC()))
+	assert.Equal(t, store.ErrRecordNotFound, s.Tickets(ctx).Remind(ticket.ID+1, time.Now().UTC()))
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.True(t, tickets[0].Reminded())
+
+	// Last message is written by customer
+	reply := models.NewTestTicketMessage(t)
+	reply.Who = ticket.From
+	assert.NoError(t, s.Tickets(ctx).AddMessage(reply))
+	tickets, _ = s.Tickets(ctx).Stale(time.Now().UTC().Add(time.Hour))
+	assert.Empty(t, tickets)
+}
@@ -2,9 +2,11 @@ package teststore_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
 	"github.com/inhumanLightBackend/app/store/teststore"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,4 +46,63 @@ func TestFakeUserRepository_Update(t *testing.T) {
 	email := "supermegamen@gmail.com"
 	user.Email = email
 	assert.NoError(t, store.User(ctx).Update(user))
-}
\ No newline at end of file
+}
+
+func TestFakeUserRepository_FindByToken(t *testing.T) {
+	store := teststore.New()
+	ctx := context.Background()
+	user := models.NewTestUser(t)
+	assert.NoError(t, store.User(ctx).Create(user))
+	user1, err := store.User(ctx).FindByToken(user.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, user1.ID)
+}
+
+func TestFakeUserRepository_List(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	for i, email := range []string{"c@gmail.com", "a@gmail.com", "b@yandex.ru"} {
+		user := models.NewTestUser(t)
+		user.Email = email
+		user.Login = fmt.Sprintf("user%d", i)
+		assert.NoError(t, s.User(ctx).Create(user))
+	}
+
+	filter := &store.UserFilter{SortBy: store.UserSortEmail, Limit: 2}
+	users, err := s.User(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, "a@gmail.com", users[0].Email)
+
+	filter.Cursor = filter.NextCursor(users)
+	users, err = s.User(ctx).List(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "c@gmail.com", users[0].Email)
+
+	count, err := s.User(ctx).Count(&store.UserFilter{Search: "GMAIL"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = s.User(ctx).List(&store.UserFilter{Cursor: "???"})
+	assert.EqualError(t, err, store.ErrInvalidCursor.Error())
+}
+
+func TestFakeUserRepository_FindByLogin(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	user := models.NewTestUser(t)
+	assert.NoError(t, s.User(ctx).Create(user))
+
+	u, err := s.User(ctx).FindByLogin("USERNMAE")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, u.ID)
+	u, err = s.User(ctx).FindByLogin(user.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, u.ID)
+
+	other := models.NewTestUser(t)
+	other.Email = "other@gmail.com"
+	other.Login = "usernmae"
+	assert.EqualError(t, s.User(ctx).Create(other), store.ErrUsernameTaken.Error())
+}
@@ -0,0 +1,82 @@
+package teststore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_WithTx(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	user := models.NewTestUser(t)
+	assert.NoError(t, s.User(ctx).Create(user))
+
+	errAbort := errors.New("abort")
+	err := s.WithTx(ctx, func(tx store.Store) error {
+		assert.NoError(t, tx.Balance(ctx).CreateBalance(uint(user.ID)))
+		u, _ := tx.User(ctx).FindById(user.ID)
+		u.Login = "Changed"
+		return errAbort
+	})
+	assert.Equal(t, errAbort, err)
+	_, err = s.Balance(ctx).LookForBalance(uint(user.ID))
+	assert.Error(t, err)
+	u, _ := s.User(ctx).FindById(user.ID)
+	assert.Equal(t, "Usernmae", u.Login)
+
+	assert.NoError(t, s.WithTx(ctx, func(tx store.Store) error {
+		return tx.Balance(ctx).CreateBalance(uint(user.ID))
+	}))
+	_, err = s.Balance(ctx).LookForBalance(uint(user.ID))
+	assert.NoError(t, err)
+}
+
+func TestStore_TryLock(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+
+	ran, err := s.TryLock(ctx, "job", func() error {
+		nested, err := s.TryLock(ctx, "job", func() error { return nil })
+		assert.NoError(t, err)
+		assert.False(t, nested)
+
+		other, err := s.TryLock(ctx, "other", func() error { return nil })
+		assert.NoError(t, err)
+		assert.True(t, other)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	errAbort := errors.New("abort")
+	ran, err = s.TryLock(ctx, "job", func() error { return errAbort })
+	assert.True(t, ran)
+	assert.Equal(t, errAbort, err)
+}
+
+func TestStore_WithTxRestoresNested(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	section := models.NewTestSection(t)
+	section.Helpers = []int{1, 2}
+	assert.NoError(t, s.Sections(ctx).Create(section))
+
+	errAbort := errors.New("abort")
+	assert.Equal(t, errAbort, s.WithTx(ctx, func(tx store.Store) error {
+		found, _ := tx.Sections(ctx).Find(section.ID)
+		found.Helpers[0] = 3
+		assert.NoError(t, tx.Sections(ctx).SetLastHelper(section.ID, 2))
+		return errAbort
+	}))
+
+	found, err := s.Sections(ctx).Find(section.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, found.Helpers)
+	assert.Equal(t, 0, found.LastHelper)
+}
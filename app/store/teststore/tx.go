@@ -0,0 +1,68 @@
+package teststore
+
+import (
+	"context"
+
+	"github.com/inhumanLightBackend/app/store"
+)
+
+// Run fn on the same store. Changes of all repositories
+// are rolled back if fn returns error or panics
+func (s *Store) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	restore := s.snapshot(ctx)
+	defer func() {
+		if p := recover(); p != nil {
+			restore()
+			panic(p)
+		}
+	}()
+
+	if err := fn(s); err != nil {
+		restore()
+		return err
+	}
+
+	return nil
+}
+
+// Run fn unless lock with the same name is held by other caller
+func (s *Store) TryLock(ctx context.Context, name string, fn func() error) (bool, error) {
+	s.locksMu.Lock()
+	if s.locks[name] {
+		s.locksMu.Unlock()
+		return false, nil
+	}
+	if s.locks == nil {
+		s.locks = make(map[string]bool)
+	}
+	s.locks[name] = true
+	s.locksMu.Unlock()
+
+	defer func() {
+		s.locksMu.Lock()
+		delete(s.locks, name)
+		s.locksMu.Unlock()
+	}()
+
+	return true, fn()
+}
+
+// Repository which can copy its state. Returned func restores the copy
+type snapshotter interface {
+	snapshot() func()
+}
+
+// Copy state of all repositories. Returned func restores it
+func (s *Store) snapshot(ctx context.Context) func() {
+	repositories := s.repositories(ctx)
+	restores := make([]func(), len(repositories))
+	for i, repo := range repositories {
+		restores[i] = repo.snapshot()
+	}
+
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package jwtHelper
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Supported signing algorithms
+const (
+	HS512 = "HS512"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+const (
+	defaultIssuer   = "inhumanLight"
+	defaultAudience = "inhumanLight-api"
+)
+
+var (
+	secret = []byte("21a481f3d02a91f8b6a9e9c1c2e1ce11d9e5d18fa23673bcdfdfedfa39ea393665aff383706b0c4666e092e048766138b605307042a208e6cc21fcb2e9ed8f24")
+
+	current = &signer{
+		method:    jwt.SigningMethodHS512,
+		signKey:   secret,
+		verifyKey: secret,
+		issuer:    defaultIssuer,
+		audience:  defaultAudience,
+	}
+)
+
+// Token signing config
+type Config struct {
+	Algorithm      string
+	PrivateKeyPath string
+	KeyId          string
+	Issuer         string
+	Audience       string
+}
+
+// Key and claims used for signing and validation
+type signer struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	keyId     string
+	issuer    string
+	audience  string
+}
+
+// Setup signing algorithm and keys. Empty config restores HS512 with
+// the built in secret. Asymmetric algorithms need PKCS8 (or PKCS1 for RSA)
+// private key in PEM format
+func Setup(config *Config) error {
+	s := &signer{
+		issuer:   config.Issuer,
+		audience: config.Audience,
+		keyId:    config.KeyId,
+	}
+	if s.issuer == "" {
+		s.issuer = defaultIssuer
+	}
+	if s.audience == "" {
+		s.audience = defaultAudience
+	}
+
+	switch config.Algorithm {
+	case "", HS512:
+		s.method = jwt.SigningMethodHS512
+		s.signKey = secret
+		s.verifyKey = secret
+	case RS256, EdDSA:
+		key, err := loadPrivateKey(config.PrivateKeyPath)
+		if err != nil {
+			return err
+		}
+
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			if config.Algorithm != RS256 {
+				return errors.New("RSA key can not be used with " + config.Algorithm)
+			}
+			s.method = jwt.SigningMethodRS256
+			s.signKey = k
+			s.verifyKey = &k.PublicKey
+		case ed25519.PrivateKey:
+			if config.Algorithm != EdDSA {
+				return errors.New("Ed25519 key can not be used with " + config.Algorithm)
+			}
+			s.method = SigningMethodEd25519
+			s.signKey = k
+			s.verifyKey = k.Public()
+		default:
+			return errors.New("Unsupported private key type")
+		}
+
+		if s.keyId == "" {
+			s.keyId = thumbprint(s.verifyKey)
+		}
+	default:
+		return fmt.Errorf("Unsupported signing algorithm: %s", config.Algorithm)
+	}
+
+	current = s
+
+	return nil
+}
+
+// Read private key from PEM file
+func loadPrivateKey(path string) (interface{}, error) {
+	if path == "" {
+		return nil, errors.New("Empty param: 'private key path'")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("Private key is not PEM encoded")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
@@ -0,0 +1,91 @@
+package jwtHelper
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+// Public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// Set of public keys which can be used to verify tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Return public keys of current signer. Symmetric keys are never published
+func PublicKeys() *JWKS {
+	jwks := &JWKS{
+		Keys: make([]JWK, 0),
+	}
+
+	key := toJWK(current.verifyKey)
+	if key != nil {
+		key.Use = "sig"
+		key.Alg = current.method.Alg()
+		key.Kid = current.keyId
+		jwks.Keys = append(jwks.Keys, *key)
+	}
+
+	return jwks
+}
+
+func toJWK(key interface{}) *JWK {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return &JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k),
+		}
+	}
+
+	return nil
+}
+
+// Key id computed as JWK thumbprint (RFC 7638)
+func thumbprint(key interface{}) string {
+	jwk := toJWK(key)
+	if jwk == nil {
+		return ""
+	}
+
+	var members interface{}
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
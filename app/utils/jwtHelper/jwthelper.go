@@ -1,6 +1,9 @@
 package jwtHelper
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -8,37 +11,105 @@ import (
 )
 
 var (
-	secret = []byte("21a481f3d02a91f8b6a9e9c1c2e1ce11d9e5d18fa23673bcdfdfedfa39ea393665aff383706b0c4666e092e048766138b605307042a208e6cc21fcb2e9ed8f24")
+	// ErrTokenExpired returned by Validate if token is correct but expired
+	ErrTokenExpired = errors.New("Token expired")
+	// ErrTokenNotValid returned by Validate if token is malformed or claims are wrong
+	ErrTokenNotValid = errors.New("Token is not valid")
 )
 
 type claims struct {
-	UserId int    `json:"user_id"`
-	Access string `json:"access"`
-	Type   string `json:"token_type"`
+	UserId         int    `json:"user_id"`
+	ImpersonatorId int    `json:"impersonator_id,omitempty"`
+	SessionId      string `json:"sid,omitempty"`
+	Access         string `json:"access"`
+	Type           string `json:"token_type"`
 	jwt.StandardClaims
 }
 
 func Create(u *models.User, days uint8, tokenType string) (string, error) {
-	jwt := jwt.NewWithClaims(jwt.SigningMethodHS512, &claims{
-		UserId: u.ID,
-		Access: u.Role,
-		Type:   tokenType,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24 * time.Duration(days)).Unix(),
-		},
-	})
+	return CreateForSession(u, "", days, tokenType)
+}
+
+// Create token bound to the session. Token is valid only while session is active
+func CreateForSession(u *models.User, sessionId string, days uint8, tokenType string) (string, error) {
+	return sign(&claims{
+		UserId:    u.ID,
+		SessionId: sessionId,
+		Access:    u.Role,
+		Type:      tokenType,
+	}, time.Hour*24*time.Duration(days))
+}
 
-	return jwt.SignedString(secret)
+// Create short-lived access token which acts as user u on behalf of impersonator
+func CreateImpersonated(u *models.User, impersonatorId int, ttl time.Duration) (string, error) {
+	return sign(&claims{
+		UserId:         u.ID,
+		ImpersonatorId: impersonatorId,
+		Access:         u.Role,
+		Type:           "access",
+	}, ttl)
 }
 
+// Validate token signature, expiration, issuer and audience
 func Validate(token string) (*claims, error) {
 	claims := &claims{}
 	jwtToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		return secret, nil
+		if token.Method.Alg() != current.method.Alg() {
+			return nil, ErrTokenNotValid
+		}
+
+		return current.verifyKey, nil
 	})
-	if err != nil && !jwtToken.Valid {
+	if err != nil {
+		if ve, ok := err.(*jwt.ValidationError); ok && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrTokenExpired
+		}
+
 		return nil, err
 	}
+	if !jwtToken.Valid {
+		return nil, ErrTokenNotValid
+	}
+
+	if !claims.VerifyIssuer(current.issuer, true) ||
+		!claims.VerifyAudience(current.audience, true) ||
+		claims.Id == "" || claims.IssuedAt == 0 {
+		return nil, ErrTokenNotValid
+	}
 
 	return claims, nil
 }
+
+// Fill standard claims and sign token with current key
+func sign(c *claims, ttl time.Duration) (string, error) {
+	jti, err := newTokenId()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	c.StandardClaims = jwt.StandardClaims{
+		Id:        jti,
+		Issuer:    current.issuer,
+		Audience:  current.audience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+
+	token := jwt.NewWithClaims(current.method, c)
+	if current.keyId != "" {
+		token.Header["kid"] = current.keyId
+	}
+
+	return token.SignedString(current.signKey)
+}
+
+// Generate random token id
+func newTokenId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
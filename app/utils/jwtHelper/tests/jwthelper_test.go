@@ -0,0 +1,135 @@
+package jwtHelper_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/roles"
+	"github.com/inhumanLightBackend/app/utils/jwtHelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKey(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := ioutil.TempFile("", "jwt-key-*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		t.Fatal(err)
+	}
+
+	return file.Name()
+}
+
+func TestJwtHelper_Algorithms(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edPath := writeKey(t, edKey)
+	rsaPath := writeKey(t, rsaKey)
+	defer os.Remove(edPath)
+	defer os.Remove(rsaPath)
+	defer jwtHelper.Setup(&jwtHelper.Config{})
+
+	testCases := []struct {
+		name        string
+		config      *jwtHelper.Config
+		expectedKty string
+	}{
+		{
+			name:   "HS512",
+			config: &jwtHelper.Config{},
+		},
+		{
+			name: "EdDSA",
+			config: &jwtHelper.Config{
+				Algorithm:      jwtHelper.EdDSA,
+				PrivateKeyPath: edPath,
+			},
+			expectedKty: "OKP",
+		},
+		{
+			name: "RS256",
+			config: &jwtHelper.Config{
+				Algorithm:      jwtHelper.RS256,
+				PrivateKeyPath: rsaPath,
+			},
+			expectedKty: "RSA",
+		},
+	}
+
+	user := &models.User{
+		ID:   3,
+		Role: roles.USER,
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, jwtHelper.Setup(tc.config))
+
+			token, err := jwtHelper.Create(user, 1, "access")
+			assert.NoError(t, err)
+			claims, err := jwtHelper.Validate(token)
+			assert.NoError(t, err)
+			assert.Equal(t, user.ID, claims.UserId)
+			assert.NotEmpty(t, claims.Id)
+			assert.NotEmpty(t, claims.IssuedAt)
+
+			keys := jwtHelper.PublicKeys().Keys
+			if tc.expectedKty == "" {
+				assert.Equal(t, 0, len(keys))
+			} else {
+				assert.Equal(t, 1, len(keys))
+				assert.Equal(t, tc.expectedKty, keys[0].Kty)
+				assert.NotEmpty(t, keys[0].Kid)
+			}
+		})
+	}
+}
+
+func TestJwtHelper_Validate(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edPath := writeKey(t, edKey)
+	defer os.Remove(edPath)
+	defer jwtHelper.Setup(&jwtHelper.Config{})
+
+	user := &models.User{
+		ID:   3,
+		Role: roles.USER,
+	}
+
+	hsToken, _ := jwtHelper.Create(user, 1, "access")
+	expired, _ := jwtHelper.CreateImpersonated(user, 1, -time.Minute)
+	_, err := jwtHelper.Validate(expired)
+	assert.Equal(t, jwtHelper.ErrTokenExpired, err)
+
+	assert.NoError(t, jwtHelper.Setup(&jwtHelper.Config{Audience: "other-service"}))
+	_, err = jwtHelper.Validate(hsToken)
+	assert.Error(t, err, "wrong audience")
+
+	assert.NoError(t, jwtHelper.Setup(&jwtHelper.Config{
+		Algorithm:      jwtHelper.EdDSA,
+		PrivateKeyPath: edPath,
+	}))
+	_, err = jwtHelper.Validate(hsToken)
+	assert.Error(t, err, "algorithm mismatch")
+
+	assert.Error(t, jwtHelper.Setup(&jwtHelper.Config{Algorithm: jwtHelper.RS256, PrivateKeyPath: edPath}))
+	assert.Error(t, jwtHelper.Setup(&jwtHelper.Config{Algorithm: jwtHelper.EdDSA}))
+	assert.Error(t, jwtHelper.Setup(&jwtHelper.Config{Algorithm: "none"}))
+}
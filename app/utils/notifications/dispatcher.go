@@ -0,0 +1,133 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/notificationStatus"
+	"github.com/inhumanLightBackend/app/store"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrChannelNotFound
+var ErrChannelNotFound = errors.New("Channel for contact kind not found")
+
+// Time given to background delivery of one message to all contacts
+const DeliveryTimeout = time.Minute
+
+// Channel delivers messages to contacts of one kind
+type Channel interface {
+	Send(ctx context.Context, contact *models.Contact, message string) error
+}
+
+// Dispatcher delivers messages through external channels.
+// Channels are picked by user contacts
+type Dispatcher struct {
+	store    store.Store
+	logger   *logrus.Logger
+	channels map[string]Channel
+	pending  sync.WaitGroup
+}
+
+// Create dispatcher without channels
+func NewDispatcher(store store.Store, logger *logrus.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		logger:   logger,
+		channels: make(map[string]Channel),
+	}
+}
+
+// Register channel for contact kind
+func (d *Dispatcher) Register(kind string, channel Channel) {
+	d.channels[kind] = channel
+}
+
+// Send message to every verified and enabled contact of user. Contacts
+// are found with ctx, delivery runs in background, so slow channel does not
+// hold the caller. Delivery errors are logged and do not stop other channels
+func (d *Dispatcher) Notify(ctx context.Context, userId int, message string) error {
+	contacts, err := d.store.Contacts(ctx).FindByUser(userId)
+	if err != nil {
+		return err
+	}
+
+	enabled := make([]*models.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		if contact.Verified && contact.Enabled {
+			enabled = append(enabled, contact)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil
+	}
+
+	d.pending.Add(1)
+	go func() {
+		defer d.pending.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), DeliveryTimeout)
+		defer cancel()
+
+		for _, contact := range enabled {
+			if err := d.SendTo(ctx, contact, message); err != nil && err != ErrChannelNotFound {
+				d.logger.WithFields(logrus.Fields{
+					"Contact": contact.ID,
+					"Kind":    contact.Kind,
+					"Error":   err.Error(),
+				}).Warn("Notification not delivered")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Wait until messages sent in background are delivered
+func (d *Dispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// Send message to contact regardless of its status. Used for verification codes
+func (d *Dispatcher) SendTo(ctx context.Context, contact *models.Contact, message string) error {
+	channel, ok := d.channels[contact.Kind]
+	if !ok {
+		return ErrChannelNotFound
+	}
+
+	return channel.Send(ctx, contact, message)
+}
+
+// Save notification about ticket for every recipient who did not mute
+// the ticket and send it through enabled contacts of recipient.
+// Errors are logged per recipient and do not stop other recipients
+func (d *Dispatcher) NotifyTicket(ctx context.Context, ticketId uint, recipients []int, message string) {
+	for _, userId := range recipients {
+		if err := d.notifyTicket(ctx, ticketId, userId, message); err != nil {
+			d.logger.WithFields(logrus.Fields{
+				"Ticket": ticketId,
+				"User":   userId,
+				"Error":  err.Error(),
+			}).Warn("Ticket notification not saved")
+		}
+	}
+}
+
+func (d *Dispatcher) notifyTicket(ctx context.Context, ticketId uint, userId int, message string) error {
+	muted, err := d.store.TicketMutes(ctx).IsMuted(userId, ticketId)
+	if err != nil || muted {
+		return err
+	}
+
+	if err := d.store.Notifications(ctx).Create(&models.Notification{
+		Message: message,
+		Status:  notificationStatus.Info,
+		For:     userId,
+	}); err != nil {
+		return err
+	}
+
+	return d.Notify(ctx, userId, message)
+}
@@ -0,0 +1,56 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/inhumanLightBackend/app/models"
+)
+
+// Smtp server config
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Channel sends messages by smtp to address from contact value
+type Channel struct {
+	config *Config
+}
+
+// Create email channel
+func NewChannel(config *Config) *Channel {
+	return &Channel{
+		config: config,
+	}
+}
+
+// Send message to contact address
+func (c *Channel) Send(ctx context.Context, contact *models.Contact, message string) error {
+	addr := net.JoinHostPort(c.config.Host, fmt.Sprint(c.config.Port))
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+
+	return smtp.SendMail(addr, auth, c.config.From, []string{contact.Value}, buildMessage(c.config.From, contact.Value, message))
+}
+
+func buildMessage(from, to, message string) []byte {
+	body := strings.Builder{}
+	body.WriteString("From: " + from + "\r\n")
+	body.WriteString("To: " + to + "\r\n")
+	body.WriteString("Subject: InhumanLight notification\r\n")
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	body.WriteString(message)
+	body.WriteString("\r\n")
+
+	return []byte(body.String())
+}
@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+)
+
+// Channel sends messages to telegram chat from contact value
+type Channel struct {
+	ApiToken string
+	client   *http.Client
+}
+
+// Create telegram channel for bot token
+func NewChannel(token string) *Channel {
+	return &Channel{
+		ApiToken: token,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send message to contact chat
+func (c *Channel) Send(ctx context.Context, contact *models.Contact, message string) error {
+	query := url.Values{}
+	query.Set("chat_id", contact.Value)
+	query.Set("text", message)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?%s", c.ApiToken, query.Encode()),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Telegram responded with %s", resp.Status)
+	}
+
+	return nil
+}
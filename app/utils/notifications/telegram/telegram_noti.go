@@ -3,6 +3,7 @@ package telegram
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 type (
@@ -25,7 +26,7 @@ func (ta *TelegramNotificator) Notify() chan string {
 	go func(m chan string) {
 		for {
 			message := <-m
-			resp, err := http.Get(fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%d&parse_mode=Markdown&text=%s", ta.ApiToken, ta.UserId, message))
+			resp, err := http.Get(fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%d&parse_mode=Markdown&text=%s", ta.ApiToken, ta.UserId, url.QueryEscape(message)))
 			if err != nil {
 				m <- err.Error()
 			} else {
@@ -0,0 +1,144 @@
+package notifications_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inhumanLightBackend/app/models"
+	"github.com/inhumanLightBackend/app/models/contactKind"
+	"github.com/inhumanLightBackend/app/store/teststore"
+	"github.com/inhumanLightBackend/app/utils/notifications"
+	"github.com/inhumanLightBackend/app/utils/notifications/webhook"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChannel struct {
+	sent []string
+}
+
+func (c *fakeChannel) Send(ctx context.Context, contact *models.Contact, message string) error {
+	c.sent = append(c.sent, contact.Value+": "+message)
+	return nil
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	channel := &fakeChannel{}
+	d := notifications.NewDispatcher(s, logrus.New())
+	d.Register(contactKind.Telegram, channel)
+
+	verified := models.NewTestContact(t)
+	assert.NoError(t, s.Contacts(ctx).Create(verified))
+	verified.Verify(verified.VerificationCode)
+	unverified := models.NewTestContact(t)
+	unverified.Value = "100500"
+	assert.NoError(t, s.Contacts(ctx).Create(unverified))
+	noChannel := models.NewTestContact(t)
+	noChannel.Kind, noChannel.Value = contactKind.Phone, "+79991234567"
+	assert.NoError(t, s.Contacts(ctx).Create(noChannel))
+	noChannel.Verify(noChannel.VerificationCode)
+
+	assert.NoError(t, d.Notify(ctx, 3, "Hello"))
+	d.Wait()
+	assert.Equal(t, []string{"708015155: Hello"}, channel.sent)
+
+	assert.Equal(t, notifications.ErrChannelNotFound, d.SendTo(ctx, noChannel, "Code"))
+}
+
+func TestDispatcher_NotifyTicket(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	channel := &fakeChannel{}
+	d := notifications.NewDispatcher(s, logrus.New())
+	d.Register(contactKind.Telegram, channel)
+
+	contact := models.NewTestContact(t)
+	assert.NoError(t, s.Contacts(ctx).Create(contact))
+	contact.Verify(contact.VerificationCode)
+	assert.NoError(t, s.TicketMutes(ctx).Mute(4, 7))
+
+	d.NotifyTicket(ctx, 7, []int{3, 4}, "Reply")
+	d.Wait()
+	assert.Equal(t, []string{"708015155: Reply"}, channel.sent)
+	notes, _ := s.Notifications(ctx).FindAll(3)
+	assert.Equal(t, 1, len(notes))
+	notes, _ = s.Notifications(ctx).FindAll(4)
+	assert.Equal(t, 0, len(notes))
+
+	assert.NoError(t, s.TicketMutes(ctx).Mute(3, 7))
+	d.NotifyTicket(ctx, 7, []int{3, 4}, "Reply")
+	d.Wait()
+	assert.Equal(t, 1, len(channel.sent))
+	d.NotifyTicket(ctx, 8, []int{3}, "Other ticket")
+	d.Wait()
+	assert.Equal(t, 2, len(channel.sent))
+}
+
+type blockedChannel struct {
+	release chan struct{}
+}
+
+func (c *blockedChannel) Send(ctx context.Context, contact *models.Contact, message string) error {
+	<-c.release
+	return nil
+}
+
+func TestDispatcher_NotifyTicketInBackground(t *testing.T) {
+	s := teststore.New()
+	ctx := context.Background()
+	channel := &blockedChannel{release: make(chan struct{})}
+	d := notifications.NewDispatcher(s, logrus.New())
+	d.Register(contactKind.Telegram, channel)
+
+	contact := models.NewTestContact(t)
+	assert.NoError(t, s.Contacts(ctx).Create(contact))
+	contact.Verify(contact.VerificationCode)
+
+	d.NotifyTicket(ctx, 7, []int{3, 4}, "Reply")
+	notes, _ := s.Notifications(ctx).FindAll(3)
+	assert.Equal(t, 1, len(notes))
+	notes, _ = s.Notifications(ctx).FindAll(4)
+	assert.Equal(t, 1, len(notes))
+
+	close(channel.release)
+	d.Wait()
+}
+
+func TestWebhookChannel_Send(t *testing.T) {
+	payload := &webhook.Payload{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	contact := &models.Contact{UserId: 3, Kind: contactKind.Webhook, Value: server.URL}
+	assert.NoError(t, webhook.NewTestChannel().Send(context.Background(), contact, "Hello"))
+	assert.Equal(t, 3, payload.UserId)
+	assert.Equal(t, "Hello", payload.Message)
+
+	contact.Value = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	assert.Error(t, webhook.NewTestChannel().Send(context.Background(), contact, "Hello"))
+}
+
+func TestWebhookChannel_SendInternal(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	for _, value := range []string{server.URL, "http://localhost:1", "http://10.0.0.1:1", "http://169.254.169.254"} {
+		contact := &models.Contact{UserId: 3, Kind: contactKind.Webhook, Value: value}
+		err := webhook.NewChannel().Send(context.Background(), contact, "Hello")
+		assert.True(t, errors.Is(err, webhook.ErrForbiddenAddress), value)
+	}
+	assert.False(t, called)
+}
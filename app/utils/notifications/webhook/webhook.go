@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/inhumanLightBackend/app/models"
+)
+
+// ErrForbiddenAddress
+var ErrForbiddenAddress = errors.New("Webhook address is not public")
+
+// Shared address space of carrier-grade NAT, not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)}
+
+// Channel posts messages as json to webhook url from contact value
+type Channel struct {
+	client *http.Client
+}
+
+// Payload sent to webhook
+type Payload struct {
+	UserId  int       `json:"user_id"`
+	Message string    `json:"message"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Create webhook channel. Url is user input, so connections to loopback,
+// private and link-local addresses are refused. Address is checked after
+// name resolution, on every connection including redirects
+func NewChannel() *Channel {
+	return newChannel(func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+			return ErrForbiddenAddress
+		}
+
+		return nil
+	})
+}
+
+// Create webhook channel posting to any address. Only for tests
+func NewTestChannel() *Channel {
+	return newChannel(nil)
+}
+
+func newChannel(control func(network, address string, c syscall.RawConn) error) *Channel {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Channel{
+		client: &http.Client{Timeout: 5 * time.Second, Transport: transport},
+	}
+}
+
+// Post message to contact url. Any status except 2xx is an error
+func (c *Channel) Send(ctx context.Context, contact *models.Contact, message string) error {
+	body, err := json.Marshal(&Payload{
+		UserId:  contact.UserId,
+		Message: message,
+		SentAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, contact.Value, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+func isPublic(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
@@ -0,0 +1,16 @@
+CREATE TABLE audit_log (
+    id bigserial not null PRIMARY KEY,
+    action VARCHAR not null,
+    actor INTEGER not null,
+    target VARCHAR not null,
+    ip VARCHAR not null,
+    details JSONB not null,
+    created_at TIMESTAMP not null
+);
+
+CREATE INDEX audit_log_action_idx ON audit_log (action);
+CREATE INDEX audit_log_actor_idx ON audit_log (actor);
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+CREATE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING;
+CREATE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING;
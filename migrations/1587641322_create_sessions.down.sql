@@ -0,0 +1,2 @@
+DROP INDEX users_token_idx;
+DROP TABLE sessions;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE sessions (
+    id VARCHAR not null PRIMARY KEY,
+    user_id INTEGER not null,
+    created_at TIMESTAMP not null,
+    expires_at TIMESTAMP not null,
+    revoked BOOLEAN not null,
+    ip VARCHAR,
+    user_agent VARCHAR
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+CREATE INDEX users_token_idx ON users (token);
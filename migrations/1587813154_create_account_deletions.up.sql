@@ -0,0 +1,8 @@
+CREATE TABLE account_deletions (
+    user_id INTEGER not null PRIMARY KEY,
+    requested_at TIMESTAMP not null,
+    purge_at TIMESTAMP not null,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX account_deletions_purge_at_idx ON account_deletions (purge_at) WHERE completed_at IS NULL;
@@ -0,0 +1 @@
+DROP INDEX users_username_lower_idx;
@@ -0,0 +1,29 @@
+DO $$
+DECLARE
+    u RECORD;
+    base TEXT;
+    suffix TEXT;
+    candidate TEXT;
+    attempt INTEGER;
+BEGIN
+    FOR u IN
+        SELECT id, username, username !~ '^[A-Za-z0-9][A-Za-z0-9_.-]{2,31}$' AS invalid
+        FROM users x
+        WHERE username !~ '^[A-Za-z0-9][A-Za-z0-9_.-]{2,31}$'
+            OR EXISTS (SELECT 1 FROM users d WHERE lower(d.username) = lower(x.username) AND d.id < x.id)
+        ORDER BY id
+    LOOP
+        base := CASE WHEN u.invalid THEN 'user' ELSE u.username END;
+        attempt := 0;
+        LOOP
+            suffix := '_' || u.id || CASE WHEN attempt > 0 THEN '_' || attempt ELSE '' END;
+            candidate := left(base, 32 - length(suffix)) || suffix;
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM users WHERE lower(username) = lower(candidate) AND id <> u.id);
+            attempt := attempt + 1;
+        END LOOP;
+
+        UPDATE users SET username = candidate WHERE id = u.id;
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX users_username_lower_idx ON users (lower(username));
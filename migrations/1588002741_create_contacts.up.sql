@@ -0,0 +1,31 @@
+CREATE TABLE contacts (
+    id bigserial not null PRIMARY KEY,
+    user_id INTEGER not null,
+    kind VARCHAR not null,
+    value VARCHAR not null,
+    verified BOOLEAN not null,
+    enabled BOOLEAN not null,
+    verification_code VARCHAR,
+    code_expires_at TIMESTAMP,
+    created_at TIMESTAMP not null,
+    UNIQUE (user_id, kind, value)
+);
+
+CREATE INDEX contacts_user_id_idx ON contacts (user_id);
+
+-- Legacy free-form users.contacts may hold several values.
+-- Recognized ones are moved to contacts and must be verified again
+INSERT INTO contacts (user_id, kind, value, verified, enabled, created_at)
+SELECT DISTINCT user_id, kind, value, false, false, now() at time zone 'utc'
+FROM (
+    SELECT id AS user_id, value,
+        CASE
+            WHEN value ~ '^[^@\s]+@[^@\s]+\.[^@\s]+$' THEN 'email'
+            WHEN value ~ '^\+[1-9][0-9]{6,14}$' THEN 'phone'
+            WHEN value ~ '^-?[0-9]{1,20}$' THEN 'telegram'
+            WHEN value ~* '^https://' THEN 'webhook'
+        END AS kind
+    FROM users, regexp_split_to_table(coalesce(contacts, ''), '[,;\s]+') AS value
+    WHERE value <> ''
+) legacy
+WHERE kind IS NOT NULL;
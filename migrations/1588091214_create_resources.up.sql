@@ -0,0 +1,14 @@
+CREATE TABLE resources (
+    id bigserial not null PRIMARY KEY,
+    owner INTEGER not null,
+    path VARCHAR not null UNIQUE,
+    filename VARCHAR not null,
+    mime_type VARCHAR not null,
+    size BIGINT not null,
+    ticket_id INTEGER,
+    message_id INTEGER,
+    created_at TIMESTAMP not null
+);
+
+CREATE INDEX resources_owner_idx ON resources (owner);
+CREATE INDEX resources_ticket_id_idx ON resources (ticket_id) WHERE ticket_id IS NOT NULL;
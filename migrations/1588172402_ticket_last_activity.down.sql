@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tickets_last_activity_at_idx;
+DROP INDEX IF EXISTS tickets_created_at_idx;
+DROP INDEX IF EXISTS tickets_status_idx;
+ALTER TABLE tickets DROP COLUMN IF EXISTS last_activity_at;
@@ -0,0 +1,12 @@
+ALTER TABLE tickets ADD COLUMN last_activity_at TIMESTAMP;
+
+UPDATE tickets t SET last_activity_at = coalesce(
+    greatest(t.created_at, (SELECT max(m.reply_at) FROM ticket_messages m WHERE m.ticket_id = t.id)),
+    now() at time zone 'utc'
+);
+
+ALTER TABLE tickets ALTER COLUMN last_activity_at SET NOT NULL;
+
+CREATE INDEX tickets_status_idx ON tickets (status);
+CREATE INDEX tickets_created_at_idx ON tickets (created_at, id);
+CREATE INDEX tickets_last_activity_at_idx ON tickets (last_activity_at, id);
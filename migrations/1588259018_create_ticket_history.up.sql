@@ -0,0 +1,11 @@
+CREATE TABLE ticket_history (
+    id bigserial not null PRIMARY KEY,
+    ticket_id INTEGER not null,
+    actor INTEGER not null,
+    action VARCHAR not null,
+    from_helper INTEGER not null,
+    to_helper INTEGER not null,
+    created_at TIMESTAMP not null
+);
+
+CREATE INDEX ticket_history_ticket_id_idx ON ticket_history (ticket_id);
@@ -0,0 +1,11 @@
+CREATE TABLE ticket_status_history (
+    id bigserial not null PRIMARY KEY,
+    ticket_id INTEGER not null,
+    from_status VARCHAR not null,
+    to_status VARCHAR not null,
+    actor INTEGER not null,
+    comment VARCHAR not null DEFAULT '',
+    created_at TIMESTAMP not null
+);
+
+CREATE INDEX ticket_status_history_ticket_id_idx ON ticket_status_history (ticket_id);
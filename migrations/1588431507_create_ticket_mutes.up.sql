@@ -0,0 +1,6 @@
+CREATE TABLE ticket_mutes (
+    user_id INTEGER not null,
+    ticket_id INTEGER not null,
+    created_at TIMESTAMP not null,
+    PRIMARY KEY (user_id, ticket_id)
+);
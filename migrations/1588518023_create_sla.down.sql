@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ticket_messages_ticket_id_idx;
+DROP TABLE IF EXISTS sla_breaches;
+DROP TABLE IF EXISTS sla_policies;
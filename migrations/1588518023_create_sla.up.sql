@@ -0,0 +1,20 @@
+CREATE TABLE sla_policies (
+    id bigserial not null PRIMARY KEY,
+    section VARCHAR not null UNIQUE,
+    first_response_minutes INTEGER not null,
+    resolution_minutes INTEGER not null,
+    created_at TIMESTAMP not null
+);
+
+CREATE TABLE sla_breaches (
+    id bigserial not null PRIMARY KEY,
+    ticket_id INTEGER not null,
+    policy_id INTEGER not null,
+    kind VARCHAR not null,
+    due_at TIMESTAMP not null,
+    breached_at TIMESTAMP not null,
+    UNIQUE (ticket_id, kind)
+);
+
+CREATE INDEX sla_breaches_breached_at_idx ON sla_breaches (breached_at);
+CREATE INDEX ticket_messages_ticket_id_idx ON ticket_messages (ticket_id);
@@ -0,0 +1,2 @@
+ALTER TABLE tickets DROP COLUMN IF EXISTS priority;
+DROP TABLE IF EXISTS sections;
@@ -0,0 +1,16 @@
+CREATE TABLE sections (
+    id bigserial not null PRIMARY KEY,
+    name VARCHAR not null UNIQUE,
+    description VARCHAR not null DEFAULT '',
+    priority VARCHAR not null,
+    routing VARCHAR not null,
+    helpers INTEGER[] not null DEFAULT '{}',
+    last_helper INTEGER not null DEFAULT 0,
+    created_at TIMESTAMP not null
+);
+
+ALTER TABLE tickets ADD COLUMN priority VARCHAR not null DEFAULT 'normal';
+
+-- Sections already used by tickets stay valid
+INSERT INTO sections (name, priority, routing, created_at)
+SELECT DISTINCT section, 'normal', 'round_robin', now() FROM tickets;
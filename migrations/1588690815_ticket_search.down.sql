@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS ticket_messages_search_idx;
+DROP INDEX IF EXISTS tickets_search_idx;
+DROP TRIGGER IF EXISTS ticket_messages_search_update ON ticket_messages;
+DROP TRIGGER IF EXISTS tickets_search_update ON tickets;
+ALTER TABLE ticket_messages DROP COLUMN IF EXISTS search;
+ALTER TABLE tickets DROP COLUMN IF EXISTS search;
@@ -0,0 +1,14 @@
+ALTER TABLE tickets ADD COLUMN search tsvector;
+ALTER TABLE ticket_messages ADD COLUMN search tsvector;
+
+CREATE TRIGGER tickets_search_update BEFORE INSERT OR UPDATE OF title, description ON tickets
+FOR EACH ROW EXECUTE PROCEDURE tsvector_update_trigger(search, 'pg_catalog.simple', title, description);
+
+CREATE TRIGGER ticket_messages_search_update BEFORE INSERT OR UPDATE OF message_text ON ticket_messages
+FOR EACH ROW EXECUTE PROCEDURE tsvector_update_trigger(search, 'pg_catalog.simple', message_text);
+
+UPDATE tickets SET search = to_tsvector('pg_catalog.simple', coalesce(title, '') || ' ' || coalesce(description, ''));
+UPDATE ticket_messages SET search = to_tsvector('pg_catalog.simple', coalesce(message_text, ''));
+
+CREATE INDEX tickets_search_idx ON tickets USING GIN (search);
+CREATE INDEX ticket_messages_search_idx ON ticket_messages USING GIN (search);
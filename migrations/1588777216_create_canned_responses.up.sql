@@ -0,0 +1,12 @@
+CREATE TABLE canned_responses (
+    id bigserial not null PRIMARY KEY,
+    owner INTEGER not null,
+    title VARCHAR not null,
+    body VARCHAR not null,
+    status VARCHAR not null DEFAULT '',
+    usage_count INTEGER not null DEFAULT 0,
+    created_at TIMESTAMP not null,
+    updated_at TIMESTAMP not null
+);
+
+CREATE INDEX canned_responses_owner_idx ON canned_responses (owner);
@@ -0,0 +1 @@
+ALTER TABLE tickets ADD COLUMN reminded_at TIMESTAMP;
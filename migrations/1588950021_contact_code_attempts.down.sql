@@ -0,0 +1 @@
+ALTER TABLE contacts DROP COLUMN IF EXISTS code_attempts;
@@ -0,0 +1 @@
+ALTER TABLE contacts ADD COLUMN code_attempts INTEGER not null DEFAULT 0;